@@ -27,6 +27,7 @@ import (
 
 	"github.com/gardener/machine-controller-manager-provider-aws/pkg/aws"
 	"github.com/gardener/machine-controller-manager-provider-aws/pkg/cpi"
+	"github.com/gardener/machine-controller-manager-provider-aws/pkg/replay"
 	_ "github.com/gardener/machine-controller-manager/pkg/util/client/metrics/prometheus" // for client metric registration
 	"github.com/gardener/machine-controller-manager/pkg/util/provider/app"
 	"github.com/gardener/machine-controller-manager/pkg/util/provider/app/options"
@@ -39,14 +40,37 @@ import (
 
 func main() {
 
+	var (
+		recordFixture string
+		driverOptions aws.Options
+	)
+
 	s := options.NewMCServer()
 	s.AddFlags(pflag.CommandLine)
+	pflag.CommandLine.StringVar(&recordFixture, "record-ec2-fixture", "", "If set, sanitized EC2 API interactions are appended to this file for use as replay fixture in unit tests")
+	pflag.CommandLine.BoolVar(&driverOptions.AllowUnknownProviderSpecFields, "allow-unknown-provider-spec-fields", false, "If set, fields of MachineClass providerSpecs unknown to the driver are ignored with a warning instead of being rejected when creating machines")
+	pflag.CommandLine.BoolVar(&driverOptions.PreflightValidation, "preflight-validation", false, "If set, the cloud resources referenced by a MachineClass (AMI, instance type, subnets, security groups, IAM instance profile) are verified with describe calls and a RunInstances dry run before machines are created")
 
 	flag.InitFlags()
 	logs.InitLogs()
 	defer logs.FlushLogs()
 
-	driver := aws.NewAWSDriver(&cpi.ClientProvider{})
+	var clientProvider cpi.ClientProviderInterface = &cpi.ClientProvider{}
+	if recordFixture != "" {
+		// #nosec: G302,G304 -- the fixture path is provided by the operator
+		f, err := os.OpenFile(recordFixture, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		clientProvider = &replay.RecordingClientProvider{
+			ClientProviderInterface: clientProvider,
+			Recorder:                replay.NewRecorder(f),
+		}
+	}
+
+	driver := aws.NewAWSDriverWithOptions(clientProvider, driverOptions)
 
 	if err := app.Run(s, driver); err != nil {
 		fmt.Fprintf(os.Stderr, "%v\n", err)
@@ -0,0 +1,62 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// machineclass-lint validates MachineClass and Secret manifests offline with the same rules the driver applies, so
+// that invalid providerSpecs are caught in CI instead of at machine creation.
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/pflag"
+
+	"github.com/gardener/machine-controller-manager-provider-aws/pkg/lint"
+)
+
+type options struct {
+	machineClass string
+	secret       string
+}
+
+func main() {
+	opts := &options{}
+	pflag.CommandLine.StringVar(&opts.machineClass, "machine-class", "", "Path to the MachineClass manifest")
+	pflag.CommandLine.StringVar(&opts.secret, "secret", "", "Path to the Secret manifest referenced by the MachineClass")
+	pflag.Parse()
+
+	if err := run(opts, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(opts *options, out io.Writer) error {
+	if opts.machineClass == "" {
+		return fmt.Errorf("--machine-class is required")
+	}
+	machineClassManifest, err := os.ReadFile(opts.machineClass) // #nosec: G304 -- path is provided by the user
+	if err != nil {
+		return err
+	}
+	var secretManifest []byte
+	if opts.secret != "" {
+		if secretManifest, err = os.ReadFile(opts.secret); err != nil { // #nosec: G304 -- path is provided by the user
+			return err
+		}
+	}
+
+	allErrs, err := lint.Lint(machineClassManifest, secretManifest)
+	if err != nil {
+		return err
+	}
+	for _, fieldErr := range allErrs {
+		fmt.Fprintf(out, "%s: %s\n", opts.machineClass, fieldErr.Error())
+	}
+	if len(allErrs) > 0 {
+		return fmt.Errorf("%s: found %d errors", opts.machineClass, len(allErrs))
+	}
+	return nil
+}
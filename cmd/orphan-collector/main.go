@@ -0,0 +1,193 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// orphan-collector lists and optionally deletes AWS resources which carry the cluster tag of a MachineClass but do
+// not correspond to any Machine object in the control cluster.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	mcmclientset "github.com/gardener/machine-controller-manager/pkg/client/clientset/versioned"
+	"github.com/spf13/pflag"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/component-base/cli/flag"
+	"k8s.io/component-base/logs"
+
+	api "github.com/gardener/machine-controller-manager-provider-aws/pkg/aws/apis"
+	"github.com/gardener/machine-controller-manager-provider-aws/pkg/aws/apis/validation"
+	"github.com/gardener/machine-controller-manager-provider-aws/pkg/cpi"
+	"github.com/gardener/machine-controller-manager-provider-aws/pkg/orphan"
+)
+
+const (
+	outputJSON = "json"
+	outputText = "text"
+)
+
+type options struct {
+	controlKubeconfig string
+	namespace         string
+	machineClass      string
+	dryRun            bool
+	output            string
+	minAge            time.Duration
+	excludeTags       []string
+}
+
+func main() {
+	opts := &options{}
+	pflag.CommandLine.StringVar(&opts.controlKubeconfig, "control-kubeconfig", "", "Path to the kubeconfig of the cluster holding the Machine and MachineClass objects")
+	pflag.CommandLine.StringVar(&opts.namespace, "namespace", "", "Namespace of the Machine and MachineClass objects")
+	pflag.CommandLine.StringVar(&opts.machineClass, "machine-class", "", "Name of the MachineClass whose cluster tag, region and credentials are used")
+	pflag.CommandLine.BoolVar(&opts.dryRun, "dry-run", true, "Only report orphaned resources without deleting them")
+	pflag.CommandLine.StringVar(&opts.output, "output", outputText, "Report format, one of [text, json]")
+	pflag.CommandLine.DurationVar(&opts.minAge, "min-age", time.Hour, "Ignore resources younger than this duration")
+	pflag.CommandLine.StringSliceVar(&opts.excludeTags, "exclude-tag", nil, "Ignore resources carrying this tag, given as key or key=value (can be repeated)")
+
+	flag.InitFlags()
+	logs.InitLogs()
+	defer logs.FlushLogs()
+
+	if err := run(context.Background(), opts, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, opts *options, out io.Writer) error {
+	if opts.namespace == "" || opts.machineClass == "" {
+		return fmt.Errorf("--namespace and --machine-class are required")
+	}
+	if opts.output != outputText && opts.output != outputJSON {
+		return fmt.Errorf("unsupported output format %q", opts.output)
+	}
+
+	restConfig, err := clientcmd.BuildConfigFromFlags("", opts.controlKubeconfig)
+	if err != nil {
+		return err
+	}
+	kubeClient, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return err
+	}
+	machineClient, err := mcmclientset.NewForConfig(restConfig)
+	if err != nil {
+		return err
+	}
+
+	machineClass, err := machineClient.MachineV1alpha1().MachineClasses(opts.namespace).Get(ctx, opts.machineClass, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	// Unknown fields do not matter here, only the tags and the region are used
+	providerSpec, _, _, err := validation.DecodeAWSProviderSpec(machineClass.ProviderSpec.Raw, field.NewPath("providerSpec"))
+	if err != nil {
+		return fmt.Errorf("could not decode providerSpec of MachineClass %q: %w", machineClass.Name, err)
+	}
+	clusterTag, roleTag := "", ""
+	for key := range providerSpec.Tags {
+		if strings.HasPrefix(key, api.ClusterTagPrefix) {
+			clusterTag = key
+		} else if strings.HasPrefix(key, api.RoleTagPrefix) {
+			roleTag = key
+		}
+	}
+	if clusterTag == "" {
+		return fmt.Errorf("MachineClass %q has no tag with prefix %q", machineClass.Name, api.ClusterTagPrefix)
+	}
+	if roleTag == "" {
+		return fmt.Errorf("MachineClass %q has no tag with prefix %q", machineClass.Name, api.RoleTagPrefix)
+	}
+
+	// Like machine-controller-manager, credentials from the credentialsSecretRef take precedence over the secretRef
+	secret := &corev1.Secret{Data: map[string][]byte{}}
+	for _, ref := range []*corev1.SecretReference{machineClass.SecretRef, machineClass.CredentialsSecretRef} {
+		if ref == nil {
+			continue
+		}
+		namespace := ref.Namespace
+		if namespace == "" {
+			namespace = opts.namespace
+		}
+		s, err := kubeClient.CoreV1().Secrets(namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		maps.Copy(secret.Data, s.Data)
+	}
+
+	machines, err := machineClient.MachineV1alpha1().Machines(opts.namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+
+	clientProvider := &cpi.ClientProvider{}
+	awsConfig, err := clientProvider.NewConfig(ctx, secret, providerSpec.Region)
+	if err != nil {
+		return err
+	}
+
+	report, err := orphan.Collect(ctx, clientProvider.NewEC2Client(awsConfig), machines.Items, orphan.Options{
+		ClusterTag:  clusterTag,
+		RoleTag:     roleTag,
+		MinAge:      opts.minAge,
+		ExcludeTags: parseExcludeTags(opts.excludeTags),
+		Delete:      !opts.dryRun,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := writeReport(out, report, opts.output); err != nil {
+		return err
+	}
+	if failed := report.Failed(); failed > 0 {
+		return fmt.Errorf("deletion of %d orphaned resources failed", failed)
+	}
+	return nil
+}
+
+func parseExcludeTags(tags []string) map[string]string {
+	excludeTags := make(map[string]string, len(tags))
+	for _, tag := range tags {
+		key, value, _ := strings.Cut(tag, "=")
+		excludeTags[key] = value
+	}
+	return excludeTags
+}
+
+func writeReport(out io.Writer, report *orphan.Report, format string) error {
+	if format == outputJSON {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	}
+
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "KIND\tID\tNAME\tCREATED\tDELETED\tERROR\n")
+	for _, resource := range report.Resources {
+		created := "-"
+		if resource.CreationTime != nil {
+			created = resource.CreationTime.UTC().Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%t\t%s\n", resource.Kind, resource.ID, resource.Name, created, resource.Deleted, resource.Error)
+	}
+	if report.DryRun {
+		fmt.Fprintf(w, "\nDry run: %d orphaned resources for cluster tag %q were not deleted\n", len(report.Resources), report.ClusterTag)
+	}
+	return w.Flush()
+}
@@ -7,6 +7,8 @@ package api
 const (
 	// V1alpha1 is the API version
 	V1alpha1 = "mcm.gardener.cloud/v1alpha1"
+	// V1alpha2 is the API version of providerSpecs without deprecated fields, see package v1alpha2
+	V1alpha2 = "mcm.gardener.cloud/v1alpha2"
 
 	// AWSAccessKeyID is a constant for a key name that is part of the AWS cloud credentials.
 	AWSAccessKeyID = "providerAccessKeyId"
@@ -51,6 +53,8 @@ var (
 )
 
 // AWSProviderSpec is the spec to be used while parsing the calls.
+// It is the internal representation of all API versions and the wire format of V1alpha1, which is assumed if the
+// APIVersion is empty.
 type AWSProviderSpec struct {
 	// APIVersion determines the APIversion for the provider APIs
 	APIVersion string `json:"apiVersion,omitempty"`
@@ -87,7 +91,7 @@ type AWSProviderSpec struct {
 
 	// SpotPrice is an optional field that if set specifies to use spot instances.
 	// When set to "" there is no maxPrice, else specifies the maxPrice.
-	// Deprecated: Use InstanceMarketOptions with MarketType "spot" instead.
+	// Deprecated: Use InstanceMarketOptions with MarketType "spot" and SpotOptions.MaxPrice instead.
 	SpotPrice *string `json:"spotPrice,omitempty"`
 
 	// If set to false, source and destination checks are disabled, default is true
@@ -102,12 +106,64 @@ type AWSProviderSpec struct {
 	// CPUOptions contains detailed configuration for the number of cores and threads for the instance.
 	CPUOptions *CPUOptions `json:"cpuOptions,omitempty"`
 
+	// EnclaveOptions enables AWS Nitro Enclaves for the instance, see AWSEnclaveOptions.
+	EnclaveOptions *AWSEnclaveOptions `json:"enclaveOptions,omitempty"`
+
+	// LicenseConfigurationARNs are the ARNs of the License Manager license configurations to associate with the
+	// instance, e.g. to track bring-your-own-license (BYOL) Windows Server or SQL Server licenses.
+	LicenseConfigurationARNs []string `json:"licenseConfigurationArns,omitempty"`
+
+	// BootMode is the boot mode the instance must be booted with. Valid values: "legacy-bios", "uefi".
+	// The boot mode is a property of the AMI, so this only validates that the AMI supports it.
+	BootMode *string `json:"bootMode,omitempty"`
+
+	// TPMSupport requires a NitroTPM of the given version for the instance. Valid values: "v2.0".
+	// NitroTPM support is a property of the AMI, so this only validates that the AMI enables it. It requires the
+	// "uefi" boot mode.
+	// For more information, see https://docs.aws.amazon.com/AWSEC2/latest/UserGuide/nitrotpm.html
+	TPMSupport *string `json:"tpmSupport,omitempty"`
+
 	// Placement contains placement configuration for the instance (placement groups, tenancy, dedicated hosts).
 	Placement *AWSPlacementSpec `json:"placement,omitempty"`
 
 	// InstanceMarketOptions configures the instance market type.
 	// If not specified, on-demand instances are launched.
 	InstanceMarketOptions *AWSInstanceMarketOptions `json:"instanceMarketOptions,omitempty"`
+
+	// EFA attaches an Elastic Fabric Adapter to every network card of the machine type, see AWSEFASpec.
+	EFA *AWSEFASpec `json:"efa,omitempty"`
+
+	// ElasticIP associates an Elastic IP address with the machine, see AWSElasticIPSpec.
+	ElasticIP *AWSElasticIPSpec `json:"elasticIP,omitempty"`
+
+	// PrivateDNSNameOptions configures the hostname type of the instance and the DNS records of its resource name.
+	// If not specified, the settings of the subnet apply.
+	PrivateDNSNameOptions *AWSPrivateDNSNameOptions `json:"privateDNSNameOptions,omitempty"`
+
+	// CreditSpecification configures the CPU credits of burstable performance instances (T instance types). If not
+	// specified, the default credit option of the account for the instance family applies.
+	CreditSpecification *AWSCreditSpecification `json:"creditSpecification,omitempty"`
+
+	// MaintenanceOptions configures how the instance is treated by AWS maintenance, see AWSMaintenanceOptions.
+	MaintenanceOptions *AWSMaintenanceOptions `json:"maintenanceOptions,omitempty"`
+
+	// HibernationOptions enables hibernation of the instance, see AWSHibernationOptions.
+	HibernationOptions *AWSHibernationOptions `json:"hibernationOptions,omitempty"`
+}
+
+// RootBlockDeviceIndex returns the index of the block device which is mapped to the root device of the AMI, or -1 if
+// there is none.
+func (s *AWSProviderSpec) RootBlockDeviceIndex() int {
+	// A single block device without device name is the root device for backward compatibility
+	if len(s.BlockDevices) == 1 {
+		return 0
+	}
+	for i, disk := range s.BlockDevices {
+		if disk.DeviceName == RootDeviceName {
+			return i
+		}
+	}
+	return -1
 }
 
 // AWSBlockDeviceMappingSpec stores info about AWS block device mappings
@@ -155,6 +211,15 @@ type AWSInstanceMarketOptions struct {
 	// MarketType is the market type for the instance.
 	// Supported values: "spot", "capacity-block", "interruptible-capacity-reservation".
 	MarketType string `json:"marketType"`
+
+	// SpotOptions configures spot instances, it can only be set for MarketType "spot".
+	SpotOptions *AWSSpotMarketOptions `json:"spotOptions,omitempty"`
+}
+
+// AWSSpotMarketOptions configures spot instances.
+type AWSSpotMarketOptions struct {
+	// MaxPrice is the maximum hourly price for the spot instance. If not set, the on-demand price is the limit.
+	MaxPrice *string `json:"maxPrice,omitempty"`
 }
 
 // AWSEbsBlockDeviceSpec describes a block device for an EBS volume.
@@ -246,6 +311,24 @@ type AWSNetworkInterfaceSpec struct {
 	// network interface.
 	Ipv6PrefixCount *int32 `json:"ipv6PrefixCount,omitempty"`
 
+	// PrivateIPv4CIDR is a range of the subnet, e.g. "10.0.1.16/28", from which a free address is chosen as primary
+	// private IPv4 address of the network interface. The chosen address is exposed as instance tag
+	// "machine.sapcloud.io/static-private-ipv4-<index of the network interface>".
+	PrivateIPv4CIDR *string `json:"privateIPv4CIDR,omitempty"`
+
+	// IPv6CIDR is a range of the IPv6 CIDR of the subnet, e.g. "2001:db8:1:2::/120", from which a free address is
+	// chosen as IPv6 address of the network interface. The chosen address is exposed as instance tag
+	// "machine.sapcloud.io/static-ipv6-<index of the network interface>". Mutually exclusive with Ipv6AddressCount.
+	IPv6CIDR *string `json:"ipv6CIDR,omitempty"`
+
+	// SecondaryPrivateIPAddressCount represents the number of secondary private IPv4 addresses to assign to the
+	// network interface. Amazon EC2 chooses the addresses from the range of the subnet.
+	SecondaryPrivateIPAddressCount *int32 `json:"secondaryPrivateIPAddressCount,omitempty"`
+
+	// Ipv4PrefixCount represents the number of IPv4 delegated prefixes (/28) to be automatically assigned to the
+	// network interface.
+	Ipv4PrefixCount *int32 `json:"ipv4PrefixCount,omitempty"`
+
 	// If set to true, the interface is deleted when the machine is terminated.
 	// You can specify true only if creating a new network interface when launching
 	// an machine.
@@ -263,6 +346,14 @@ type AWSNetworkInterfaceSpec struct {
 	// creating a network interface when launching an machine.
 	SubnetID string `json:"subnetID,omitempty"`
 
+	// NetworkInterfaceID is the ID of an existing network interface, which is attached instead of creating a new one,
+	// e.g. to keep a fixed private IP address. The interface is never deleted on termination.
+	NetworkInterfaceID *string `json:"networkInterfaceID,omitempty"`
+
+	// NetworkInterfaceTags select an existing network interface carrying all of the tags, which is attached instead of
+	// creating a new one. The first available interface is taken. The interface is never deleted on termination.
+	NetworkInterfaceTags map[string]string `json:"networkInterfaceTags,omitempty"`
+
 	// InterfaceType is the type of network interface.
 	// Currently valid values for RunInstances: "interface", "efa", "efa-only".
 	// See https://github.com/aws/aws-sdk-go-v2/blob/service/ec2/v1.279.0/service/ec2/types/types.go#L9181
@@ -279,18 +370,125 @@ type AWSNetworkInterfaceSpec struct {
 	PrimaryIpv6 *bool `json:"primaryIpv6,omitempty"`
 }
 
+// IsExisting returns true if the network interface references an existing network interface instead of describing a
+// new one.
+func (n *AWSNetworkInterfaceSpec) IsExisting() bool {
+	return n.NetworkInterfaceID != nil || len(n.NetworkInterfaceTags) > 0
+}
+
+// AWSEFASpec configures Elastic Fabric Adapters on all network cards of the machine type. It requires exactly one
+// network interface in NetworkInterfaces, which becomes the primary interface of type "efa" on network card 0. On
+// device index 1 of every further network card an interface is added, up to the maximum number of EFA interfaces of
+// the machine type. The added interfaces use the subnet and security groups of the primary interface.
+type AWSEFASpec struct {
+	// InterfaceType is the type of the interfaces added on the further network cards.
+	// Valid values: "efa-only" (default), which does not consume IP addresses of the subnet, and "efa".
+	InterfaceType *string `json:"interfaceType,omitempty"`
+}
+
+// AWSElasticIPSpec configures a stable public IPv4 address for a machine. The Elastic IP is associated with the primary
+// network interface when the machine is initialized and tagged with the machine name. Unless PoolTags is set, a new
+// Elastic IP is allocated for the machine, which is released again when the machine is deleted.
+type AWSElasticIPSpec struct {
+	// PoolTags selects a pool of pre-allocated Elastic IPs, which carry all of the tags. An unassociated address of the
+	// pool is taken for the machine and returned to the pool when the machine is deleted.
+	PoolTags map[string]string `json:"poolTags,omitempty"`
+}
+
+// AWSPrivateDNSNameOptions configures the private hostname of an instance, which is also the name of its node.
+// See https://docs.aws.amazon.com/AWSEC2/latest/UserGuide/ec2-instance-naming.html for additional information.
+type AWSPrivateDNSNameOptions struct {
+	// HostnameType is the type of the hostname, either "ip-name" (derived from the private IPv4 address) or
+	// "resource-name" (derived from the instance ID). Instances in IPv6-only subnets require "resource-name".
+	HostnameType *string `json:"hostnameType,omitempty"`
+
+	// EnableResourceNameDNSARecord enables the DNS A record resolving the resource name to the private IPv4 address.
+	EnableResourceNameDNSARecord *bool `json:"enableResourceNameDNSARecord,omitempty"`
+
+	// EnableResourceNameDNSAAAARecord enables the DNS AAAA record resolving the resource name to the IPv6 address of
+	// the primary network interface, which must request IPv6 addresses.
+	EnableResourceNameDNSAAAARecord *bool `json:"enableResourceNameDNSAAAARecord,omitempty"`
+}
+
+// AWSCreditSpecification configures the CPU credits of a burstable performance instance.
+// See https://docs.aws.amazon.com/AWSEC2/latest/UserGuide/burstable-credits-baseline-concepts.html for additional information.
+type AWSCreditSpecification struct {
+	// CPUCredits is the credit option for CPU usage, either "standard" or "unlimited".
+	CPUCredits string `json:"cpuCredits"`
+}
+
+// AWSMaintenanceOptions configures whether AWS recovers or migrates an instance on its own. Machines of long-lived
+// stateful pools should be recovered by AWS, while machines of ephemeral pools are better replaced by the
+// machine-controller-manager. Some instance types do not support these options.
+// See https://docs.aws.amazon.com/AWSEC2/latest/UserGuide/ec2-instance-recover.html for additional information.
+type AWSMaintenanceOptions struct {
+	// AutoRecovery controls the simplified automatic recovery of the instance after a system status check failure,
+	// either "default" (enabled) or "disabled".
+	AutoRecovery *string `json:"autoRecovery,omitempty"`
+
+	// RebootMigration controls whether the instance is migrated to new hardware when it is rebooted for a scheduled
+	// event, either "default" (enabled) or "disabled". It cannot be set at launch and is applied when the machine is
+	// initialized.
+	RebootMigration *string `json:"rebootMigration,omitempty"`
+}
+
+// AWSHibernationOptions configures hibernation of an instance. When a hibernation-capable instance is stopped with
+// hibernation, the contents of its memory are saved to the root volume, which must be encrypted and larger than the
+// memory of the machine type. Hibernation can only be enabled at launch.
+// See https://docs.aws.amazon.com/AWSEC2/latest/UserGuide/Hibernate.html for additional information.
+type AWSHibernationOptions struct {
+	// Configured enables hibernation of the instance.
+	Configured bool `json:"configured"`
+}
+
+// AWSEnclaveOptions configures AWS Nitro Enclaves, isolated compute environments carved out of the vCPUs and memory of
+// the instance. Enclaves can only be enabled at launch and not together with hibernation.
+// See https://docs.aws.amazon.com/enclaves/latest/user/nitro-enclave.html for additional information.
+type AWSEnclaveOptions struct {
+	// Enabled enables Nitro Enclaves for the instance.
+	Enabled bool `json:"enabled"`
+}
+
 // AWSPlacementSpec contains placement configuration for an EC2 instance.
 type AWSPlacementSpec struct {
 	// GroupID is the ID of the placement group.
 	GroupID *string `json:"groupId,omitempty"`
+	// GroupName is the name of the placement group, an alternative to GroupID.
+	GroupName *string `json:"groupName,omitempty"`
 	// Tenancy is the tenancy of the instance. Valid values: "default", "dedicated", "host".
 	Tenancy *string `json:"tenancy,omitempty"`
 	// HostID is the ID of the Dedicated Host.
 	HostID *string `json:"hostId,omitempty"`
 	// PartitionNumber is the partition number for the instance.
 	PartitionNumber *int32 `json:"partitionNumber,omitempty"`
+	// SpreadPartitions spreads the machines of the MachineClass round-robin across the partitions of the partition
+	// placement group, instead of launching them into a fixed PartitionNumber. Every machine is launched into the
+	// partition with the fewest instances of the MachineClass.
+	SpreadPartitions bool `json:"spreadPartitions,omitempty"`
 	// Affinity is the affinity setting. Valid values: "default", "host".
 	Affinity *string `json:"affinity,omitempty"`
+	// HostResourceGroupARN is the ARN of the License Manager host resource group to launch the instance into.
+	// Requires the tenancy "host" and cannot be combined with HostID.
+	HostResourceGroupARN *string `json:"hostResourceGroupArn,omitempty"`
+	// HostResourceGroupName is the name of the host resource group to launch the instance into, an alternative to
+	// HostResourceGroupARN. The group must belong to the account of the credentials.
+	HostResourceGroupName *string `json:"hostResourceGroupName,omitempty"`
+	// DedicatedHosts places the instance on a Dedicated Host of the cluster with free capacity for the machine type,
+	// instead of a fixed HostID, see AWSDedicatedHostsSpec. Requires the tenancy "host".
+	DedicatedHosts *AWSDedicatedHostsSpec `json:"dedicatedHosts,omitempty"`
+}
+
+// AWSDedicatedHostsSpec configures the automatic placement of instances on Dedicated Hosts. A host is eligible if it
+// carries the cluster tag of the providerSpec, is located in the availability zone of the machine and has free
+// capacity for its machine type. The fullest eligible host is chosen, so that empty hosts can be released.
+type AWSDedicatedHostsSpec struct {
+	// Allocate allocates a new Dedicated Host for the machine type if no host of the cluster has free capacity.
+	// Allocated hosts carry the tags of the providerSpec and are released once their last machine has been deleted.
+	// Hosts which have been allocated otherwise are never released.
+	Allocate bool `json:"allocate,omitempty"`
+	// MinimumHoldDuration is the time after its allocation during which a host is not released even if it is empty,
+	// e.g. "24h" for hosts of Mac instances, which cannot be released earlier. Requires Allocate.
+	MinimumHoldDuration *string `json:"minimumHoldDuration,omitempty"`
 }
 
 const (
@@ -0,0 +1,27 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build ignore
+
+// generate writes the JSON Schema of the AWSProviderSpec into the schema package.
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/gardener/machine-controller-manager-provider-aws/pkg/aws/apis/schema"
+)
+
+func main() {
+	data, err := schema.Generate()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	if err := os.WriteFile(schema.FileName, data, 0600); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+}
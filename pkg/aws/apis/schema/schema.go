@@ -0,0 +1,128 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package schema generates the JSON Schema of the AWSProviderSpec, which can be used by external tools to validate
+// MachineClass providerSpecs without depending on the Go types.
+package schema
+
+import (
+	// embed is used for the generated schema
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	awsapi "github.com/gardener/machine-controller-manager-provider-aws/pkg/aws/apis"
+	"github.com/gardener/machine-controller-manager-provider-aws/pkg/aws/apis/validation"
+)
+
+//go:generate go run generate.go
+
+// FileName is the name of the generated schema file.
+const FileName = "aws-provider-spec.schema.json"
+
+// AWSProviderSpec is the generated JSON Schema of the AWSProviderSpec.
+//
+//go:embed aws-provider-spec.schema.json
+var AWSProviderSpec []byte
+
+// Schema is the subset of JSON Schema used to describe the AWSProviderSpec.
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Type                 string             `json:"type"`
+	Format               string             `json:"format,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties any                `json:"additionalProperties,omitempty"`
+}
+
+// Generate returns the JSON Schema of the AWSProviderSpec. Objects do not allow additional properties, since the
+// driver rejects unknown fields, and enumerated fields list the values accepted by the validation.
+func Generate() ([]byte, error) {
+	enums := make(map[string][]string, len(validation.Enums))
+	for path, values := range validation.Enums {
+		enums[path] = values
+	}
+
+	root, err := schemaFor(reflect.TypeFor[awsapi.AWSProviderSpec](), "", enums)
+	if err != nil {
+		return nil, err
+	}
+	// Every enumerated field must have been consumed, otherwise the field was renamed or removed
+	if len(enums) > 0 {
+		paths := make([]string, 0, len(enums))
+		for path := range enums {
+			paths = append(paths, path)
+		}
+		slices.Sort(paths)
+		return nil, fmt.Errorf("enumerated fields %v do not exist in AWSProviderSpec", paths)
+	}
+	root.Schema = "https://json-schema.org/draft/2020-12/schema"
+	root.Title = "AWSProviderSpec"
+
+	data, err := json.MarshalIndent(root, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+func schemaFor(t reflect.Type, path string, enums map[string][]string) (*Schema, error) {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		schema := &Schema{Type: "string"}
+		if values, ok := enums[path]; ok {
+			schema.Enum = values
+			delete(enums, path)
+		}
+		return schema, nil
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}, nil
+	case reflect.Int32, reflect.Int64:
+		return &Schema{Type: "integer", Format: t.Kind().String()}, nil
+	case reflect.Slice:
+		items, err := schemaFor(t.Elem(), path, enums)
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: "array", Items: items}, nil
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("unsupported map key type %s of field %q", t.Key(), path)
+		}
+		values, err := schemaFor(t.Elem(), path, enums)
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: "object", AdditionalProperties: values}, nil
+	case reflect.Struct:
+		schema := &Schema{Type: "object", Properties: map[string]*Schema{}, AdditionalProperties: false}
+		for field := range t.Fields() {
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "" || name == "-" || !field.IsExported() {
+				continue
+			}
+			fieldPath := name
+			if path != "" {
+				fieldPath = path + "." + name
+			}
+			property, err := schemaFor(field.Type, fieldPath, enums)
+			if err != nil {
+				return nil, err
+			}
+			schema.Properties[name] = property
+		}
+		return schema, nil
+	default:
+		return nil, fmt.Errorf("unsupported type %s of field %q", t, path)
+	}
+}
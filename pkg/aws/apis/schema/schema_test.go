@@ -0,0 +1,135 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package schema
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	awsapi "github.com/gardener/machine-controller-manager-provider-aws/pkg/aws/apis"
+	"github.com/gardener/machine-controller-manager-provider-aws/pkg/aws/apis/validation"
+)
+
+func TestSchemaIsUpToDate(t *testing.T) {
+	g := NewWithT(t)
+
+	data, err := Generate()
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(string(AWSProviderSpec)).To(Equal(string(data)), "%s is outdated, run 'go generate ./pkg/aws/apis/schema'", FileName)
+}
+
+func TestSchemaContainsEnums(t *testing.T) {
+	g := NewWithT(t)
+
+	root := &Schema{}
+	g.Expect(json.Unmarshal(AWSProviderSpec, root)).To(Succeed())
+	g.Expect(root.Properties["placement"].Properties["tenancy"].Enum).To(ConsistOf("default", "dedicated", "host"))
+	g.Expect(root.Properties["networkInterfaces"].Items.Properties["interfaceType"].Enum).To(ConsistOf("interface", "efa", "efa-only"))
+	g.Expect(root.Properties["blockDevices"].Items.Properties["ebs"].Properties["volumeType"].Enum).To(ContainElement("gp3"))
+	g.Expect(root.Properties["instanceMarketOptions"].Properties["marketType"].Enum).To(ContainElement("spot"))
+	g.Expect(root.Properties["instanceMetadataOptions"].Properties["httpTokens"].Enum).To(ConsistOf("required", "optional"))
+}
+
+func TestGenerateFailsForUnknownEnumField(t *testing.T) {
+	g := NewWithT(t)
+	validation.Enums["placement.tenancyy"] = []string{"default"}
+	defer delete(validation.Enums, "placement.tenancyy")
+
+	_, err := Generate()
+	g.Expect(err).To(MatchError("enumerated fields [placement.tenancyy] do not exist in AWSProviderSpec"))
+}
+
+// TestEnumsCoverValidatedFields makes sure that every string field which the validation restricts to a set of values
+// is listed in validation.Enums, since the schema would accept any value for it otherwise. Each string field without
+// an entry is set to an invalid value, which must not be rejected as unsupported.
+func TestEnumsCoverValidatedFields(t *testing.T) {
+	secret := &corev1.Secret{Data: map[string][]byte{
+		"providerAccessKeyId":     []byte("dummy-id"),
+		"providerSecretAccessKey": []byte("dummy-secret"),
+		"userData":                []byte("dummy-user-data"),
+	}}
+	indices := regexp.MustCompile(`\[[^]]*\]`)
+
+	for _, path := range stringFieldPaths(reflect.TypeFor[awsapi.AWSProviderSpec](), "") {
+		if _, ok := validation.Enums[path]; ok {
+			continue
+		}
+		spec := &awsapi.AWSProviderSpec{
+			AMI:               "ami-123456789",
+			BlockDevices:      []awsapi.AWSBlockDeviceMappingSpec{{Ebs: awsapi.AWSEbsBlockDeviceSpec{VolumeSize: 50, VolumeType: "gp2"}}},
+			IAM:               awsapi.AWSIAMProfileSpec{Name: "test-iam"},
+			Region:            "eu-west-1",
+			MachineType:       "m4.large",
+			NetworkInterfaces: []awsapi.AWSNetworkInterfaceSpec{{SecurityGroupIDs: []string{"sg-1"}, SubnetID: "subnet-1"}},
+			Tags:              map[string]string{"kubernetes.io/cluster/shoot--test": "1", "kubernetes.io/role/test": "1"},
+		}
+		setStringField(reflect.ValueOf(spec).Elem(), strings.Split(path, "."), "not-an-enum-value")
+
+		for _, err := range validation.ValidateAWSProviderSpec(spec, secret, field.NewPath("providerSpec")) {
+			if err.Type == field.ErrorTypeNotSupported && indices.ReplaceAllString(err.Field, "") == "providerSpec."+path {
+				t.Errorf("%s is restricted to %s, add it to validation.Enums", path, err.Detail)
+			}
+		}
+	}
+}
+
+// stringFieldPaths returns the JSON paths of all string fields and string lists of the type, without map values.
+func stringFieldPaths(t reflect.Type, path string) []string {
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return []string{path}
+	case reflect.Struct:
+		var paths []string
+		for field := range t.Fields() {
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "" || name == "-" || !field.IsExported() {
+				continue
+			}
+			if path != "" {
+				name = path + "." + name
+			}
+			paths = append(paths, stringFieldPaths(field.Type, name)...)
+		}
+		return paths
+	default:
+		return nil
+	}
+}
+
+// setStringField sets the field at the JSON path to the value, allocating pointers and the first list element on the
+// way.
+func setStringField(v reflect.Value, path []string, value string) {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		setStringField(v.Elem(), path, value)
+	case reflect.Slice:
+		if v.Len() == 0 {
+			v.Set(reflect.MakeSlice(v.Type(), 1, 1))
+		}
+		setStringField(v.Index(0), path, value)
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Struct:
+		for i := range v.NumField() {
+			if name, _, _ := strings.Cut(v.Type().Field(i).Tag.Get("json"), ","); name == path[0] {
+				setStringField(v.Field(i), path[1:], value)
+				return
+			}
+		}
+	}
+}
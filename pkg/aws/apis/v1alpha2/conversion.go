@@ -0,0 +1,84 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha2
+
+import (
+	api "github.com/gardener/machine-controller-manager-provider-aws/pkg/aws/apis"
+)
+
+// ConvertToInternal converts a v1alpha2 providerSpec into the internal representation.
+func ConvertToInternal(in *AWSProviderSpec) *api.AWSProviderSpec {
+	return &api.AWSProviderSpec{
+		APIVersion:                in.APIVersion,
+		AMI:                       in.AMI,
+		BlockDevices:              in.BlockDevices,
+		CapacityReservationTarget: in.CapacityReservationTarget,
+		EbsOptimized:              in.EbsOptimized,
+		IAM:                       in.IAM,
+		MachineType:               in.MachineType,
+		KeyName:                   in.KeyName,
+		Monitoring:                in.Monitoring,
+		NetworkInterfaces:         in.NetworkInterfaces,
+		Region:                    in.Region,
+		SrcAndDstChecksEnabled:    in.SrcAndDstChecksEnabled,
+		Tags:                      in.Tags,
+		InstanceMetadataOptions:   in.InstanceMetadataOptions,
+		CPUOptions:                in.CPUOptions,
+		EnclaveOptions:            in.EnclaveOptions,
+		LicenseConfigurationARNs:  in.LicenseConfigurationARNs,
+		BootMode:                  in.BootMode,
+		TPMSupport:                in.TPMSupport,
+		Placement:                 in.Placement,
+		InstanceMarketOptions:     in.InstanceMarketOptions,
+		EFA:                       in.EFA,
+		ElasticIP:                 in.ElasticIP,
+		PrivateDNSNameOptions:     in.PrivateDNSNameOptions,
+		CreditSpecification:       in.CreditSpecification,
+		MaintenanceOptions:        in.MaintenanceOptions,
+		HibernationOptions:        in.HibernationOptions,
+	}
+}
+
+// ConvertFromInternal converts a providerSpec in the internal representation, e.g. a decoded v1alpha1 providerSpec,
+// into v1alpha2. Deprecated fields are migrated to their replacement, unless the replacement is set already.
+func ConvertFromInternal(in *api.AWSProviderSpec) *AWSProviderSpec {
+	out := &AWSProviderSpec{
+		APIVersion:                api.V1alpha2,
+		AMI:                       in.AMI,
+		BlockDevices:              in.BlockDevices,
+		CapacityReservationTarget: in.CapacityReservationTarget,
+		EbsOptimized:              in.EbsOptimized,
+		IAM:                       in.IAM,
+		MachineType:               in.MachineType,
+		KeyName:                   in.KeyName,
+		Monitoring:                in.Monitoring,
+		NetworkInterfaces:         in.NetworkInterfaces,
+		Region:                    in.Region,
+		SrcAndDstChecksEnabled:    in.SrcAndDstChecksEnabled,
+		Tags:                      in.Tags,
+		InstanceMetadataOptions:   in.InstanceMetadataOptions,
+		CPUOptions:                in.CPUOptions,
+		EnclaveOptions:            in.EnclaveOptions,
+		LicenseConfigurationARNs:  in.LicenseConfigurationARNs,
+		BootMode:                  in.BootMode,
+		TPMSupport:                in.TPMSupport,
+		Placement:                 in.Placement,
+		InstanceMarketOptions:     in.InstanceMarketOptions,
+		EFA:                       in.EFA,
+		ElasticIP:                 in.ElasticIP,
+		PrivateDNSNameOptions:     in.PrivateDNSNameOptions,
+		CreditSpecification:       in.CreditSpecification,
+		MaintenanceOptions:        in.MaintenanceOptions,
+		HibernationOptions:        in.HibernationOptions,
+	}
+
+	if in.SpotPrice != nil && in.InstanceMarketOptions == nil {
+		out.InstanceMarketOptions = &api.AWSInstanceMarketOptions{MarketType: "spot"}
+		if *in.SpotPrice != "" {
+			out.InstanceMarketOptions.SpotOptions = &api.AWSSpotMarketOptions{MaxPrice: in.SpotPrice}
+		}
+	}
+	return out
+}
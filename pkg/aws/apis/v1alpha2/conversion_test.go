@@ -0,0 +1,111 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha2
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/utils/ptr"
+
+	api "github.com/gardener/machine-controller-manager-provider-aws/pkg/aws/apis"
+)
+
+func TestConvertFromInternalMigratesSpotPrice(t *testing.T) {
+	g := NewWithT(t)
+
+	out := ConvertFromInternal(&api.AWSProviderSpec{AMI: "ami-123", SpotPrice: ptr.To("0.5")})
+	g.Expect(out.APIVersion).To(Equal(api.V1alpha2))
+	g.Expect(out.AMI).To(Equal("ami-123"))
+	g.Expect(out.InstanceMarketOptions).To(Equal(&api.AWSInstanceMarketOptions{
+		MarketType:  "spot",
+		SpotOptions: &api.AWSSpotMarketOptions{MaxPrice: ptr.To("0.5")},
+	}))
+
+	out = ConvertFromInternal(&api.AWSProviderSpec{SpotPrice: ptr.To("")})
+	g.Expect(out.InstanceMarketOptions).To(Equal(&api.AWSInstanceMarketOptions{MarketType: "spot"}))
+}
+
+func TestConvertFromInternalKeepsInstanceMarketOptions(t *testing.T) {
+	g := NewWithT(t)
+
+	marketOptions := &api.AWSInstanceMarketOptions{MarketType: "capacity-block"}
+	out := ConvertFromInternal(&api.AWSProviderSpec{SpotPrice: ptr.To("0.5"), InstanceMarketOptions: marketOptions})
+	g.Expect(out.InstanceMarketOptions).To(Equal(marketOptions))
+}
+
+func TestConvertRoundTrip(t *testing.T) {
+	g := NewWithT(t)
+
+	in := &AWSProviderSpec{
+		APIVersion:   api.V1alpha2,
+		AMI:          "ami-123",
+		Region:       "eu-west-1",
+		MachineType:  "m5.large",
+		IAM:          api.AWSIAMProfileSpec{Name: "test-iam"},
+		BlockDevices: []api.AWSBlockDeviceMappingSpec{{Ebs: api.AWSEbsBlockDeviceSpec{VolumeSize: 50, VolumeType: "gp3"}}},
+		NetworkInterfaces: []api.AWSNetworkInterfaceSpec{
+			{SubnetID: "subnet-123", SecurityGroupIDs: []string{"sg-123"}},
+		},
+		Tags:      map[string]string{"foo": "bar"},
+		Placement: &api.AWSPlacementSpec{Tenancy: ptr.To("host"), HostResourceGroupName: ptr.To("byol")},
+		EFA:       &api.AWSEFASpec{InterfaceType: ptr.To("efa-only")},
+		ElasticIP: &api.AWSElasticIPSpec{PoolTags: map[string]string{"pool": "bastion"}},
+		PrivateDNSNameOptions: &api.AWSPrivateDNSNameOptions{
+			HostnameType:                    ptr.To("resource-name"),
+			EnableResourceNameDNSAAAARecord: ptr.To(true),
+		},
+		CreditSpecification: &api.AWSCreditSpecification{CPUCredits: "unlimited"},
+		MaintenanceOptions:  &api.AWSMaintenanceOptions{AutoRecovery: ptr.To("disabled"), RebootMigration: ptr.To("disabled")},
+		HibernationOptions:  &api.AWSHibernationOptions{Configured: true},
+		EnclaveOptions:      &api.AWSEnclaveOptions{Enabled: true},
+		LicenseConfigurationARNs: []string{
+			"arn:aws:license-manager:eu-west-1:123456789012:license-configuration:lic-0123456789abcdef0",
+		},
+		BootMode:   ptr.To("uefi"),
+		TPMSupport: ptr.To("v2.0"),
+	}
+	internal := ConvertToInternal(in)
+	g.Expect(internal.SpotPrice).To(BeNil())
+	g.Expect(ConvertFromInternal(internal)).To(Equal(in))
+}
+
+// TestFieldsMatchInternal guards against fields which are added to only one of the providerSpec types, since the
+// conversions copy the fields explicitly. SpotPrice has been dropped in v1alpha2.
+func TestFieldsMatchInternal(t *testing.T) {
+	g := NewWithT(t)
+
+	fields := func(typ reflect.Type, skip ...string) map[string]string {
+		out := make(map[string]string)
+		for i := range typ.NumField() {
+			field := typ.Field(i)
+			if !slices.Contains(skip, field.Name) {
+				jsonName, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+				out[field.Name] = field.Type.String() + " " + jsonName
+			}
+		}
+		return out
+	}
+	g.Expect(fields(reflect.TypeFor[AWSProviderSpec]())).To(Equal(fields(reflect.TypeFor[api.AWSProviderSpec](), "SpotPrice")))
+}
+
+func TestSetDefaults(t *testing.T) {
+	g := NewWithT(t)
+
+	spec := &AWSProviderSpec{
+		BlockDevices: []api.AWSBlockDeviceMappingSpec{{}, {Ebs: api.AWSEbsBlockDeviceSpec{DeleteOnTermination: ptr.To(false)}}},
+		NetworkInterfaces: []api.AWSNetworkInterfaceSpec{
+			{}, {DeleteOnTermination: ptr.To(false)},
+		},
+	}
+	SetDefaults(spec)
+	g.Expect(spec.BlockDevices[0].Ebs.DeleteOnTermination).To(Equal(ptr.To(true)))
+	g.Expect(spec.BlockDevices[1].Ebs.DeleteOnTermination).To(Equal(ptr.To(false)))
+	g.Expect(spec.NetworkInterfaces[0].DeleteOnTermination).To(Equal(ptr.To(true)))
+	g.Expect(spec.NetworkInterfaces[1].DeleteOnTermination).To(Equal(ptr.To(false)))
+}
@@ -0,0 +1,24 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha2
+
+import (
+	"k8s.io/utils/ptr"
+)
+
+// SetDefaults sets the defaults of the providerSpec which the driver otherwise applies implicitly when launching
+// instances.
+func SetDefaults(spec *AWSProviderSpec) {
+	for i := range spec.BlockDevices {
+		if spec.BlockDevices[i].Ebs.DeleteOnTermination == nil {
+			spec.BlockDevices[i].Ebs.DeleteOnTermination = ptr.To(true)
+		}
+	}
+	for i := range spec.NetworkInterfaces {
+		if spec.NetworkInterfaces[i].DeleteOnTermination == nil && !spec.NetworkInterfaces[i].IsExisting() {
+			spec.NetworkInterfaces[i].DeleteOnTermination = ptr.To(true)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package v1alpha2 contains the v1alpha2 providerSpec of AWS MachineClasses. It drops the deprecated fields of
+// v1alpha1 (the unversioned shape in package api). ProviderSpecs are converted into the internal api.AWSProviderSpec
+// on decoding, so the driver only deals with a single type.
+//
+// Differences to v1alpha1:
+//   - apiVersion must be set to "mcm.gardener.cloud/v1alpha2".
+//   - spotPrice is removed, spot instances are requested with instanceMarketOptions.marketType "spot" and the
+//     maximum price is set with instanceMarketOptions.spotOptions.maxPrice.
+//   - the deleteOnTermination flags of block devices and network interfaces are defaulted explicitly.
+package v1alpha2
+
+import (
+	api "github.com/gardener/machine-controller-manager-provider-aws/pkg/aws/apis"
+)
+
+// AWSProviderSpec is the v1alpha2 providerSpec of AWS MachineClasses. Nested types are shared with package api.
+type AWSProviderSpec struct {
+	// APIVersion is the API version of the providerSpec, it must be api.V1alpha2.
+	APIVersion string `json:"apiVersion"`
+
+	// AMI is the disk image version
+	AMI string `json:"ami,omitempty"`
+
+	// BlockDevices is the list of block devices to be mapped to the instances
+	BlockDevices []api.AWSBlockDeviceMappingSpec `json:"blockDevices,omitempty"`
+
+	// CapacityReservationTarget is an optional field that allows assigning of machines to an AWS Capacity Reservation
+	CapacityReservationTarget *api.AWSCapacityReservationTargetSpec `json:"capacityReservation,omitempty"`
+
+	// EbsOptimized specifies that the EBS is optimized
+	EbsOptimized bool `json:"ebsOptimized,omitempty"`
+
+	// IAM details for the machine
+	IAM api.AWSIAMProfileSpec `json:"iam,omitempty"`
+
+	// MachineType contains the EC2 instance type
+	MachineType string `json:"machineType,omitempty"`
+
+	// KeyName is an optional field that contains the SSH keypair
+	KeyName *string `json:"keyName,omitempty"`
+
+	// Monitoring specifies if monitoring is enabled
+	Monitoring bool `json:"monitoring,omitempty"`
+
+	// NetworkInterfaces contains a list of NetworkInterfaceSpecs
+	NetworkInterfaces []api.AWSNetworkInterfaceSpec `json:"networkInterfaces,omitempty"`
+
+	// Region contains the AWS region for the machine
+	Region string `json:"region,omitempty"`
+
+	// If set to false, source and destination checks are disabled, default is true
+	SrcAndDstChecksEnabled *bool `json:"srcAndDstChecksEnabled,omitempty"`
+
+	// Tags to be specified on the EC2 instances
+	Tags map[string]string `json:"tags,omitempty"`
+
+	// InstanceMetadataOptions contains configuration for controlling access to the metadata API.
+	InstanceMetadataOptions *api.InstanceMetadataOptions `json:"instanceMetadataOptions,omitempty"`
+
+	// CPUOptions contains detailed configuration for the number of cores and threads for the instance.
+	CPUOptions *api.CPUOptions `json:"cpuOptions,omitempty"`
+
+	// EnclaveOptions enables AWS Nitro Enclaves for the instance, see api.AWSEnclaveOptions.
+	EnclaveOptions *api.AWSEnclaveOptions `json:"enclaveOptions,omitempty"`
+
+	// LicenseConfigurationARNs are the ARNs of the License Manager license configurations to associate with the
+	// instance.
+	LicenseConfigurationARNs []string `json:"licenseConfigurationArns,omitempty"`
+
+	// BootMode is the boot mode the instance must be booted with, see api.AWSProviderSpec.
+	BootMode *string `json:"bootMode,omitempty"`
+
+	// TPMSupport requires a NitroTPM of the given version for the instance, see api.AWSProviderSpec.
+	TPMSupport *string `json:"tpmSupport,omitempty"`
+
+	// Placement contains placement configuration for the instance (placement groups, tenancy, dedicated hosts).
+	Placement *api.AWSPlacementSpec `json:"placement,omitempty"`
+
+	// InstanceMarketOptions configures the instance market type.
+	// If not specified, on-demand instances are launched.
+	InstanceMarketOptions *api.AWSInstanceMarketOptions `json:"instanceMarketOptions,omitempty"`
+
+	// EFA attaches an Elastic Fabric Adapter to every network card of the machine type, see api.AWSEFASpec.
+	EFA *api.AWSEFASpec `json:"efa,omitempty"`
+
+	// ElasticIP associates an Elastic IP address with the machine, see api.AWSElasticIPSpec.
+	ElasticIP *api.AWSElasticIPSpec `json:"elasticIP,omitempty"`
+
+	// PrivateDNSNameOptions configures the hostname type of the instance, see api.AWSPrivateDNSNameOptions.
+	PrivateDNSNameOptions *api.AWSPrivateDNSNameOptions `json:"privateDNSNameOptions,omitempty"`
+
+	// CreditSpecification configures the CPU credits of burstable performance instances, see api.AWSCreditSpecification.
+	CreditSpecification *api.AWSCreditSpecification `json:"creditSpecification,omitempty"`
+
+	// MaintenanceOptions configures how the instance is treated by AWS maintenance, see api.AWSMaintenanceOptions.
+	MaintenanceOptions *api.AWSMaintenanceOptions `json:"maintenanceOptions,omitempty"`
+
+	// HibernationOptions enables hibernation of the instance, see api.AWSHibernationOptions.
+	HibernationOptions *api.AWSHibernationOptions `json:"hibernationOptions,omitempty"`
+}
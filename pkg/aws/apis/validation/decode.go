@@ -0,0 +1,151 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package validation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	sigsjson "sigs.k8s.io/json"
+
+	awsapi "github.com/gardener/machine-controller-manager-provider-aws/pkg/aws/apis"
+	"github.com/gardener/machine-controller-manager-provider-aws/pkg/aws/apis/v1alpha2"
+)
+
+// DecodeAWSProviderSpec decodes the raw providerSpec of a MachineClass according to its apiVersion and returns it in
+// the internal representation. An empty apiVersion is treated as awsapi.V1alpha1. The spec is decoded exactly like
+// the driver always did (i.e. field names are matched case-insensitively), and additionally a field error is
+// returned for every field of the raw providerSpec which is not a field of the versioned type. Such fields are
+// silently dropped by the decoding and usually indicate a typo. Fields whose name only differs in case from a field of
+// the versioned type are decoded, they are returned separately as caseMismatchErrs. The returned error is only set if
+// the providerSpec cannot be decoded at all, it is a *field.Error if the apiVersion is not supported.
+func DecodeAWSProviderSpec(raw []byte, fldPath *field.Path) (spec *awsapi.AWSProviderSpec, unknownFieldErrs, caseMismatchErrs field.ErrorList, err error) {
+	versioned := &struct {
+		APIVersion string `json:"apiVersion"`
+	}{}
+	if err := json.Unmarshal(raw, versioned); err != nil {
+		return nil, nil, nil, err
+	}
+
+	switch versioned.APIVersion {
+	case "", awsapi.V1alpha1:
+		spec := &awsapi.AWSProviderSpec{}
+		unknownFieldErrs, caseMismatchErrs, err := decodeStrict(raw, spec, fldPath)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		return spec, unknownFieldErrs, caseMismatchErrs, nil
+	case awsapi.V1alpha2:
+		spec := &v1alpha2.AWSProviderSpec{}
+		unknownFieldErrs, caseMismatchErrs, err := decodeStrict(raw, spec, fldPath)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		v1alpha2.SetDefaults(spec)
+		return v1alpha2.ConvertToInternal(spec), unknownFieldErrs, caseMismatchErrs, nil
+	default:
+		return nil, nil, nil, field.NotSupported(fldPath.Child("apiVersion"), versioned.APIVersion, []string{awsapi.V1alpha1, awsapi.V1alpha2})
+	}
+}
+
+// decodeStrict decodes raw into spec and returns a field error for every unknown field. The strict decoding is
+// case-sensitive, so fields which are only unknown because of their case are returned separately.
+func decodeStrict(raw []byte, spec any, fldPath *field.Path) (unknownFieldErrs, caseMismatchErrs field.ErrorList, err error) {
+	if err := json.Unmarshal(raw, spec); err != nil {
+		return nil, nil, err
+	}
+
+	specType := reflect.TypeOf(spec).Elem()
+	strictErrs, err := sigsjson.UnmarshalStrict(raw, reflect.New(specType).Interface(), sigsjson.DisallowUnknownFields)
+	if err != nil {
+		return nil, nil, err
+	}
+	unknownFieldErrs, caseMismatchErrs = field.ErrorList{}, field.ErrorList{}
+	for _, strictErr := range strictErrs {
+		var fieldErr sigsjson.FieldError
+		if !errors.As(strictErr, &fieldErr) {
+			unknownFieldErrs = append(unknownFieldErrs, field.InternalError(fldPath, strictErr))
+			continue
+		}
+		if name, ok := caseInsensitiveFieldName(specType, fieldErr.FieldPath()); ok {
+			caseMismatchErrs = append(caseMismatchErrs, field.Invalid(fldPath.Child(fieldErr.FieldPath()), name, fmt.Sprintf("field name differs in case from %q", name)))
+			continue
+		}
+		unknownFieldErrs = append(unknownFieldErrs, field.Forbidden(fldPath.Child(fieldErr.FieldPath()), "unknown field"))
+	}
+	return unknownFieldErrs, caseMismatchErrs, nil
+}
+
+// caseInsensitiveFieldName returns the JSON name of the field of typ which the last element of the JSON path matches
+// case-insensitively, if all other elements match exactly. Paths are formatted like "blockDevices[0].ebs.volumeType".
+func caseInsensitiveFieldName(typ reflect.Type, path string) (string, bool) {
+	segments := strings.Split(path, ".")
+	for i, segment := range segments {
+		name, indices, _ := strings.Cut(segment, "[")
+		typ = indirectType(typ)
+		switch typ.Kind() {
+		case reflect.Map:
+			typ = typ.Elem()
+		case reflect.Struct:
+			jsonName, fieldType, found := jsonField(typ, name)
+			if !found {
+				return "", false
+			}
+			if i == len(segments)-1 {
+				return jsonName, jsonName != name
+			}
+			if jsonName != name {
+				return "", false
+			}
+			typ = fieldType
+		default:
+			return "", false
+		}
+		for range strings.Count(indices, "]") {
+			typ = indirectType(typ).Elem()
+		}
+	}
+	return "", false
+}
+
+// jsonField returns the JSON name and type of the field of the struct type which matches name, preferring an exact
+// match over a case-insensitive one like encoding/json.
+func jsonField(typ reflect.Type, name string) (string, reflect.Type, bool) {
+	var (
+		foldName string
+		foldType reflect.Type
+	)
+	for _, f := range reflect.VisibleFields(typ) {
+		if !f.IsExported() {
+			continue
+		}
+		tag := f.Tag.Get("json")
+		if tag == "-" || (f.Anonymous && tag == "") {
+			continue
+		}
+		jsonName, _, _ := strings.Cut(tag, ",")
+		if jsonName == "" {
+			jsonName = f.Name
+		}
+		if jsonName == name {
+			return jsonName, f.Type, true
+		}
+		if foldType == nil && strings.EqualFold(jsonName, name) {
+			foldName, foldType = jsonName, f.Type
+		}
+	}
+	return foldName, foldType, foldType != nil
+}
+
+func indirectType(typ reflect.Type) reflect.Type {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	return typ
+}
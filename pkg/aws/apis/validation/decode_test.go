@@ -0,0 +1,93 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package validation
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gstruct"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	awsapi "github.com/gardener/machine-controller-manager-provider-aws/pkg/aws/apis"
+)
+
+var _ = Describe("Decode", func() {
+	Describe("#DecodeAWSProviderSpec", func() {
+		fldPath := field.NewPath("providerSpec")
+
+		It("should decode a spec without unknown fields", func() {
+			spec, unknownFieldErrs, _, err := DecodeAWSProviderSpec([]byte(`{"ami":"ami-123","blockDevices":[{"ebs":{"volumeType":"gp3"}}]}`), fldPath)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(unknownFieldErrs).To(BeEmpty())
+			Expect(spec.AMI).To(Equal("ami-123"))
+			Expect(spec.BlockDevices[0].Ebs.VolumeType).To(Equal("gp3"))
+		})
+
+		It("should report unknown fields and field names which differ in case with their path but decode like before", func() {
+			spec, unknownFieldErrs, caseMismatchErrs, err := DecodeAWSProviderSpec([]byte(`{"ami":"ami-123","blockDevices":[{"ebs":{"volumetype":"gp3","kmskeyID":"key"}}],"spotPrise":"1","Tags":{"a":"b"}}`), fldPath)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(unknownFieldErrs).To(ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{"Type": Equal(field.ErrorTypeForbidden), "Field": Equal("providerSpec.spotPrise")})),
+			))
+			Expect(caseMismatchErrs).To(ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{"Type": Equal(field.ErrorTypeInvalid), "Field": Equal("providerSpec.blockDevices[0].ebs.volumetype"), "BadValue": Equal("volumeType")})),
+				PointTo(MatchFields(IgnoreExtras, Fields{"Type": Equal(field.ErrorTypeInvalid), "Field": Equal("providerSpec.blockDevices[0].ebs.kmskeyID"), "BadValue": Equal("kmsKeyID")})),
+				PointTo(MatchFields(IgnoreExtras, Fields{"Type": Equal(field.ErrorTypeInvalid), "Field": Equal("providerSpec.Tags"), "BadValue": Equal("tags")})),
+			))
+			// Field names are matched case-insensitively by the decoding itself
+			Expect(spec.BlockDevices[0].Ebs.VolumeType).To(Equal("gp3"))
+			Expect(spec.Tags).To(HaveKeyWithValue("a", "b"))
+		})
+
+		It("should report unknown fields below a field name which differs in case as a case mismatch only", func() {
+			_, unknownFieldErrs, caseMismatchErrs, err := DecodeAWSProviderSpec([]byte(`{"ami":"ami-123","BlockDevices":[{"ebs":{"volumeTyp":"gp3"}}]}`), fldPath)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(unknownFieldErrs).To(BeEmpty())
+			Expect(caseMismatchErrs).To(ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{"Field": Equal("providerSpec.BlockDevices")})),
+			))
+		})
+
+		It("should decode and default a v1alpha2 spec", func() {
+			spec, unknownFieldErrs, _, err := DecodeAWSProviderSpec([]byte(`{"apiVersion":"mcm.gardener.cloud/v1alpha2","ami":"ami-123","blockDevices":[{"ebs":{"volumeType":"gp3"}}],"instanceMarketOptions":{"marketType":"spot","spotOptions":{"maxPrice":"0.5"}}}`), fldPath)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(unknownFieldErrs).To(BeEmpty())
+			Expect(spec.APIVersion).To(Equal(awsapi.V1alpha2))
+			Expect(spec.BlockDevices[0].Ebs.DeleteOnTermination).To(PointTo(BeTrue()))
+			Expect(spec.InstanceMarketOptions.SpotOptions.MaxPrice).To(PointTo(Equal("0.5")))
+		})
+
+		It("should report deprecated v1alpha1 fields as unknown in a v1alpha2 spec", func() {
+			spec, unknownFieldErrs, _, err := DecodeAWSProviderSpec([]byte(`{"apiVersion":"mcm.gardener.cloud/v1alpha2","ami":"ami-123","spotPrice":"0.5"}`), fldPath)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(unknownFieldErrs).To(ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{"Type": Equal(field.ErrorTypeForbidden), "Field": Equal("providerSpec.spotPrice")})),
+			))
+			Expect(spec.SpotPrice).To(BeNil())
+		})
+
+		It("should fail for an unsupported apiVersion", func() {
+			_, _, _, err := DecodeAWSProviderSpec([]byte(`{"apiVersion":"mcm.gardener.cloud/v2","ami":"ami-123"}`), fldPath)
+
+			var fieldErr *field.Error
+			Expect(errors.As(err, &fieldErr)).To(BeTrue())
+			Expect(fieldErr.Type).To(Equal(field.ErrorTypeNotSupported))
+			Expect(fieldErr.Field).To(Equal("providerSpec.apiVersion"))
+		})
+
+		It("should fail for invalid JSON", func() {
+			_, _, _, err := DecodeAWSProviderSpec([]byte(`{"ami":`), fldPath)
+
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,69 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package validation
+
+import (
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+
+	awsapi "github.com/gardener/machine-controller-manager-provider-aws/pkg/aws/apis"
+)
+
+var (
+	validNetworkInterfaceTypes = []string{
+		"",
+		string(ec2types.NetworkInterfaceTypeInterface),
+		string(ec2types.NetworkInterfaceTypeEfa),
+		string(ec2types.NetworkInterfaceTypeEfaOnly),
+	}
+	validEFAInterfaceTypes = []string{
+		string(ec2types.NetworkInterfaceTypeEfaOnly),
+		string(ec2types.NetworkInterfaceTypeEfa),
+	}
+	validTenancies              = []string{"default", "dedicated", "host"}
+	validAffinities             = []string{"default", "host"}
+	validMarketTypes            = enumValues(ec2types.MarketType("").Values())
+	validHostnameTypes          = enumValues(ec2types.HostnameType("").Values())
+	validCPUCredits             = []string{"standard", "unlimited"}
+	validAutoRecoveryStates     = enumValues(ec2types.InstanceAutoRecoveryState("").Values())
+	validRebootMigrationStates  = enumValues(ec2types.InstanceRebootMigrationState("").Values())
+	validBootModes              = enumValues(ec2types.InstanceBootModeValues("").Values())
+	validTPMSupportValues       = enumValues(ec2types.TpmSupportValues("").Values())
+	validHTTPEndpoints          = []string{awsapi.HTTPEndpointDisabled, awsapi.HTTPEndpointEnabled}
+	validHTTPTokens             = []string{awsapi.HTTPTokensRequired, awsapi.HTTPTokensOptional}
+	validHTTPProtocolIPv6States = []string{
+		string(ec2types.InstanceMetadataProtocolStateEnabled),
+		string(ec2types.InstanceMetadataProtocolStateDisabled),
+	}
+)
+
+// Enums contains the values accepted by ValidateAWSProviderSpec for enumerated providerSpec fields. The keys are the
+// JSON paths of the fields without list indices, e.g. "blockDevices.ebs.volumeType". Optional fields which are left
+// empty are not subject to these values.
+var Enums = map[string][]string{
+	"blockDevices.ebs.volumeType":              awsapi.ValidVolumeTypes,
+	"networkInterfaces.interfaceType":          validNetworkInterfaceTypes[1:],
+	"placement.tenancy":                        validTenancies,
+	"placement.affinity":                       validAffinities,
+	"instanceMarketOptions.marketType":         validMarketTypes,
+	"instanceMetadataOptions.httpEndpoint":     validHTTPEndpoints,
+	"instanceMetadataOptions.httpTokens":       validHTTPTokens,
+	"instanceMetadataOptions.httpProtocolIpv6": validHTTPProtocolIPv6States,
+	"cpuOptions.amdSevSnp":                     enumValues(ec2types.AmdSevSnpSpecification("").Values()),
+	"efa.interfaceType":                        validEFAInterfaceTypes,
+	"privateDNSNameOptions.hostnameType":       validHostnameTypes,
+	"creditSpecification.cpuCredits":           validCPUCredits,
+	"maintenanceOptions.autoRecovery":          validAutoRecoveryStates,
+	"maintenanceOptions.rebootMigration":       validRebootMigrationStates,
+	"bootMode":                                 validBootModes,
+	"tpmSupport":                               validTPMSupportValues,
+}
+
+func enumValues[T ~string](values []T) []string {
+	result := make([]string, len(values))
+	for i, value := range values {
+		result[i] = string(value)
+	}
+	return result
+}
@@ -7,15 +7,19 @@ package validation
 
 import (
 	"fmt"
+	"net/netip"
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
 	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
 	"golang.org/x/exp/slices"
 	corev1 "k8s.io/api/core/v1"
 	utilvalidation "k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/ptr"
 
 	awsapi "github.com/gardener/machine-controller-manager-provider-aws/pkg/aws/apis"
 )
@@ -42,12 +46,21 @@ func ValidateAWSProviderSpec(spec *awsapi.AWSProviderSpec, secret *corev1.Secret
 	allErrs = append(allErrs, validateBlockDevices(spec.BlockDevices, fldPath.Child("blockDevices"))...)
 	allErrs = append(allErrs, validateCapacityReservations(spec.CapacityReservationTarget, fldPath.Child("capacityReservation"))...)
 	allErrs = append(allErrs, validateNetworkInterfaces(spec.NetworkInterfaces, fldPath.Child("networkInterfaces"))...)
-	allErrs = append(allErrs, validatePlacement(spec.Placement, fldPath.Child("placement"))...)
+	allErrs = append(allErrs, ValidatePlacement(spec.Placement, fldPath.Child("placement"))...)
 	allErrs = append(allErrs, validateInstanceMarketOptions(spec.InstanceMarketOptions, fldPath.Child("instanceMarketOptions"))...)
 	allErrs = append(allErrs, ValidateSecret(secret, field.NewPath("secretRef"))...)
 	allErrs = append(allErrs, validateSpecTags(spec.Tags, fldPath.Child("tags"))...)
 	allErrs = append(allErrs, validateInstanceMetadata(spec.InstanceMetadataOptions, fldPath.Child("instanceMetadata"))...)
 	allErrs = append(allErrs, validateCPUOptions(spec.CPUOptions, fldPath.Child(("cpuOptions")))...)
+	allErrs = append(allErrs, validateEnclaveOptions(spec, fldPath)...)
+	allErrs = append(allErrs, validateLicenseConfigurationARNs(spec.LicenseConfigurationARNs, fldPath.Child("licenseConfigurationArns"))...)
+	allErrs = append(allErrs, validateBootOptions(spec, fldPath)...)
+	allErrs = append(allErrs, validateEFA(spec.EFA, spec.NetworkInterfaces, fldPath)...)
+	allErrs = append(allErrs, validateElasticIP(spec.ElasticIP, fldPath.Child("elasticIP"))...)
+	allErrs = append(allErrs, validateHibernationOptions(spec, fldPath)...)
+	allErrs = append(allErrs, validateMaintenanceOptions(spec.MaintenanceOptions, fldPath.Child("maintenanceOptions"))...)
+	allErrs = append(allErrs, validateCreditSpecification(spec.CreditSpecification, fldPath.Child("creditSpecification"))...)
+	allErrs = append(allErrs, validatePrivateDNSNameOptions(spec.PrivateDNSNameOptions, spec.NetworkInterfaces, fldPath.Child("privateDNSNameOptions"))...)
 
 	return allErrs
 }
@@ -150,13 +163,6 @@ func validateCapacityReservations(capacityReservation *awsapi.AWSCapacityReserva
 	return allErrs
 }
 
-var validNetworkInterfaceTypes = []string{
-	"",
-	string(ec2types.NetworkInterfaceTypeInterface),
-	string(ec2types.NetworkInterfaceTypeEfa),
-	string(ec2types.NetworkInterfaceTypeEfaOnly),
-}
-
 func validateNetworkInterfaces(networkInterfaces []awsapi.AWSNetworkInterfaceSpec, fldPath *field.Path) field.ErrorList {
 	var (
 		allErrs = field.ErrorList{}
@@ -168,17 +174,21 @@ func validateNetworkInterfaces(networkInterfaces []awsapi.AWSNetworkInterfaceSpe
 		for i := range networkInterfaces {
 			idxPath := fldPath.Index(i)
 
-			if networkInterfaces[i].SubnetID == "" {
-				allErrs = append(allErrs, field.Required(idxPath.Child("subnetID"), "SubnetID is required"))
-			}
-
-			if len(networkInterfaces[i].SecurityGroupIDs) == 0 {
-				allErrs = append(allErrs, field.Required(idxPath.Child("securityGroupIDs"), "Mention at least one securityGroupID"))
+			if networkInterfaces[i].IsExisting() {
+				allErrs = append(allErrs, validateExistingNetworkInterface(networkInterfaces[i], idxPath)...)
 			} else {
-				for j := range networkInterfaces[i].SecurityGroupIDs {
-					if networkInterfaces[i].SecurityGroupIDs[j] == "" {
-						output := strings.Join([]string{"securityGroupIDs cannot be blank for networkInterface:", strconv.Itoa(i), " securityGroupID:", strconv.Itoa(j)}, "")
-						allErrs = append(allErrs, field.Required(idxPath.Child("securityGroupIDs"), output))
+				if networkInterfaces[i].SubnetID == "" {
+					allErrs = append(allErrs, field.Required(idxPath.Child("subnetID"), "SubnetID is required"))
+				}
+
+				if len(networkInterfaces[i].SecurityGroupIDs) == 0 {
+					allErrs = append(allErrs, field.Required(idxPath.Child("securityGroupIDs"), "Mention at least one securityGroupID"))
+				} else {
+					for j := range networkInterfaces[i].SecurityGroupIDs {
+						if networkInterfaces[i].SecurityGroupIDs[j] == "" {
+							output := strings.Join([]string{"securityGroupIDs cannot be blank for networkInterface:", strconv.Itoa(i), " securityGroupID:", strconv.Itoa(j)}, "")
+							allErrs = append(allErrs, field.Required(idxPath.Child("securityGroupIDs"), output))
+						}
 					}
 				}
 			}
@@ -194,8 +204,113 @@ func validateNetworkInterfaces(networkInterfaces []awsapi.AWSNetworkInterfaceSpe
 			if networkInterfaces[i].NetworkCardIndex != nil && *networkInterfaces[i].NetworkCardIndex < 0 {
 				allErrs = append(allErrs, field.Invalid(idxPath.Child("networkCardIndex"), *networkInterfaces[i].NetworkCardIndex, "must be >= 0"))
 			}
+
+			allErrs = append(allErrs, validateIPv4Counts(networkInterfaces[i], idxPath)...)
+			allErrs = append(allErrs, validateStaticAddressRanges(networkInterfaces[i], idxPath)...)
+		}
+	}
+	return allErrs
+}
+
+// validateExistingNetworkInterface validates a reference to an existing network interface. Fields which only apply to
+// network interfaces created at launch must not be set.
+func validateExistingNetworkInterface(networkInterface awsapi.AWSNetworkInterfaceSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if networkInterface.NetworkInterfaceID != nil {
+		if *networkInterface.NetworkInterfaceID == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Child("networkInterfaceID"), "networkInterfaceID must not be empty"))
+		}
+		if len(networkInterface.NetworkInterfaceTags) > 0 {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("networkInterfaceTags"), "networkInterfaceID and networkInterfaceTags are mutually exclusive"))
+		}
+	}
+	for key := range networkInterface.NetworkInterfaceTags {
+		if key == "" {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("networkInterfaceTags"), key, "tag keys must not be empty"))
+		}
+	}
+
+	const detail = "must not be set for an existing network interface"
+	if networkInterface.SubnetID != "" {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("subnetID"), detail))
+	}
+	if len(networkInterface.SecurityGroupIDs) > 0 {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("securityGroupIDs"), detail))
+	}
+	if networkInterface.Description != nil {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("description"), detail))
+	}
+	if networkInterface.InterfaceType != nil {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("interfaceType"), detail))
+	}
+	if networkInterface.AssociatePublicIPAddress != nil {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("associatePublicIPAddress"), detail))
+	}
+	if networkInterface.Ipv6AddressCount != nil {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("ipv6AddressCount"), detail))
+	}
+	if ptr.Deref(networkInterface.DeleteOnTermination, false) {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("deleteOnTermination"), "existing network interfaces are never deleted on termination"))
+	}
+	return allErrs
+}
+
+// validateStaticAddressRanges validates the ranges from which static addresses of a network interface are chosen.
+func validateStaticAddressRanges(networkInterface awsapi.AWSNetworkInterfaceSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	efaOnly := ptr.Deref(networkInterface.InterfaceType, "") == string(ec2types.NetworkInterfaceTypeEfaOnly)
+
+	validateRange := func(cidr *string, ipv4 bool, rangePath *field.Path) {
+		family := "IPv6"
+		if ipv4 {
+			family = "IPv4"
+		}
+		if cidr == nil {
+			return
+		}
+		if networkInterface.IsExisting() {
+			allErrs = append(allErrs, field.Forbidden(rangePath, "must not be set for an existing network interface"))
+			return
+		}
+		if efaOnly {
+			allErrs = append(allErrs, field.Forbidden(rangePath, "efa-only interfaces have no IP addresses"))
+			return
+		}
+		prefix, err := netip.ParsePrefix(*cidr)
+		if err != nil {
+			allErrs = append(allErrs, field.Invalid(rangePath, *cidr, err.Error()))
+		} else if prefix.Addr().Is4() != ipv4 {
+			allErrs = append(allErrs, field.Invalid(rangePath, *cidr, fmt.Sprintf("must be an %s CIDR", family)))
+		}
+	}
+	validateRange(networkInterface.PrivateIPv4CIDR, true, fldPath.Child("privateIPv4CIDR"))
+	validateRange(networkInterface.IPv6CIDR, false, fldPath.Child("ipv6CIDR"))
+
+	if networkInterface.IPv6CIDR != nil && networkInterface.Ipv6AddressCount != nil {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("ipv6CIDR"), "ipv6CIDR and ipv6AddressCount are mutually exclusive"))
+	}
+	return allErrs
+}
+
+// validateIPv4Counts validates the number of secondary private IPv4 addresses and IPv4 prefixes of a network interface.
+// EFA-only interfaces have no IP addresses at all.
+func validateIPv4Counts(networkInterface awsapi.AWSNetworkInterfaceSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	efaOnly := ptr.Deref(networkInterface.InterfaceType, "") == string(ec2types.NetworkInterfaceTypeEfaOnly)
+
+	validateCount := func(count *int32, countPath *field.Path) {
+		if count == nil {
+			return
+		}
+		if *count < 0 {
+			allErrs = append(allErrs, field.Invalid(countPath, *count, "must be >= 0"))
+		} else if efaOnly && *count > 0 {
+			allErrs = append(allErrs, field.Forbidden(countPath, "efa-only interfaces have no IP addresses"))
 		}
 	}
+	validateCount(networkInterface.SecondaryPrivateIPAddressCount, fldPath.Child("secondaryPrivateIPAddressCount"))
+	validateCount(networkInterface.Ipv4PrefixCount, fldPath.Child("ipv4PrefixCount"))
 	return allErrs
 }
 
@@ -210,19 +325,15 @@ func validateInstanceMetadata(metadata *awsapi.InstanceMetadataOptions, fldPath
 	}
 
 	if metadata.HTTPEndpoint != "" {
-		allErrs = append(allErrs, validateStringValues(fldPath.Child("httpEndpoint"), metadata.HTTPEndpoint, []string{awsapi.HTTPEndpointDisabled, awsapi.HTTPEndpointEnabled})...)
+		allErrs = append(allErrs, validateStringValues(fldPath.Child("httpEndpoint"), metadata.HTTPEndpoint, validHTTPEndpoints)...)
 	}
 
 	if metadata.HTTPTokens != "" {
-		allErrs = append(allErrs, validateStringValues(fldPath.Child("httpTokens"), metadata.HTTPTokens, []string{awsapi.HTTPTokensRequired, awsapi.HTTPTokensOptional})...)
+		allErrs = append(allErrs, validateStringValues(fldPath.Child("httpTokens"), metadata.HTTPTokens, validHTTPTokens)...)
 	}
 
 	if metadata.HTTPProtocolIPv6 != "" {
-		allErrs = append(allErrs, validateStringValues(fldPath.Child("httpProtocolIpv6"), metadata.HTTPProtocolIPv6,
-			[]string{
-				string(ec2types.InstanceMetadataProtocolStateEnabled),
-				string(ec2types.InstanceMetadataProtocolStateDisabled),
-			})...)
+		allErrs = append(allErrs, validateStringValues(fldPath.Child("httpProtocolIpv6"), metadata.HTTPProtocolIPv6, validHTTPProtocolIPv6States)...)
 	}
 
 	return allErrs
@@ -269,6 +380,38 @@ func validateCPUOptions(cpuOptions *awsapi.CPUOptions, fldPath *field.Path) fiel
 	return allErrs
 }
 
+// validateEnclaveOptions rejects Nitro Enclaves on instances which are hibernated, as AWS does not support both.
+func validateEnclaveOptions(spec *awsapi.AWSProviderSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if spec.EnclaveOptions == nil || !spec.EnclaveOptions.Enabled {
+		return allErrs
+	}
+
+	if spec.HibernationOptions != nil && spec.HibernationOptions.Configured {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("enclaveOptions", "enabled"), "Nitro Enclaves cannot be enabled together with hibernation"))
+	}
+	return allErrs
+}
+
+// validateBootOptions validates the boot mode and NitroTPM version. Whether the AMI supports them is validated against
+// the AMI when the machine is created.
+func validateBootOptions(spec *awsapi.AWSProviderSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if spec.BootMode != nil && !slices.Contains(validBootModes, *spec.BootMode) {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("bootMode"), *spec.BootMode, validBootModes))
+	}
+	if spec.TPMSupport != nil {
+		if !slices.Contains(validTPMSupportValues, *spec.TPMSupport) {
+			allErrs = append(allErrs, field.NotSupported(fldPath.Child("tpmSupport"), *spec.TPMSupport, validTPMSupportValues))
+		}
+		if spec.BootMode != nil && *spec.BootMode != string(ec2types.InstanceBootModeValuesUefi) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("bootMode"), *spec.BootMode, "NitroTPM requires the uefi boot mode"))
+		}
+	}
+	return allErrs
+}
+
 // ValidateSecret makes sure that the supplied secrets contains the required fields
 func ValidateSecret(secret *corev1.Secret, fldPath *field.Path) field.ErrorList {
 	var (
@@ -311,21 +454,21 @@ func validateStringValues(fld *field.Path, s string, accepted []string) field.Er
 	return field.ErrorList{field.Invalid(fld, s, fmt.Sprintf("Accepted values: %v", accepted))}
 }
 
-func validatePlacement(placement *awsapi.AWSPlacementSpec, fldPath *field.Path) field.ErrorList {
+// ValidatePlacement validates the placement of the providerSpec. It is also used for the deprecated awsPlacement
+// annotation of machines.
+func ValidatePlacement(placement *awsapi.AWSPlacementSpec, fldPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
 	if placement == nil {
 		return allErrs
 	}
 
 	if placement.Tenancy != nil {
-		validTenancies := []string{"default", "dedicated", "host"}
 		if !slices.Contains(validTenancies, *placement.Tenancy) {
 			allErrs = append(allErrs, field.NotSupported(fldPath.Child("tenancy"), *placement.Tenancy, validTenancies))
 		}
 	}
 
 	if placement.Affinity != nil {
-		validAffinities := []string{"default", "host"}
 		if !slices.Contains(validAffinities, *placement.Affinity) {
 			allErrs = append(allErrs, field.NotSupported(fldPath.Child("affinity"), *placement.Affinity, validAffinities))
 		}
@@ -335,10 +478,93 @@ func validatePlacement(placement *awsapi.AWSPlacementSpec, fldPath *field.Path)
 		allErrs = append(allErrs, field.Forbidden(fldPath.Child("hostId"), "hostId can only be set when tenancy is \"host\""))
 	}
 
+	if placement.HostResourceGroupARN != nil || placement.HostResourceGroupName != nil {
+		allErrs = append(allErrs, validateHostResourceGroup(placement, fldPath)...)
+	}
+
+	if placement.DedicatedHosts != nil {
+		allErrs = append(allErrs, validateDedicatedHosts(placement, fldPath)...)
+	}
+
 	if placement.PartitionNumber != nil && *placement.PartitionNumber < 1 {
 		allErrs = append(allErrs, field.Invalid(fldPath.Child("partitionNumber"), *placement.PartitionNumber, "must be >= 1"))
 	}
 
+	if placement.GroupID != nil && placement.GroupName != nil {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("groupName"), "groupName and groupId are mutually exclusive"))
+	}
+
+	if placement.SpreadPartitions {
+		if placement.GroupID == nil && placement.GroupName == nil {
+			allErrs = append(allErrs, field.Required(fldPath.Child("groupId"), "spreading partitions requires groupId or groupName"))
+		}
+		if placement.PartitionNumber != nil {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("partitionNumber"), "partitionNumber cannot be set when spreadPartitions is enabled"))
+		}
+	}
+
+	return allErrs
+}
+
+// validateHostResourceGroup validates the host resource group of the placement, which allocates the dedicated hosts of
+// the instances itself.
+func validateHostResourceGroup(placement *awsapi.AWSPlacementSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if placement.HostResourceGroupARN != nil {
+		if placement.HostResourceGroupName != nil {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("hostResourceGroupName"), "hostResourceGroupName and hostResourceGroupArn are mutually exclusive"))
+		}
+		if resourceARN, err := arn.Parse(*placement.HostResourceGroupARN); err != nil || resourceARN.Service != "resource-groups" {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("hostResourceGroupArn"), *placement.HostResourceGroupARN, "must be the ARN of a host resource group"))
+		}
+	} else if *placement.HostResourceGroupName == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("hostResourceGroupName"), "hostResourceGroupName must not be empty"))
+	}
+	if placement.Tenancy == nil || *placement.Tenancy != "host" {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("tenancy"), "tenancy must be \"host\" when a host resource group is set"))
+	}
+	if placement.HostID != nil {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("hostId"), "hostId cannot be combined with a host resource group"))
+	}
+	return allErrs
+}
+
+// validateDedicatedHosts validates the automatic placement on Dedicated Hosts, which chooses the host of the instance
+// itself.
+func validateDedicatedHosts(placement *awsapi.AWSPlacementSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	hostsPath := fldPath.Child("dedicatedHosts")
+
+	if placement.Tenancy == nil || *placement.Tenancy != "host" {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("tenancy"), "tenancy must be \"host\" when dedicatedHosts is set"))
+	}
+	if placement.HostID != nil {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("hostId"), "hostId cannot be combined with dedicatedHosts"))
+	}
+	if placement.HostResourceGroupARN != nil || placement.HostResourceGroupName != nil {
+		allErrs = append(allErrs, field.Forbidden(hostsPath, "dedicatedHosts cannot be combined with a host resource group"))
+	}
+
+	if holdDuration := placement.DedicatedHosts.MinimumHoldDuration; holdDuration != nil {
+		if duration, err := time.ParseDuration(*holdDuration); err != nil || duration < 0 {
+			allErrs = append(allErrs, field.Invalid(hostsPath.Child("minimumHoldDuration"), *holdDuration, "must be a non-negative duration, e.g. \"24h\""))
+		}
+		if !placement.DedicatedHosts.Allocate {
+			allErrs = append(allErrs, field.Forbidden(hostsPath.Child("minimumHoldDuration"), "minimumHoldDuration requires allocate, only allocated hosts are released"))
+		}
+	}
+	return allErrs
+}
+
+// validateLicenseConfigurationARNs makes sure that the license configurations are License Manager ARNs.
+func validateLicenseConfigurationARNs(licenseConfigurationARNs []string, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	for i, licenseConfigurationARN := range licenseConfigurationARNs {
+		if resourceARN, err := arn.Parse(licenseConfigurationARN); err != nil || resourceARN.Service != "license-manager" {
+			allErrs = append(allErrs, field.Invalid(fldPath.Index(i), licenseConfigurationARN, "must be the ARN of a License Manager license configuration"))
+		}
+	}
 	return allErrs
 }
 
@@ -348,15 +574,162 @@ func validateInstanceMarketOptions(opts *awsapi.AWSInstanceMarketOptions, fldPat
 		return allErrs
 	}
 
-	validMarketTypes := ec2types.MarketType("").Values()
-	validStrings := make([]string, len(validMarketTypes))
-	for i, v := range validMarketTypes {
-		validStrings[i] = string(v)
+	if !slices.Contains(validMarketTypes, opts.MarketType) {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("marketType"), opts.MarketType, validMarketTypes))
 	}
 
-	if !slices.Contains(validStrings, opts.MarketType) {
-		allErrs = append(allErrs, field.NotSupported(fldPath.Child("marketType"), opts.MarketType, validStrings))
+	if opts.SpotOptions != nil && opts.MarketType != string(ec2types.MarketTypeSpot) {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("spotOptions"), "spotOptions can only be set when marketType is \"spot\""))
 	}
 
 	return allErrs
 }
+
+// validateEFA makes sure that the single network interface the EFA interfaces are derived from is a suitable primary
+// interface. The interfaces of the further network cards are added by the driver.
+func validateEFA(efa *awsapi.AWSEFASpec, networkInterfaces []awsapi.AWSNetworkInterfaceSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if efa == nil {
+		return allErrs
+	}
+
+	if efa.InterfaceType != nil && !slices.Contains(validEFAInterfaceTypes, *efa.InterfaceType) {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("efa", "interfaceType"), *efa.InterfaceType, validEFAInterfaceTypes))
+	}
+
+	if len(networkInterfaces) != 1 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("networkInterfaces"), len(networkInterfaces), "exactly one network interface is required if efa is set, the interfaces of further network cards are added automatically"))
+		return allErrs
+	}
+	idxPath := fldPath.Child("networkInterfaces").Index(0)
+	if networkInterfaces[0].IsExisting() {
+		allErrs = append(allErrs, field.Forbidden(idxPath, "efa cannot be set for an existing network interface, as the interfaces of further network cards use the subnet and security groups of the primary interface"))
+		return allErrs
+	}
+	if interfaceType := ptr.Deref(networkInterfaces[0].InterfaceType, ""); interfaceType != "" && interfaceType != string(ec2types.NetworkInterfaceTypeEfa) {
+		allErrs = append(allErrs, field.Invalid(idxPath.Child("interfaceType"), interfaceType, "the primary interface has type \"efa\" if efa is set"))
+	}
+	if cardIndex := ptr.Deref(networkInterfaces[0].NetworkCardIndex, 0); cardIndex != 0 {
+		allErrs = append(allErrs, field.Invalid(idxPath.Child("networkCardIndex"), cardIndex, "the primary interface is attached to network card 0 if efa is set"))
+	}
+	if deviceIndex := ptr.Deref(networkInterfaces[0].DeviceIndex, 0); deviceIndex != 0 {
+		allErrs = append(allErrs, field.Invalid(idxPath.Child("deviceIndex"), deviceIndex, "the primary interface has device index 0 if efa is set"))
+	}
+	return allErrs
+}
+
+func validateElasticIP(elasticIP *awsapi.AWSElasticIPSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if elasticIP == nil {
+		return allErrs
+	}
+
+	for key := range elasticIP.PoolTags {
+		if key == "" {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("poolTags"), key, "tag keys must not be empty"))
+		}
+	}
+	return allErrs
+}
+
+// validateHibernationOptions makes sure that the memory of a hibernated instance can be saved to an encrypted root volume.
+// Whether the root volume is large enough is validated against the memory of the machine type.
+func validateHibernationOptions(spec *awsapi.AWSProviderSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if spec.HibernationOptions == nil || !spec.HibernationOptions.Configured {
+		return allErrs
+	}
+
+	rootIdx := spec.RootBlockDeviceIndex()
+	if rootIdx < 0 {
+		allErrs = append(allErrs, field.Required(fldPath.Child("blockDevices"), "a root volume is required for hibernation"))
+		return allErrs
+	}
+	if !spec.BlockDevices[rootIdx].Ebs.Encrypted {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("blockDevices").Index(rootIdx).Child("ebs", "encrypted"), false, "the root volume must be encrypted for hibernation"))
+	}
+	return allErrs
+}
+
+func validateMaintenanceOptions(opts *awsapi.AWSMaintenanceOptions, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if opts == nil {
+		return allErrs
+	}
+
+	if opts.AutoRecovery != nil && !slices.Contains(validAutoRecoveryStates, *opts.AutoRecovery) {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("autoRecovery"), *opts.AutoRecovery, validAutoRecoveryStates))
+	}
+	if opts.RebootMigration != nil && !slices.Contains(validRebootMigrationStates, *opts.RebootMigration) {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("rebootMigration"), *opts.RebootMigration, validRebootMigrationStates))
+	}
+	return allErrs
+}
+
+// validateCreditSpecification validates the credit option. Whether the machine type is a burstable performance instance
+// type is validated against its capabilities.
+func validateCreditSpecification(creditSpecification *awsapi.AWSCreditSpecification, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if creditSpecification == nil {
+		return allErrs
+	}
+
+	if !slices.Contains(validCPUCredits, creditSpecification.CPUCredits) {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("cpuCredits"), creditSpecification.CPUCredits, validCPUCredits))
+	}
+	return allErrs
+}
+
+// validatePrivateDNSNameOptions makes sure that the AAAA record of the resource name can be created, which requires
+// IPv6 addresses on the primary network interface. Whether a subnet is IPv6-only is checked by the pre-flight validation.
+func validatePrivateDNSNameOptions(opts *awsapi.AWSPrivateDNSNameOptions, networkInterfaces []awsapi.AWSNetworkInterfaceSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if opts == nil {
+		return allErrs
+	}
+
+	if opts.HostnameType != nil && !slices.Contains(validHostnameTypes, *opts.HostnameType) {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("hostnameType"), *opts.HostnameType, validHostnameTypes))
+	}
+
+	if ptr.Deref(opts.EnableResourceNameDNSAAAARecord, false) {
+		for i, netIf := range networkInterfaces {
+			// #nosec: G115 -- index will not exceed int32 limits
+			if ptr.Deref(netIf.DeviceIndex, int32(i)) != 0 || ptr.Deref(netIf.NetworkCardIndex, 0) != 0 {
+				continue
+			}
+			// The addresses of existing network interfaces are not known
+			if !netIf.IsExisting() && ptr.Deref(netIf.Ipv6AddressCount, 0) == 0 && netIf.IPv6CIDR == nil {
+				allErrs = append(allErrs, field.Forbidden(fldPath.Child("enableResourceNameDNSAAAARecord"),
+					fmt.Sprintf("the primary network interface networkInterfaces[%d] has no IPv6 address, set ipv6AddressCount or ipv6CIDR", i)))
+			}
+			break
+		}
+	}
+	return allErrs
+}
+
+// ValidateDeprecatedFields validates the deprecated fields against their replacements. In contrast to
+// ValidateAWSProviderSpec, conflicts are only rejected when machines are created, so that MachineClasses which have been
+// deployed before can still be listed and deleted.
+func ValidateDeprecatedFields(spec *awsapi.AWSProviderSpec, fldPath *field.Path) field.ErrorList {
+	return validateSpotPrice(spec.SpotPrice, spec.InstanceMarketOptions, fldPath.Child("spotPrice"))
+}
+
+// validateSpotPrice rejects the deprecated spotPrice if instanceMarketOptions are set as well and request something
+// else, since instanceMarketOptions take precedence.
+func validateSpotPrice(spotPrice *string, opts *awsapi.AWSInstanceMarketOptions, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if spotPrice == nil || opts == nil {
+		return allErrs
+	}
+
+	maxPrice := ""
+	if opts.SpotOptions != nil {
+		maxPrice = ptr.Deref(opts.SpotOptions.MaxPrice, "")
+	}
+	if opts.MarketType != string(ec2types.MarketTypeSpot) || maxPrice != *spotPrice {
+		allErrs = append(allErrs, field.Invalid(fldPath, *spotPrice, "conflicts with instanceMarketOptions, remove the deprecated spotPrice and use instanceMarketOptions.spotOptions.maxPrice instead"))
+	}
+	return allErrs
+}
@@ -1890,6 +1890,324 @@ var _ = Describe("Validation", func() {
 					},
 				},
 			}),
+			Entry("Negative secondaryPrivateIPAddressCount for network interface", &data{
+				setup: setup{
+					apply: func(spec *awsapi.AWSProviderSpec) {
+						spec.NetworkInterfaces[0].SecondaryPrivateIPAddressCount = ptr.To[int32](-1)
+					},
+				},
+				action: action{
+					spec:   validAWSProviderSpec(),
+					secret: providerSecret,
+				},
+				expect: expect{
+					errToHaveOccurred: true,
+					errList: field.ErrorList{
+						{
+							Type:     "FieldValueInvalid",
+							Field:    "providerSpec.networkInterfaces[0].secondaryPrivateIPAddressCount",
+							BadValue: int32(-1),
+							Detail:   "must be >= 0",
+						},
+					},
+				},
+			}),
+			Entry("ipv4PrefixCount on efa-only network interface", &data{
+				setup: setup{
+					apply: func(spec *awsapi.AWSProviderSpec) {
+						spec.NetworkInterfaces = append(spec.NetworkInterfaces, awsapi.AWSNetworkInterfaceSpec{
+							SubnetID:         spec.NetworkInterfaces[0].SubnetID,
+							SecurityGroupIDs: spec.NetworkInterfaces[0].SecurityGroupIDs,
+							InterfaceType:    ptr.To("efa-only"),
+							NetworkCardIndex: ptr.To[int32](1),
+							DeviceIndex:      ptr.To[int32](1),
+							Ipv4PrefixCount:  ptr.To[int32](1),
+						})
+					},
+				},
+				action: action{
+					spec:   validAWSProviderSpec(),
+					secret: providerSecret,
+				},
+				expect: expect{
+					errToHaveOccurred: true,
+					errList: field.ErrorList{
+						{
+							Type:     "FieldValueForbidden",
+							Field:    "providerSpec.networkInterfaces[1].ipv4PrefixCount",
+							BadValue: "",
+							Detail:   "efa-only interfaces have no IP addresses",
+						},
+					},
+				},
+			}),
+			Entry("Empty tag key in elasticIP poolTags", &data{
+				setup: setup{
+					apply: func(spec *awsapi.AWSProviderSpec) {
+						spec.ElasticIP = &awsapi.AWSElasticIPSpec{PoolTags: map[string]string{"": "bastion"}}
+					},
+				},
+				action: action{
+					spec:   validAWSProviderSpec(),
+					secret: providerSecret,
+				},
+				expect: expect{
+					errToHaveOccurred: true,
+					errList: field.ErrorList{
+						{
+							Type:     "FieldValueInvalid",
+							Field:    "providerSpec.elasticIP.poolTags",
+							BadValue: "",
+							Detail:   "tag keys must not be empty",
+						},
+					},
+				},
+			}),
+			Entry("Existing network interface by ID", &data{
+				setup: setup{
+					apply: func(spec *awsapi.AWSProviderSpec) {
+						spec.NetworkInterfaces[0] = awsapi.AWSNetworkInterfaceSpec{NetworkInterfaceID: ptr.To("eni-123")}
+					},
+				},
+				action: action{
+					spec:   validAWSProviderSpec(),
+					secret: providerSecret,
+				},
+				expect: expect{
+					errToHaveOccurred: false,
+				},
+			}),
+			Entry("Existing network interface with fields of a new network interface", &data{
+				setup: setup{
+					apply: func(spec *awsapi.AWSProviderSpec) {
+						spec.NetworkInterfaces[0].NetworkInterfaceID = ptr.To("eni-123")
+						spec.NetworkInterfaces[0].NetworkInterfaceTags = map[string]string{"appliance": "fw"}
+						spec.NetworkInterfaces[0].DeleteOnTermination = ptr.To(true)
+					},
+				},
+				action: action{
+					spec:   validAWSProviderSpec(),
+					secret: providerSecret,
+				},
+				expect: expect{
+					errToHaveOccurred: true,
+					errList: field.ErrorList{
+						{
+							Type:     "FieldValueForbidden",
+							Field:    "providerSpec.networkInterfaces[0].networkInterfaceTags",
+							BadValue: "",
+							Detail:   "networkInterfaceID and networkInterfaceTags are mutually exclusive",
+						},
+						{
+							Type:     "FieldValueForbidden",
+							Field:    "providerSpec.networkInterfaces[0].subnetID",
+							BadValue: "",
+							Detail:   "must not be set for an existing network interface",
+						},
+						{
+							Type:     "FieldValueForbidden",
+							Field:    "providerSpec.networkInterfaces[0].securityGroupIDs",
+							BadValue: "",
+							Detail:   "must not be set for an existing network interface",
+						},
+						{
+							Type:     "FieldValueForbidden",
+							Field:    "providerSpec.networkInterfaces[0].deleteOnTermination",
+							BadValue: "",
+							Detail:   "existing network interfaces are never deleted on termination",
+						},
+					},
+				},
+			}),
+			Entry("Invalid static address ranges for network interface", &data{
+				setup: setup{
+					apply: func(spec *awsapi.AWSProviderSpec) {
+						spec.NetworkInterfaces[0].PrivateIPv4CIDR = ptr.To("2001:db8::/120")
+						spec.NetworkInterfaces[0].IPv6CIDR = ptr.To("2001:db8::/120")
+						spec.NetworkInterfaces[0].Ipv6AddressCount = ptr.To[int32](1)
+					},
+				},
+				action: action{
+					spec:   validAWSProviderSpec(),
+					secret: providerSecret,
+				},
+				expect: expect{
+					errToHaveOccurred: true,
+					errList: field.ErrorList{
+						{
+							Type:     "FieldValueInvalid",
+							Field:    "providerSpec.networkInterfaces[0].privateIPv4CIDR",
+							BadValue: "2001:db8::/120",
+							Detail:   "must be an IPv4 CIDR",
+						},
+						{
+							Type:     "FieldValueForbidden",
+							Field:    "providerSpec.networkInterfaces[0].ipv6CIDR",
+							BadValue: "",
+							Detail:   "ipv6CIDR and ipv6AddressCount are mutually exclusive",
+						},
+					},
+				},
+			}),
+			Entry("Invalid maintenanceOptions", &data{
+				setup: setup{
+					apply: func(spec *awsapi.AWSProviderSpec) {
+						spec.MaintenanceOptions = &awsapi.AWSMaintenanceOptions{
+							AutoRecovery:    ptr.To("enabled"),
+							RebootMigration: ptr.To("enabled"),
+						}
+					},
+				},
+				action: action{
+					spec:   validAWSProviderSpec(),
+					secret: providerSecret,
+				},
+				expect: expect{
+					errToHaveOccurred: true,
+					errList: field.ErrorList{
+						{
+							Type:     "FieldValueNotSupported",
+							Field:    "providerSpec.maintenanceOptions.autoRecovery",
+							BadValue: "enabled",
+							Detail:   "supported values: \"disabled\", \"default\"",
+						},
+						{
+							Type:     "FieldValueNotSupported",
+							Field:    "providerSpec.maintenanceOptions.rebootMigration",
+							BadValue: "enabled",
+							Detail:   "supported values: \"disabled\", \"default\"",
+						},
+					},
+				},
+			}),
+			Entry("Invalid boot options", &data{
+				setup: setup{
+					apply: func(spec *awsapi.AWSProviderSpec) {
+						spec.BootMode = ptr.To("legacy-bios")
+						spec.TPMSupport = ptr.To("v1.2")
+					},
+				},
+				action: action{
+					spec:   validAWSProviderSpec(),
+					secret: providerSecret,
+				},
+				expect: expect{
+					errToHaveOccurred: true,
+					errList: field.ErrorList{
+						{
+							Type:     "FieldValueNotSupported",
+							Field:    "providerSpec.tpmSupport",
+							BadValue: "v1.2",
+							Detail:   "supported values: \"v2.0\"",
+						},
+						{
+							Type:     "FieldValueInvalid",
+							Field:    "providerSpec.bootMode",
+							BadValue: "legacy-bios",
+							Detail:   "NitroTPM requires the uefi boot mode",
+						},
+					},
+				},
+			}),
+			Entry("Nitro Enclaves with hibernation", &data{
+				setup: setup{
+					apply: func(spec *awsapi.AWSProviderSpec) {
+						spec.EnclaveOptions = &awsapi.AWSEnclaveOptions{Enabled: true}
+						spec.HibernationOptions = &awsapi.AWSHibernationOptions{Configured: true}
+						spec.BlockDevices[0].Ebs.Encrypted = true
+					},
+				},
+				action: action{
+					spec:   validAWSProviderSpec(),
+					secret: providerSecret,
+				},
+				expect: expect{
+					errToHaveOccurred: true,
+					errList: field.ErrorList{
+						{
+							Type:     "FieldValueForbidden",
+							Field:    "providerSpec.enclaveOptions.enabled",
+							BadValue: "",
+							Detail:   "Nitro Enclaves cannot be enabled together with hibernation",
+						},
+					},
+				},
+			}),
+			Entry("Hibernation with unencrypted root volume", &data{
+				setup: setup{
+					apply: func(spec *awsapi.AWSProviderSpec) {
+						spec.HibernationOptions = &awsapi.AWSHibernationOptions{Configured: true}
+					},
+				},
+				action: action{
+					spec:   validAWSProviderSpec(),
+					secret: providerSecret,
+				},
+				expect: expect{
+					errToHaveOccurred: true,
+					errList: field.ErrorList{
+						{
+							Type:     "FieldValueInvalid",
+							Field:    "providerSpec.blockDevices[0].ebs.encrypted",
+							BadValue: false,
+							Detail:   "the root volume must be encrypted for hibernation",
+						},
+					},
+				},
+			}),
+			Entry("Invalid creditSpecification cpuCredits", &data{
+				setup: setup{
+					apply: func(spec *awsapi.AWSProviderSpec) {
+						spec.CreditSpecification = &awsapi.AWSCreditSpecification{CPUCredits: "boosted"}
+					},
+				},
+				action: action{
+					spec:   validAWSProviderSpec(),
+					secret: providerSecret,
+				},
+				expect: expect{
+					errToHaveOccurred: true,
+					errList: field.ErrorList{
+						{
+							Type:     "FieldValueNotSupported",
+							Field:    "providerSpec.creditSpecification.cpuCredits",
+							BadValue: "boosted",
+							Detail:   "supported values: \"standard\", \"unlimited\"",
+						},
+					},
+				},
+			}),
+			Entry("Invalid privateDNSNameOptions", &data{
+				setup: setup{
+					apply: func(spec *awsapi.AWSProviderSpec) {
+						spec.PrivateDNSNameOptions = &awsapi.AWSPrivateDNSNameOptions{
+							HostnameType:                    ptr.To("instance-name"),
+							EnableResourceNameDNSAAAARecord: ptr.To(true),
+						}
+					},
+				},
+				action: action{
+					spec:   validAWSProviderSpec(),
+					secret: providerSecret,
+				},
+				expect: expect{
+					errToHaveOccurred: true,
+					errList: field.ErrorList{
+						{
+							Type:     "FieldValueNotSupported",
+							Field:    "providerSpec.privateDNSNameOptions.hostnameType",
+							BadValue: "instance-name",
+							Detail:   "supported values: \"ip-name\", \"resource-name\"",
+						},
+						{
+							Type:     "FieldValueForbidden",
+							Field:    "providerSpec.privateDNSNameOptions.enableResourceNameDNSAAAARecord",
+							BadValue: "",
+							Detail:   "the primary network interface networkInterfaces[0] has no IPv6 address, set ipv6AddressCount or ipv6CIDR",
+						},
+					},
+				},
+			}),
 			Entry("Invalid instanceMarketOptions marketType", &data{
 				setup: setup{
 					apply: func(spec *awsapi.AWSProviderSpec) {
@@ -1914,6 +2232,49 @@ var _ = Describe("Validation", func() {
 					},
 				},
 			}),
+			Entry("spotOptions with a marketType other than spot", &data{
+				setup: setup{
+					apply: func(spec *awsapi.AWSProviderSpec) {
+						spec.InstanceMarketOptions = &awsapi.AWSInstanceMarketOptions{
+							MarketType:  "capacity-block",
+							SpotOptions: &awsapi.AWSSpotMarketOptions{MaxPrice: ptr.To("0.5")},
+						}
+					},
+				},
+				action: action{
+					spec:   validAWSProviderSpec(),
+					secret: providerSecret,
+				},
+				expect: expect{
+					errToHaveOccurred: true,
+					errList: field.ErrorList{
+						{
+							Type:     "FieldValueForbidden",
+							Field:    "providerSpec.instanceMarketOptions.spotOptions",
+							BadValue: "",
+							Detail:   `spotOptions can only be set when marketType is "spot"`,
+						},
+					},
+				},
+			}),
+			Entry("spotPrice matching instanceMarketOptions", &data{
+				setup: setup{
+					apply: func(spec *awsapi.AWSProviderSpec) {
+						spec.SpotPrice = ptr.To("0.5")
+						spec.InstanceMarketOptions = &awsapi.AWSInstanceMarketOptions{
+							MarketType:  "spot",
+							SpotOptions: &awsapi.AWSSpotMarketOptions{MaxPrice: ptr.To("0.5")},
+						}
+					},
+				},
+				action: action{
+					spec:   validAWSProviderSpec(),
+					secret: providerSecret,
+				},
+				expect: expect{
+					errToHaveOccurred: false,
+				},
+			}),
 			Entry("Invalid placement tenancy", &data{
 				setup: setup{
 					apply: func(spec *awsapi.AWSProviderSpec) {
@@ -1962,6 +2323,244 @@ var _ = Describe("Validation", func() {
 					},
 				},
 			}),
+			Entry("Placement host resource group without tenancy host", &data{
+				setup: setup{
+					apply: func(spec *awsapi.AWSProviderSpec) {
+						spec.Placement = &awsapi.AWSPlacementSpec{
+							HostResourceGroupARN: ptr.To("arn:aws:license-manager:eu-west-1:123456789012:license-configuration:lic-0123456789abcdef0"),
+						}
+						spec.LicenseConfigurationARNs = []string{"lic-0123456789abcdef0"}
+					},
+				},
+				action: action{
+					spec:   validAWSProviderSpec(),
+					secret: providerSecret,
+				},
+				expect: expect{
+					errToHaveOccurred: true,
+					errList: field.ErrorList{
+						{
+							Type:     "FieldValueInvalid",
+							Field:    "providerSpec.placement.hostResourceGroupArn",
+							BadValue: "arn:aws:license-manager:eu-west-1:123456789012:license-configuration:lic-0123456789abcdef0",
+							Detail:   "must be the ARN of a host resource group",
+						},
+						{
+							Type:     "FieldValueForbidden",
+							Field:    "providerSpec.placement.tenancy",
+							BadValue: "",
+							Detail:   `tenancy must be "host" when a host resource group is set`,
+						},
+						{
+							Type:     "FieldValueInvalid",
+							Field:    "providerSpec.licenseConfigurationArns[0]",
+							BadValue: "lic-0123456789abcdef0",
+							Detail:   "must be the ARN of a License Manager license configuration",
+						},
+					},
+				},
+			}),
+			Entry("Placement spreading partitions without placement group", &data{
+				setup: setup{
+					apply: func(spec *awsapi.AWSProviderSpec) {
+						spec.Placement = &awsapi.AWSPlacementSpec{
+							PartitionNumber:  ptr.To[int32](1),
+							SpreadPartitions: true,
+						}
+					},
+				},
+				action: action{
+					spec:   validAWSProviderSpec(),
+					secret: providerSecret,
+				},
+				expect: expect{
+					errToHaveOccurred: true,
+					errList: field.ErrorList{
+						{
+							Type:     "FieldValueRequired",
+							Field:    "providerSpec.placement.groupId",
+							BadValue: "",
+							Detail:   "spreading partitions requires groupId or groupName",
+						},
+						{
+							Type:     "FieldValueForbidden",
+							Field:    "providerSpec.placement.partitionNumber",
+							BadValue: "",
+							Detail:   "partitionNumber cannot be set when spreadPartitions is enabled",
+						},
+					},
+				},
+			}),
+			Entry("Placement with groupId and groupName", &data{
+				setup: setup{
+					apply: func(spec *awsapi.AWSProviderSpec) {
+						spec.Placement = &awsapi.AWSPlacementSpec{
+							GroupID:   ptr.To("pg-0123456789"),
+							GroupName: ptr.To("spread"),
+						}
+					},
+				},
+				action: action{
+					spec:   validAWSProviderSpec(),
+					secret: providerSecret,
+				},
+				expect: expect{
+					errToHaveOccurred: true,
+					errList: field.ErrorList{
+						{
+							Type:     "FieldValueForbidden",
+							Field:    "providerSpec.placement.groupName",
+							BadValue: "",
+							Detail:   "groupName and groupId are mutually exclusive",
+						},
+					},
+				},
+			}),
+			Entry("Placement on dedicated hosts with hostId and an invalid minimum hold", &data{
+				setup: setup{
+					apply: func(spec *awsapi.AWSProviderSpec) {
+						spec.Placement = &awsapi.AWSPlacementSpec{
+							Tenancy: ptr.To("dedicated"),
+							HostID:  ptr.To("h-0123456789"),
+							DedicatedHosts: &awsapi.AWSDedicatedHostsSpec{
+								MinimumHoldDuration: ptr.To("1 day"),
+							},
+						}
+					},
+				},
+				action: action{
+					spec:   validAWSProviderSpec(),
+					secret: providerSecret,
+				},
+				expect: expect{
+					errToHaveOccurred: true,
+					errList: field.ErrorList{
+						{
+							Type:     "FieldValueForbidden",
+							Field:    "providerSpec.placement.hostId",
+							BadValue: "",
+							Detail:   `hostId can only be set when tenancy is "host"`,
+						},
+						{
+							Type:     "FieldValueForbidden",
+							Field:    "providerSpec.placement.tenancy",
+							BadValue: "",
+							Detail:   `tenancy must be "host" when dedicatedHosts is set`,
+						},
+						{
+							Type:     "FieldValueForbidden",
+							Field:    "providerSpec.placement.hostId",
+							BadValue: "",
+							Detail:   "hostId cannot be combined with dedicatedHosts",
+						},
+						{
+							Type:     "FieldValueInvalid",
+							Field:    "providerSpec.placement.dedicatedHosts.minimumHoldDuration",
+							BadValue: "1 day",
+							Detail:   `must be a non-negative duration, e.g. "24h"`,
+						},
+						{
+							Type:     "FieldValueForbidden",
+							Field:    "providerSpec.placement.dedicatedHosts.minimumHoldDuration",
+							BadValue: "",
+							Detail:   "minimumHoldDuration requires allocate, only allocated hosts are released",
+						},
+					},
+				},
+			}),
+			Entry("Placement on allocated dedicated hosts with a minimum hold", &data{
+				setup: setup{
+					apply: func(spec *awsapi.AWSProviderSpec) {
+						spec.Placement = &awsapi.AWSPlacementSpec{
+							Tenancy: ptr.To("host"),
+							DedicatedHosts: &awsapi.AWSDedicatedHostsSpec{
+								Allocate:            true,
+								MinimumHoldDuration: ptr.To("24h"),
+							},
+						}
+					},
+				},
+				action: action{
+					spec:   validAWSProviderSpec(),
+					secret: providerSecret,
+				},
+				expect: expect{
+					errToHaveOccurred: false,
+				},
+			}),
+			Entry("efa with a single primary interface", &data{
+				setup: setup{
+					apply: func(spec *awsapi.AWSProviderSpec) {
+						spec.EFA = &awsapi.AWSEFASpec{InterfaceType: ptr.To("efa")}
+					},
+				},
+				action: action{
+					spec:   validAWSProviderSpec(),
+					secret: providerSecret,
+				},
+				expect: expect{
+					errToHaveOccurred: false,
+				},
+			}),
+			Entry("efa with invalid interfaceType and primary interface", &data{
+				setup: setup{
+					apply: func(spec *awsapi.AWSProviderSpec) {
+						spec.EFA = &awsapi.AWSEFASpec{InterfaceType: ptr.To("interface")}
+						spec.NetworkInterfaces[0].InterfaceType = ptr.To("efa-only")
+						spec.NetworkInterfaces[0].NetworkCardIndex = ptr.To[int32](1)
+					},
+				},
+				action: action{
+					spec:   validAWSProviderSpec(),
+					secret: providerSecret,
+				},
+				expect: expect{
+					errToHaveOccurred: true,
+					errList: field.ErrorList{
+						{
+							Type:     "FieldValueNotSupported",
+							Field:    "providerSpec.efa.interfaceType",
+							BadValue: "interface",
+							Detail:   `supported values: "efa-only", "efa"`,
+						},
+						{
+							Type:     "FieldValueInvalid",
+							Field:    "providerSpec.networkInterfaces[0].interfaceType",
+							BadValue: "efa-only",
+							Detail:   `the primary interface has type "efa" if efa is set`,
+						},
+						{
+							Type:     "FieldValueInvalid",
+							Field:    "providerSpec.networkInterfaces[0].networkCardIndex",
+							BadValue: int32(1),
+							Detail:   "the primary interface is attached to network card 0 if efa is set",
+						},
+					},
+				},
+			}),
+			Entry("efa with multiple network interfaces", &data{
+				setup: setup{
+					apply: func(spec *awsapi.AWSProviderSpec) {
+						spec.EFA = &awsapi.AWSEFASpec{}
+						spec.NetworkInterfaces = append(spec.NetworkInterfaces, spec.NetworkInterfaces[0])
+					},
+				},
+				action: action{
+					spec:   validAWSProviderSpec(),
+					secret: providerSecret,
+				},
+				expect: expect{
+					errToHaveOccurred: true,
+					errList: field.ErrorList{
+						{
+							Type:     "FieldValueInvalid",
+							Field:    "providerSpec.networkInterfaces",
+							BadValue: 2,
+							Detail:   "exactly one network interface is required if efa is set, the interfaces of further network cards are added automatically",
+						},
+					},
+				},
+			}),
 		)
 	})
 
@@ -2009,6 +2608,33 @@ var _ = Describe("Validation", func() {
 		})
 	})
 
+	Describe("#ValidateDeprecatedFields", func() {
+		It("should reject a spotPrice conflicting with instanceMarketOptions", func() {
+			spec := validAWSProviderSpec()
+			spec.SpotPrice = ptr.To("0.5")
+			spec.InstanceMarketOptions = &awsapi.AWSInstanceMarketOptions{MarketType: "spot"}
+
+			Expect(ValidateAWSProviderSpec(spec, providerSecret, field.NewPath("providerSpec"))).To(BeEmpty())
+			Expect(ValidateDeprecatedFields(spec, field.NewPath("providerSpec"))).To(Equal(field.ErrorList{{
+				Type:     field.ErrorTypeInvalid,
+				Field:    "providerSpec.spotPrice",
+				BadValue: "0.5",
+				Detail:   "conflicts with instanceMarketOptions, remove the deprecated spotPrice and use instanceMarketOptions.spotOptions.maxPrice instead",
+			}}))
+		})
+
+		It("should accept a spotPrice matching instanceMarketOptions", func() {
+			spec := validAWSProviderSpec()
+			spec.SpotPrice = ptr.To("0.5")
+			spec.InstanceMarketOptions = &awsapi.AWSInstanceMarketOptions{
+				MarketType:  "spot",
+				SpotOptions: &awsapi.AWSSpotMarketOptions{MaxPrice: ptr.To("0.5")},
+			}
+
+			Expect(ValidateDeprecatedFields(spec, field.NewPath("providerSpec"))).To(BeEmpty())
+		})
+	})
+
 	var _ = Describe("ValidateCPUOptions", func() {
 		Entry("nil cpuOptions should be valid", func() {
 			spec := validAWSProviderSpec()
@@ -0,0 +1,127 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package aws
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/gardener/machine-controller-manager/pkg/util/provider/machinecodes/codes"
+	"github.com/gardener/machine-controller-manager/pkg/util/provider/machinecodes/status"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
+
+	api "github.com/gardener/machine-controller-manager-provider-aws/pkg/aws/apis"
+	awserror "github.com/gardener/machine-controller-manager-provider-aws/pkg/aws/errors"
+	"github.com/gardener/machine-controller-manager-provider-aws/pkg/aws/interfaces"
+	"github.com/gardener/machine-controller-manager-provider-aws/pkg/instrument"
+)
+
+const (
+	capacityReservationGetServiceLabel = "capacity_reservation_get"
+
+	// capacityBlockEndingThreshold is the time before the end of a Capacity Block from which GetMachineStatus warns
+	// about its machines. EC2 starts terminating the instances of a Capacity Block 30 minutes before its end.
+	capacityBlockEndingThreshold = time.Hour
+)
+
+// validateCapacityReservation refuses to launch into the targeted capacity reservation or Capacity Block if it cannot
+// take the instance, as RunInstances only fails with a generic error then. Reservation groups cannot be described and
+// are not validated.
+func validateCapacityReservation(ctx context.Context, svc interfaces.Ec2Client, providerSpec *api.AWSProviderSpec) error {
+	target := providerSpec.CapacityReservationTarget
+	if target == nil || target.CapacityReservationID == nil {
+		return nil
+	}
+
+	reservationID := *target.CapacityReservationID
+	reservation, err := describeCapacityReservation(ctx, svc, reservationID)
+	if err != nil {
+		return err
+	}
+
+	if instanceType := ptr.Deref(reservation.InstanceType, ""); instanceType != "" && instanceType != providerSpec.MachineType {
+		return status.Error(codes.InvalidArgument, fmt.Sprintf("capacity reservation %s is for instance type %s, not %s", reservationID, instanceType, providerSpec.MachineType))
+	}
+
+	switch reservation.State {
+	case ec2types.CapacityReservationStateActive:
+	case ec2types.CapacityReservationStateScheduled,
+		ec2types.CapacityReservationStatePending,
+		ec2types.CapacityReservationStatePaymentPending,
+		ec2types.CapacityReservationStateAssessing,
+		ec2types.CapacityReservationStateDelayed:
+		msg := fmt.Sprintf("capacity reservation %s is %s and not active yet", reservationID, reservation.State)
+		if reservation.StartDate != nil {
+			msg += fmt.Sprintf(", it starts at %s", reservation.StartDate.Format(time.RFC3339))
+		}
+		return status.Error(codes.ResourceExhausted, msg)
+	default:
+		return status.Error(codes.ResourceExhausted, fmt.Sprintf("capacity reservation %s is %s", reservationID, reservation.State))
+	}
+
+	if reservation.EndDate != nil && !time.Now().Before(*reservation.EndDate) {
+		return status.Error(codes.ResourceExhausted, fmt.Sprintf("capacity reservation %s ended at %s", reservationID, reservation.EndDate.Format(time.RFC3339)))
+	}
+	if ptr.Deref(reservation.AvailableInstanceCount, 0) < 1 {
+		return status.Error(codes.ResourceExhausted, fmt.Sprintf("capacity reservation %s has no available capacity, all %d instances are in use",
+			reservationID, ptr.Deref(reservation.TotalInstanceCount, 0)))
+	}
+	return nil
+}
+
+// capacityBlockEndingMessage describes the end of the Capacity Block of the instance, if the block ends within the
+// capacityBlockEndingThreshold, and returns an empty string otherwise.
+func capacityBlockEndingMessage(ctx context.Context, svc interfaces.Ec2Client, instance *ec2types.Instance) (string, error) {
+	if instance.InstanceLifecycle != ec2types.InstanceLifecycleTypeCapacityBlock || instance.CapacityReservationId == nil {
+		return "", nil
+	}
+
+	reservation, err := describeCapacityReservation(ctx, svc, *instance.CapacityReservationId)
+	if err != nil {
+		return "", err
+	}
+	if reservation.EndDate == nil || time.Until(*reservation.EndDate) > capacityBlockEndingThreshold {
+		return "", nil
+	}
+	return fmt.Sprintf("runs in Capacity Block %s which ends at %s", *instance.CapacityReservationId, reservation.EndDate.Format(time.RFC3339)), nil
+}
+
+// describeCapacityReservation returns the capacity reservation or Capacity Block and records its remaining capacity.
+// The recorded metrics are deleted once the reservation does not exist anymore or has ended.
+func describeCapacityReservation(ctx context.Context, svc interfaces.Ec2Client, reservationID string) (reservation *ec2types.CapacityReservation, err error) {
+	defer instrument.AwsAPIMetricRecorderFn(capacityReservationGetServiceLabel, &err)()
+
+	output, err := svc.DescribeCapacityReservations(ctx, &ec2.DescribeCapacityReservationsInput{
+		CapacityReservationIds: []string{reservationID},
+	})
+	if awserror.HasErrorCode(err, awserror.InvalidCapacityReservationIDNotFound) {
+		instrument.DeleteCapacityReservation(reservationID)
+		return nil, status.Error(codes.InvalidArgument, fmt.Sprintf("capacity reservation %s does not exist", reservationID))
+	} else if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	if len(output.CapacityReservations) == 0 {
+		instrument.DeleteCapacityReservation(reservationID)
+		return nil, status.Error(codes.InvalidArgument, fmt.Sprintf("capacity reservation %s does not exist", reservationID))
+	}
+
+	reservation = &output.CapacityReservations[0]
+	switch reservation.State {
+	case ec2types.CapacityReservationStateExpired,
+		ec2types.CapacityReservationStateCancelled,
+		ec2types.CapacityReservationStateFailed,
+		ec2types.CapacityReservationStatePaymentFailed,
+		ec2types.CapacityReservationStateUnsupported:
+		instrument.DeleteCapacityReservation(reservationID)
+	default:
+		instrument.RecordCapacityReservation(reservationID, ptr.Deref(reservation.InstanceType, ""), ptr.Deref(reservation.AvailableInstanceCount, 0), reservation.EndDate)
+	}
+	klog.V(3).Infof("Capacity reservation %s is %s with %d available instances", reservationID, reservation.State, ptr.Deref(reservation.AvailableInstanceCount, 0))
+	return reservation, nil
+}
@@ -0,0 +1,158 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package aws
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/gardener/machine-controller-manager/pkg/util/provider/driver"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+
+	"github.com/gardener/machine-controller-manager-provider-aws/pkg/instrument"
+	"github.com/gardener/machine-controller-manager-provider-aws/pkg/mockclient"
+)
+
+var _ = Describe("CapacityReservation", func() {
+	const providerSpec = `{"ami":"ami-123456789","blockDevices":[{"ebs":{"volumeSize":50,"volumeType":"gp2"}}],"capacityReservation":{"capacityReservationId":"cr-0123456789"},"iam":{"name":"test-iam"},"instanceMarketOptions":{"marketType":"capacity-block"},"machineType":"p5.48xlarge","networkInterfaces":[{"securityGroupIDs":["sg-1"],"subnetID":"subnet-1"}],"region":"eu-west-1","tags":{"kubernetes.io/cluster/shoot--test":"1","kubernetes.io/role/test":"1"}}`
+	providerSecret := &corev1.Secret{
+		Data: map[string][]byte{
+			"providerAccessKeyId":     []byte("dummy-id"),
+			"providerSecretAccessKey": []byte("dummy-secret"),
+			"userData":                []byte("dummy-user-data"),
+		},
+	}
+
+	var (
+		mockClientProvider *mockclient.MockClientProvider
+		reservation        *ec2types.CapacityReservation
+	)
+
+	BeforeEach(func() {
+		mockClientProvider = &mockclient.MockClientProvider{FakeInstances: make([]ec2types.Instance, 0)}
+		reservation = &ec2types.CapacityReservation{
+			CapacityReservationId:  ptr.To("cr-0123456789"),
+			InstanceType:           ptr.To("p5.48xlarge"),
+			State:                  ec2types.CapacityReservationStateActive,
+			ReservationType:        ec2types.CapacityReservationTypeCapacityBlock,
+			TotalInstanceCount:     ptr.To[int32](2),
+			AvailableInstanceCount: ptr.To[int32](1),
+			EndDate:                ptr.To(time.Now().Add(48 * time.Hour).Truncate(time.Second)),
+		}
+	})
+
+	createMachineRequest := func() *driver.CreateMachineRequest {
+		return &driver.CreateMachineRequest{
+			Machine:      newMachine(0, nil),
+			MachineClass: newMachineClass([]byte(providerSpec)),
+			Secret:       providerSecret,
+		}
+	}
+
+	createMachine := func() error {
+		mockClientProvider.FakeCapacityReservations = []ec2types.CapacityReservation{*reservation}
+		_, err := NewAWSDriver(mockClientProvider).CreateMachine(context.Background(), createMachineRequest())
+		return err
+	}
+
+	It("should launch into an active Capacity Block and record its remaining capacity", func() {
+		Expect(createMachine()).To(Succeed())
+		Expect(mockClientProvider.FakeInstances).To(HaveLen(1))
+		Expect(mockClientProvider.FakeInstances[0].CapacityReservationId).To(Equal(ptr.To("cr-0123456789")))
+
+		Expect(testutil.ToFloat64(instrument.CapacityReservationAvailableInstances.WithLabelValues("aws", "cr-0123456789", "p5.48xlarge"))).To(Equal(float64(1)))
+		Expect(testutil.ToFloat64(instrument.CapacityReservationEndTimestamp.WithLabelValues("aws", "cr-0123456789", "p5.48xlarge"))).To(Equal(float64(reservation.EndDate.Unix())))
+	})
+
+	It("should refuse to launch into an exhausted Capacity Block", func() {
+		reservation.AvailableInstanceCount = ptr.To[int32](0)
+
+		err := createMachine()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("code = [ResourceExhausted]"))
+		Expect(err.Error()).To(ContainSubstring("capacity reservation cr-0123456789 has no available capacity, all 2 instances are in use"))
+		Expect(mockClientProvider.FakeInstances).To(BeEmpty())
+	})
+
+	It("should refuse to launch into an expired or scheduled Capacity Block", func() {
+		reservation.State = ec2types.CapacityReservationStateExpired
+		err := createMachine()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("code = [ResourceExhausted]"))
+		Expect(err.Error()).To(ContainSubstring("capacity reservation cr-0123456789 is expired"))
+
+		reservation.State = ec2types.CapacityReservationStateScheduled
+		reservation.StartDate = ptr.To(time.Date(2030, 1, 1, 11, 30, 0, 0, time.UTC))
+		err = createMachine()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("code = [ResourceExhausted]"))
+		Expect(err.Error()).To(ContainSubstring("capacity reservation cr-0123456789 is scheduled and not active yet, it starts at 2030-01-01T11:30:00Z"))
+		Expect(mockClientProvider.FakeInstances).To(BeEmpty())
+	})
+
+	It("should fail with InvalidArgument if the reservation does not exist or is for another instance type", func() {
+		reservation.InstanceType = ptr.To("p4d.24xlarge")
+		err := createMachine()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("code = [InvalidArgument]"))
+		Expect(err.Error()).To(ContainSubstring("capacity reservation cr-0123456789 is for instance type p4d.24xlarge, not p5.48xlarge"))
+
+		reservation.CapacityReservationId = ptr.To("cr-other")
+		err = createMachine()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("code = [InvalidArgument]"))
+		Expect(err.Error()).To(ContainSubstring("capacity reservation cr-0123456789 does not exist"))
+	})
+
+	It("should flag machines in a Capacity Block nearing its end", func() {
+		Expect(createMachine()).To(Succeed())
+		d := NewAWSDriver(mockClientProvider)
+		request := &driver.GetMachineStatusRequest{Machine: newMachine(0, nil), MachineClass: newMachineClass([]byte(providerSpec)), Secret: providerSecret}
+
+		_, err := d.GetMachineStatus(context.Background(), request)
+		Expect(err).ToNot(HaveOccurred())
+
+		endDate := time.Now().Add(45 * time.Minute).Truncate(time.Second)
+		mockClientProvider.FakeCapacityReservations[0].EndDate = &endDate
+		d = NewAWSDriver(mockClientProvider)
+		response, err := d.GetMachineStatus(context.Background(), request)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("code = [Uninitialized]"))
+		Expect(err.Error()).To(ContainSubstring(fmt.Sprintf(`VM "i-0123456789-0" associated with machine "machine-0" runs in Capacity Block cr-0123456789 which ends at %s`, endDate.Format(time.RFC3339))))
+		Expect(response.ProviderID).To(Equal("aws:///eu-west-1/i-0123456789-0"))
+		Expect(testutil.ToFloat64(instrument.CapacityReservationEndTimestamp.WithLabelValues("aws", "cr-0123456789", "p5.48xlarge"))).To(Equal(float64(endDate.Unix())))
+	})
+
+	It("should delete the metrics of ended or deleted reservations", func() {
+		instrument.CapacityReservationAvailableInstances.Reset()
+		instrument.CapacityReservationEndTimestamp.Reset()
+		Expect(createMachine()).To(Succeed())
+		d := NewAWSDriver(mockClientProvider)
+		request := &driver.GetMachineStatusRequest{Machine: newMachine(0, nil), MachineClass: newMachineClass([]byte(providerSpec)), Secret: providerSecret}
+		Expect(testutil.CollectAndCount(instrument.CapacityReservationAvailableInstances, "mcm_cloud_api_capacity_reservation_available_instances")).To(Equal(1))
+
+		mockClientProvider.FakeCapacityReservations[0].State = ec2types.CapacityReservationStateExpired
+		_, err := d.GetMachineStatus(context.Background(), request)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(testutil.CollectAndCount(instrument.CapacityReservationAvailableInstances, "mcm_cloud_api_capacity_reservation_available_instances")).To(Equal(0))
+		Expect(testutil.CollectAndCount(instrument.CapacityReservationEndTimestamp, "mcm_cloud_api_capacity_reservation_end_timestamp_seconds")).To(Equal(0))
+
+		mockClientProvider.FakeCapacityReservations[0].State = ec2types.CapacityReservationStateActive
+		_, err = d.GetMachineStatus(context.Background(), request)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(testutil.CollectAndCount(instrument.CapacityReservationAvailableInstances, "mcm_cloud_api_capacity_reservation_available_instances")).To(Equal(1))
+
+		mockClientProvider.FakeCapacityReservations = nil
+		_, err = d.GetMachineStatus(context.Background(), request)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(testutil.CollectAndCount(instrument.CapacityReservationAvailableInstances, "mcm_cloud_api_capacity_reservation_available_instances")).To(Equal(0))
+	})
+})
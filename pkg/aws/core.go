@@ -10,6 +10,7 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
@@ -22,10 +23,14 @@ import (
 	"github.com/gardener/machine-controller-manager/pkg/util/provider/driver"
 	"github.com/gardener/machine-controller-manager/pkg/util/provider/machinecodes/codes"
 	"github.com/gardener/machine-controller-manager/pkg/util/provider/machinecodes/status"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/klog/v2"
 	"k8s.io/utils/ptr"
 
+	api "github.com/gardener/machine-controller-manager-provider-aws/pkg/aws/apis"
+	"github.com/gardener/machine-controller-manager-provider-aws/pkg/aws/apis/validation"
 	awserror "github.com/gardener/machine-controller-manager-provider-aws/pkg/aws/errors"
+	"github.com/gardener/machine-controller-manager-provider-aws/pkg/aws/instancetypes"
 	"github.com/gardener/machine-controller-manager-provider-aws/pkg/cpi"
 )
 
@@ -40,7 +45,25 @@ const (
 
 // Driver is the driver struct for holding AWS machine information
 type Driver struct {
-	CPI cpi.ClientProviderInterface
+	CPI     cpi.ClientProviderInterface
+	Options Options
+
+	// preflightResults caches successful pre-flight validations, see Options.PreflightValidation
+	preflightResults *preflightCache
+	// instanceTypes caches the capabilities of the instance types the machines are created with
+	instanceTypes *instancetypes.Catalog
+}
+
+// Options configures optional behaviour of the Driver.
+type Options struct {
+	// AllowUnknownProviderSpecFields accepts providerSpecs with fields unknown to the driver when creating machines and
+	// only logs a warning for them, like the driver did before strict decoding was introduced. All other calls always
+	// accept them.
+	AllowUnknownProviderSpecFields bool
+	// PreflightValidation verifies the cloud resources referenced by a MachineClass before its first machine is
+	// created, e.g. that the subnets are in the VPC of the security groups or that the IAM instance profile exists.
+	// It uses describe calls and a RunInstances dry run, successful results are cached per MachineClass hash.
+	PreflightValidation bool
 }
 
 const (
@@ -51,15 +74,28 @@ const (
 	resourceTypeNetworkInterface = "network-interface"
 	// awsEBSDriverName is the name of the CSI driver for EBS
 	awsEBSDriverName = "ebs.csi.aws.com"
-	awsPlacement     = "machine.sapcloud.io/awsPlacement"
+	// awsPlacement is the deprecated node template annotation for the placement, see awsPlacementAnnotation
+	awsPlacement = "machine.sapcloud.io/awsPlacement"
+	// machineClassTagKey is the tag key of the MachineClass name on instances whose partitions are spread
+	machineClassTagKey = "machine.sapcloud.io/machine-class"
+	// placementPartitionTagKey is the tag key of the partition chosen for instances whose partitions are spread
+	placementPartitionTagKey = "machine.sapcloud.io/placement-partition"
 )
 
 var maxElapsedTimeInBackoff = 5 * time.Minute
 
 // NewAWSDriver returns an empty AWSDriver object
 func NewAWSDriver(cpi cpi.ClientProviderInterface) driver.Driver {
+	return NewAWSDriverWithOptions(cpi, Options{})
+}
+
+// NewAWSDriverWithOptions returns an AWSDriver object configured by the given options
+func NewAWSDriverWithOptions(cpi cpi.ClientProviderInterface, opts Options) driver.Driver {
 	return &Driver{
-		CPI: cpi,
+		CPI:              cpi,
+		Options:          opts,
+		preflightResults: newPreflightCache(),
+		instanceTypes:    instancetypes.NewCatalog(instancetypes.DefaultTTL),
 	}
 }
 
@@ -84,7 +120,7 @@ func (d *Driver) CreateMachine(ctx context.Context, req *driver.CreateMachineReq
 	// Log messages to track request
 	klog.V(3).Infof("Machine creation request has been received for %q", req.Machine.Name)
 
-	providerSpec, err := decodeProviderSpecAndSecret(machineClass, secret)
+	providerSpec, err := d.decodeProviderSpecAndSecret(machineClass, secret, true)
 	if err != nil {
 		return nil, err
 	}
@@ -94,11 +130,40 @@ func (d *Driver) CreateMachine(ctx context.Context, req *driver.CreateMachineReq
 		return nil, status.Error(awserror.GetMCMErrorCodeForCreateMachine(err), err.Error())
 	}
 
+	// Validate the providerSpec against the capabilities of the machine type, which the static validation cannot know
+	info := d.instanceTypes.Get(ctx, client, providerSpec.Region, providerSpec.MachineType)
+	if providerSpec.EFA != nil {
+		networkInterfaces, err := expandEFANetworkInterfaces(providerSpec, info)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		providerSpec.NetworkInterfaces = networkInterfaces
+	}
+	if info != nil {
+		if errs := instancetypes.ValidateProviderSpec(info, providerSpec, field.NewPath("providerSpec")); len(errs) > 0 {
+			err = fmt.Errorf("error while validating ProviderSpec against instance type %s %v", providerSpec.MachineType, errs.ToAggregate().Error())
+			klog.V(2).Infof("Validation of AWSMachineClass %q failed %s", machineClass.Name, err)
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+
 	if userData, exists = secret.Data["userData"]; !exists {
 		return nil, status.Error(codes.Internal, "userData doesn't exist")
 	}
 	UserDataEnc := base64.StdEncoding.EncodeToString(userData)
 
+	// The pre-flight validation is finished by a dry run of the final RunInstances request below
+	var pendingPreflightHash string
+	if d.Options.PreflightValidation {
+		if hash := preflightHash(machineClass, secret); !d.preflightResults.valid(hash) {
+			if err := validateCloudReferences(ctx, client, providerSpec); err != nil {
+				klog.V(2).Infof("Pre-flight validation of MachineClass %q failed: %v", machineClass.Name, err)
+				return nil, err
+			}
+			pendingPreflightHash = hash
+		}
+	}
+
 	var imageIds []string
 	imageID := providerSpec.AMI
 	imageIds = append(imageIds, imageID)
@@ -113,6 +178,11 @@ func (d *Driver) CreateMachine(ctx context.Context, req *driver.CreateMachineReq
 		return nil, status.Error(codes.NotFound, fmt.Sprintf("Image %s not found", imageID))
 	}
 
+	if err := validateImageBootOptions(&output.Images[0], providerSpec); err != nil {
+		klog.V(2).Infof("Validation of AWSMachineClass %q against AMI %s failed %s", machineClass.Name, imageID, err)
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
 	blkDeviceMappings, err := d.generateBlockDevices(providerSpec.BlockDevices, output.Images[0].RootDeviceName)
 	if err != nil {
 		return nil, status.Error(codes.InvalidArgument, err.Error())
@@ -133,21 +203,38 @@ func (d *Driver) CreateMachine(ctx context.Context, req *driver.CreateMachineReq
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
+	if err = resolveNetworkInterfaceTags(ctx, client, providerSpec.NetworkInterfaces); err != nil {
+		return nil, err
+	}
+
 	var networkInterfaceSpecs []ec2types.InstanceNetworkInterfaceSpecification
+	createsNetworkInterfaces := false
 
 	for i, netIf := range providerSpec.NetworkInterfaces {
+		deviceIndex := netIf.DeviceIndex
+		if deviceIndex == nil {
+			deviceIndex = aws.Int32(int32(i)) // #nosec: G115 -- index will not exceed int32 limits
+		}
+
+		if netIf.NetworkInterfaceID != nil {
+			// Existing network interfaces are detached on termination, so that they can be reused by the next machine
+			networkInterfaceSpecs = append(networkInterfaceSpecs, ec2types.InstanceNetworkInterfaceSpecification{
+				NetworkInterfaceId:  netIf.NetworkInterfaceID,
+				DeviceIndex:         deviceIndex,
+				NetworkCardIndex:    netIf.NetworkCardIndex,
+				DeleteOnTermination: aws.Bool(false),
+			})
+			continue
+		}
+		createsNetworkInterfaces = true
+
 		spec := ec2types.InstanceNetworkInterfaceSpecification{
 			Groups:                   netIf.SecurityGroupIDs,
 			AssociatePublicIpAddress: netIf.AssociatePublicIPAddress,
 			DeleteOnTermination:      netIf.DeleteOnTermination,
 			Description:              netIf.Description,
 			SubnetId:                 aws.String(netIf.SubnetID),
-		}
-
-		if netIf.DeviceIndex != nil {
-			spec.DeviceIndex = netIf.DeviceIndex
-		} else {
-			spec.DeviceIndex = aws.Int32(int32(i)) // #nosec: G115 -- index will not exceed int32 limits
+			DeviceIndex:              deviceIndex,
 		}
 
 		spec.NetworkCardIndex = netIf.NetworkCardIndex
@@ -157,7 +244,7 @@ func (d *Driver) CreateMachine(ctx context.Context, req *driver.CreateMachineReq
 			spec.DeleteOnTermination = aws.Bool(true)
 		}
 
-		if netIf.Ipv6AddressCount != nil {
+		if netIf.Ipv6AddressCount != nil || netIf.IPv6CIDR != nil {
 			spec.Ipv6AddressCount = netIf.Ipv6AddressCount
 			if netIf.PrimaryIpv6 != nil {
 				spec.PrimaryIpv6 = netIf.PrimaryIpv6
@@ -166,6 +253,9 @@ func (d *Driver) CreateMachine(ctx context.Context, req *driver.CreateMachineReq
 			}
 		}
 
+		spec.SecondaryPrivateIpAddressCount = netIf.SecondaryPrivateIPAddressCount
+		spec.Ipv4PrefixCount = netIf.Ipv4PrefixCount
+
 		networkInterfaceSpecs = append(networkInterfaceSpecs, spec)
 	}
 
@@ -196,9 +286,50 @@ func (d *Driver) CreateMachine(ctx context.Context, req *driver.CreateMachineReq
 		UserData:            &UserDataEnc,
 		IamInstanceProfile:  iam,
 		NetworkInterfaces:   networkInterfaceSpecs,
-		TagSpecifications:   []ec2types.TagSpecification{tagInstance, tagVolume, tagNetworkInterface},
+		TagSpecifications:   []ec2types.TagSpecification{tagInstance, tagVolume},
 		MetadataOptions:     metadataOptions,
 	}
+	if createsNetworkInterfaces {
+		inputConfig.TagSpecifications = append(inputConfig.TagSpecifications, tagNetworkInterface)
+	}
+
+	for _, licenseConfigurationARN := range providerSpec.LicenseConfigurationARNs {
+		inputConfig.LicenseSpecifications = append(inputConfig.LicenseSpecifications, ec2types.LicenseConfigurationRequest{
+			LicenseConfigurationArn: aws.String(licenseConfigurationARN),
+		})
+	}
+
+	if providerSpec.EnclaveOptions != nil {
+		inputConfig.EnclaveOptions = &ec2types.EnclaveOptionsRequest{
+			Enabled: aws.Bool(providerSpec.EnclaveOptions.Enabled),
+		}
+	}
+
+	if providerSpec.HibernationOptions != nil {
+		inputConfig.HibernationOptions = &ec2types.HibernationOptionsRequest{
+			Configured: aws.Bool(providerSpec.HibernationOptions.Configured),
+		}
+	}
+
+	if maintenanceOptions := providerSpec.MaintenanceOptions; maintenanceOptions != nil && maintenanceOptions.AutoRecovery != nil {
+		inputConfig.MaintenanceOptions = &ec2types.InstanceMaintenanceOptionsRequest{
+			AutoRecovery: ec2types.InstanceAutoRecoveryState(*maintenanceOptions.AutoRecovery),
+		}
+	}
+
+	if providerSpec.CreditSpecification != nil {
+		inputConfig.CreditSpecification = &ec2types.CreditSpecificationRequest{
+			CpuCredits: aws.String(providerSpec.CreditSpecification.CPUCredits),
+		}
+	}
+
+	if dnsOptions := providerSpec.PrivateDNSNameOptions; dnsOptions != nil {
+		inputConfig.PrivateDnsNameOptions = &ec2types.PrivateDnsNameOptionsRequest{
+			HostnameType:                    ec2types.HostnameType(ptr.Deref(dnsOptions.HostnameType, "")),
+			EnableResourceNameDnsARecord:    dnsOptions.EnableResourceNameDNSARecord,
+			EnableResourceNameDnsAAAARecord: dnsOptions.EnableResourceNameDNSAAAARecord,
+		}
+	}
 
 	if ptr.Deref(providerSpec.KeyName, "") != "" {
 		inputConfig.KeyName = aws.String(*providerSpec.KeyName)
@@ -219,6 +350,10 @@ func (d *Driver) CreateMachine(ctx context.Context, req *driver.CreateMachineReq
 	// Set the AWS Capacity Reservation target. Using an 'open' preference means that if the reservation is not found, then
 	// instances are launched with regular on-demand capacity.
 	if providerSpec.CapacityReservationTarget != nil {
+		if err := validateCapacityReservation(ctx, client, providerSpec); err != nil {
+			klog.V(2).Infof("Capacity reservation of AWSMachineClass %q cannot be used: %v", machineClass.Name, err)
+			return nil, err
+		}
 		inputConfig.CapacityReservationSpecification = &ec2types.CapacityReservationSpecification{
 			CapacityReservationPreference: ec2types.CapacityReservationPreference(providerSpec.CapacityReservationTarget.CapacityReservationPreference),
 			CapacityReservationTarget: &ec2types.CapacityReservationTarget{
@@ -233,6 +368,12 @@ func (d *Driver) CreateMachine(ctx context.Context, req *driver.CreateMachineReq
 		inputConfig.InstanceMarketOptions = &ec2types.InstanceMarketOptionsRequest{
 			MarketType: ec2types.MarketType(providerSpec.InstanceMarketOptions.MarketType),
 		}
+		if spotOptions := providerSpec.InstanceMarketOptions.SpotOptions; spotOptions != nil {
+			inputConfig.InstanceMarketOptions.SpotOptions = &ec2types.SpotMarketOptions{
+				SpotInstanceType: ec2types.SpotInstanceTypeOneTime,
+				MaxPrice:         spotOptions.MaxPrice,
+			}
+		}
 	} else if providerSpec.SpotPrice != nil {
 		// Backward compatibility for deprecated SpotPrice field
 		inputConfig.InstanceMarketOptions = &ec2types.InstanceMarketOptionsRequest{
@@ -246,29 +387,75 @@ func (d *Driver) CreateMachine(ctx context.Context, req *driver.CreateMachineReq
 		}
 	}
 
-	// Set placement from providerSpec (first-class API), falling back to annotation
+	// Set placement from providerSpec (first-class API), falling back to the deprecated annotation
+	annotationPlacement, err := getPlacementObj(req)
+	if err != nil {
+		return nil, err
+	}
+	deprecations.observe(featureAWSPlacementAnnotation, machineClass, annotationPlacement != nil)
+	// A Dedicated Host allocated for the machine is released again if the instance cannot be launched
+	var allocatedHostID string
+	releaseLaunchHost := func() {
+		if allocatedHostID != "" {
+			releaseAllocatedDedicatedHost(ctx, client, providerSpec, allocatedHostID)
+		}
+	}
 	if providerSpec.Placement != nil {
-		placement := &ec2types.Placement{}
-		if providerSpec.Placement.Tenancy != nil {
-			placement.Tenancy = ec2types.Tenancy(*providerSpec.Placement.Tenancy)
-		}
-		placement.GroupId = providerSpec.Placement.GroupID
-		placement.HostId = providerSpec.Placement.HostID
-		placement.PartitionNumber = providerSpec.Placement.PartitionNumber
-		placement.Affinity = providerSpec.Placement.Affinity
-		inputConfig.Placement = placement
-	} else {
-		placement, err := getPlacementObj(req)
-		if err != nil {
-			return nil, status.Error(codes.Internal, err.Error())
-		} else if placement != nil {
-			inputConfig.Placement = placement
+		if conflicts := placementConflicts(providerSpec.Placement, annotationPlacement); len(conflicts) > 0 {
+			return nil, status.Error(codes.InvalidArgument, fmt.Sprintf("the deprecated %s annotation conflicts with providerSpec.placement in %v, remove the annotation", awsPlacement, conflicts))
+		}
+		inputConfig.Placement = placementFromSpec(providerSpec.Placement)
+		if name := providerSpec.Placement.HostResourceGroupName; name != nil {
+			stsClient, err := d.createSTSClient(ctx, secret, providerSpec.Region)
+			if err != nil {
+				return nil, status.Error(codes.Internal, err.Error())
+			}
+			groupARN, err := hostResourceGroupARN(ctx, stsClient, providerSpec.Region, *name)
+			if err != nil {
+				return nil, err
+			}
+			inputConfig.Placement.HostResourceGroupArn = aws.String(groupARN)
+		}
+		if providerSpec.Placement.DedicatedHosts != nil {
+			hostID, allocated, err := d.chooseDedicatedHost(ctx, client, providerSpec, machine.Name)
+			if err != nil {
+				return nil, err
+			}
+			inputConfig.Placement.HostId = aws.String(hostID)
+			if allocated {
+				allocatedHostID = hostID
+			}
+		}
+		if providerSpec.Placement.SpreadPartitions {
+			partition, err := choosePlacementPartition(ctx, client, providerSpec, machineClass.Name)
+			if err != nil {
+				releaseLaunchHost()
+				return nil, err
+			}
+			inputConfig.Placement.PartitionNumber = aws.Int32(partition)
+			// Record the MachineClass and the partition on the instance, so that the next machine is spread accordingly
+			inputConfig.TagSpecifications[0].Tags = append(inputConfig.TagSpecifications[0].Tags,
+				ec2types.Tag{Key: aws.String(machineClassTagKey), Value: aws.String(machineClass.Name)},
+				ec2types.Tag{Key: aws.String(placementPartitionTagKey), Value: aws.String(strconv.Itoa(int(partition)))},
+			)
+		}
+	} else if annotationPlacement != nil {
+		inputConfig.Placement = annotationPlacement
+	}
+
+	if pendingPreflightHash != "" {
+		if err := dryRunInstance(ctx, client, inputConfig); err != nil {
+			releaseLaunchHost()
+			return nil, err
 		}
+		d.preflightResults.add(pendingPreflightHash)
+		klog.V(3).Infof("Pre-flight validation of MachineClass %q succeeded", machineClass.Name)
 	}
 
-	runResult, err := client.RunInstances(ctx, inputConfig)
+	runResult, err := runInstances(ctx, client, providerSpec.NetworkInterfaces, inputConfig)
 	if err != nil {
-		return nil, status.Error(awserror.GetMCMErrorCodeForCreateMachine(err), err.Error())
+		releaseLaunchHost()
+		return nil, err
 	}
 
 	var instanceID, providerID, nodeName string
@@ -276,7 +463,7 @@ func (d *Driver) CreateMachine(ctx context.Context, req *driver.CreateMachineReq
 		if instance.InstanceId != nil {
 			instanceID = *instance.InstanceId
 			providerID = encodeInstanceID(providerSpec.Region, instanceID)
-			nodeName = ptr.Deref(instance.PrivateDnsName, "")
+			nodeName = instanceNodeName(&instance, providerSpec.Region, providerSpec.PrivateDNSNameOptions)
 			break
 		}
 	}
@@ -308,7 +495,9 @@ func (d *Driver) CreateMachine(ctx context.Context, req *driver.CreateMachineReq
 		return nil, status.Error(codes.Internal, fmt.Sprintf("creation of VM %q failed, timed out waiting for eventual consistency. Multiple VMs backing machine obj might spawn, they will be orphan collected", providerID))
 	}
 
-	nodeName = ptr.Deref(instance.PrivateDnsName, nodeName)
+	if name := instanceNodeName(instance, providerSpec.Region, providerSpec.PrivateDNSNameOptions); name != "" {
+		nodeName = name
+	}
 	if nodeName == "" {
 		msg := fmt.Sprintf("VM with Provider-ID %q, for machine %q does not yet have a nodeName (instance.PrivateDnsName)", providerID, machine.Name)
 		klog.Error(msg)
@@ -331,7 +520,7 @@ func (d *Driver) CreateMachine(ctx context.Context, req *driver.CreateMachineReq
 func (d *Driver) InitializeMachine(ctx context.Context, request *driver.InitializeMachineRequest) (resp *driver.InitializeMachineResponse, err error) {
 	defer instrument.DriverAPIMetricRecorderFn(initializeMachineOperationLabel, &err)()
 
-	providerSpec, err := decodeProviderSpecAndSecret(request.MachineClass, request.Secret)
+	providerSpec, err := d.decodeProviderSpecAndSecret(request.MachineClass, request.Secret, false)
 	if err != nil {
 		return nil, err
 	}
@@ -383,31 +572,164 @@ func (d *Driver) InitializeMachine(ctx context.Context, request *driver.Initiali
 				return nil, status.Error(codes.Uninitialized, err.Error())
 			}
 		}
+		if secondaryIPs := countSecondaryPrivateIPAddresses(instanceNetIf); netIf.SecondaryPrivateIPAddressCount != nil && secondaryIPs < *netIf.SecondaryPrivateIPAddressCount {
+			input := &ec2.AssignPrivateIpAddressesInput{
+				NetworkInterfaceId:             instanceNetIf.NetworkInterfaceId,
+				SecondaryPrivateIpAddressCount: ptr.To(*netIf.SecondaryPrivateIPAddressCount - secondaryIPs),
+			}
+			klog.V(3).Infof("On VM %q associated with machine %s, assigning secondaryPrivateIPAddressCount: %d to networkInterface %q",
+				providerID, request.Machine.Name, *netIf.SecondaryPrivateIPAddressCount, ptr.Deref(instanceNetIf.NetworkInterfaceId, ""))
+			_, err = client.AssignPrivateIpAddresses(ctx, input)
+			if err != nil {
+				return nil, status.Error(codes.Uninitialized, err.Error())
+			}
+		}
+		// #nosec: G115 -- index will not exceed int32 limits
+		if netIf.Ipv4PrefixCount != nil && int32(len(instanceNetIf.Ipv4Prefixes)) < *netIf.Ipv4PrefixCount {
+			input := &ec2.AssignPrivateIpAddressesInput{
+				NetworkInterfaceId: instanceNetIf.NetworkInterfaceId,
+				Ipv4PrefixCount:    ptr.To(*netIf.Ipv4PrefixCount - int32(len(instanceNetIf.Ipv4Prefixes))),
+			}
+			klog.V(3).Infof("On VM %q associated with machine %s, assigning ipv4PrefixCount: %d to networkInterface %q",
+				providerID, request.Machine.Name, *netIf.Ipv4PrefixCount, ptr.Deref(instanceNetIf.NetworkInterfaceId, ""))
+			_, err = client.AssignPrivateIpAddresses(ctx, input)
+			if err != nil {
+				return nil, status.Error(codes.Uninitialized, err.Error())
+			}
+		}
+	}
+
+	if rebootMigrationDrifted(&targetInstance, providerSpec.MaintenanceOptions) {
+		klog.V(3).Infof("On VM %q associated with machine %s, setting rebootMigration to %q",
+			providerID, request.Machine.Name, *providerSpec.MaintenanceOptions.RebootMigration)
+		if err = modifyInstanceRebootMigration(ctx, client, ptr.Deref(targetInstance.InstanceId, ""), *providerSpec.MaintenanceOptions.RebootMigration); err != nil {
+			return nil, status.Error(codes.Uninitialized, err.Error())
+		}
+	}
+
+	if providerSpec.CreditSpecification != nil {
+		cpuCredits, err := getInstanceCPUCredits(ctx, client, ptr.Deref(targetInstance.InstanceId, ""))
+		if err != nil {
+			return nil, status.Error(codes.Uninitialized, err.Error())
+		}
+		if cpuCredits != providerSpec.CreditSpecification.CPUCredits {
+			klog.V(3).Infof("On VM %q associated with machine %s, setting cpuCredits from %q to %q",
+				providerID, request.Machine.Name, cpuCredits, providerSpec.CreditSpecification.CPUCredits)
+			if err = modifyInstanceCPUCredits(ctx, client, ptr.Deref(targetInstance.InstanceId, ""), providerSpec.CreditSpecification.CPUCredits); err != nil {
+				return nil, status.Error(codes.Uninitialized, err.Error())
+			}
+		}
+	}
+
+	if providerSpec.ElasticIP != nil {
+		if err = d.associateElasticIP(ctx, client, providerSpec.ElasticIP, providerSpec.Tags, request.Machine.Name, targetInstance); err != nil {
+			klog.Errorf("could not associate Elastic IP with VM %q of machine %q: %v", providerID, request.Machine.Name, err)
+			return nil, status.Error(codes.Uninitialized, err.Error())
+		}
 	}
 
 	return &driver.InitializeMachineResponse{
 		ProviderID: providerID,
-		NodeName:   ptr.Deref(targetInstance.PrivateDnsName, ""),
+		NodeName:   instanceNodeName(&targetInstance, providerSpec.Region, providerSpec.PrivateDNSNameOptions),
 	}, nil
 }
 
-// returns Placement Object required in ec2.RunInstancesInput
-func getPlacementObj(req *driver.CreateMachineRequest) (placementobj *ec2types.Placement, err error) {
-	placementobj = &ec2types.Placement{}
+// awsPlacementAnnotation is the value of the deprecated awsPlacement annotation. It is a JSON object with the
+// following keys, which are matched case-insensitively:
+//   - affinity: "default" or "host"
+//   - availabilityZone: the availability zone of the instance
+//   - groupId: the ID of the placement group
+//   - groupName: the name of the placement group
+//   - hostId: the ID of the Dedicated Host, requires tenancy "host"
+//   - partitionNumber: the partition in a partition placement group, must be >= 1
+//   - tenancy: "default", "dedicated" or "host"
+//
+// Other keys are rejected. Except for availabilityZone, the keys correspond to providerSpec.placement, which should
+// be used instead. The availability zone is determined by the subnet anyway.
+type awsPlacementAnnotation struct {
+	api.AWSPlacementSpec
+	AvailabilityZone *string `json:"availabilityZone,omitempty"`
+}
 
-	requestAnnotations := req.Machine.Spec.NodeTemplateSpec.Annotations
+// returns Placement Object required in ec2.RunInstancesInput from the deprecated awsPlacement annotation. The
+// annotation is validated like providerSpec.placement, invalid annotations are reported as InvalidArgument.
+func getPlacementObj(req *driver.CreateMachineRequest) (*ec2types.Placement, error) {
+	placementAnnotation := req.Machine.Spec.NodeTemplateSpec.Annotations[awsPlacement]
+	if placementAnnotation == "" {
+		return nil, nil
+	}
 
-	if placementAnnotation, ok := requestAnnotations[awsPlacement]; ok && placementAnnotation != "" {
-		placementAnnotationsRaw := []byte(placementAnnotation)
-		if err = json.Unmarshal(placementAnnotationsRaw, placementobj); err != nil {
-			return nil, err
-		}
+	annotation := &awsPlacementAnnotation{}
+	decoder := json.NewDecoder(strings.NewReader(placementAnnotation))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(annotation); err != nil {
+		return nil, status.Error(codes.InvalidArgument, fmt.Sprintf("invalid %s annotation: %v", awsPlacement, err))
+	}
+	fldPath := field.NewPath("spec", "nodeTemplate", "metadata", "annotations").Key(awsPlacement)
+	if errs := validation.ValidatePlacement(&annotation.AWSPlacementSpec, fldPath); len(errs) > 0 {
+		return nil, status.Error(codes.InvalidArgument, fmt.Sprintf("invalid %s annotation: %v", awsPlacement, errs.ToAggregate()))
+	}
+
+	if annotation.HostResourceGroupARN != nil || annotation.HostResourceGroupName != nil {
+		return nil, status.Error(codes.InvalidArgument, fmt.Sprintf("invalid %s annotation: host resource groups are only supported in providerSpec.placement", awsPlacement))
+	}
+	if annotation.SpreadPartitions {
+		return nil, status.Error(codes.InvalidArgument, fmt.Sprintf("invalid %s annotation: spreadPartitions is only supported in providerSpec.placement", awsPlacement))
+	}
+	if annotation.DedicatedHosts != nil {
+		return nil, status.Error(codes.InvalidArgument, fmt.Sprintf("invalid %s annotation: dedicatedHosts is only supported in providerSpec.placement", awsPlacement))
 	}
 
-	if *placementobj == (ec2types.Placement{}) {
+	placement := placementFromSpec(&annotation.AWSPlacementSpec)
+	placement.AvailabilityZone = annotation.AvailabilityZone
+	if *placement == (ec2types.Placement{}) {
 		return nil, nil
 	}
-	return placementobj, nil
+	return placement, nil
+}
+
+// placementFromSpec converts the placement of the providerSpec into the placement of ec2.RunInstancesInput.
+func placementFromSpec(spec *api.AWSPlacementSpec) *ec2types.Placement {
+	placement := &ec2types.Placement{
+		GroupId:              spec.GroupID,
+		GroupName:            spec.GroupName,
+		HostId:               spec.HostID,
+		PartitionNumber:      spec.PartitionNumber,
+		Affinity:             spec.Affinity,
+		HostResourceGroupArn: spec.HostResourceGroupARN,
+	}
+	if spec.Tenancy != nil {
+		placement.Tenancy = ec2types.Tenancy(*spec.Tenancy)
+	}
+	return placement
+}
+
+// placementConflicts returns the keys of the placement annotation which differ from the placement of the providerSpec.
+// The availability zone is not part of the providerSpec placement, since it is determined by the subnet.
+func placementConflicts(spec *api.AWSPlacementSpec, annotation *ec2types.Placement) []string {
+	var conflicts []string
+	if annotation == nil {
+		return conflicts
+	}
+	if annotation.Tenancy != "" && string(annotation.Tenancy) != ptr.Deref(spec.Tenancy, "") {
+		conflicts = append(conflicts, "tenancy")
+	}
+	if annotation.Affinity != nil && *annotation.Affinity != ptr.Deref(spec.Affinity, "") {
+		conflicts = append(conflicts, "affinity")
+	}
+	if annotation.GroupId != nil && *annotation.GroupId != ptr.Deref(spec.GroupID, "") {
+		conflicts = append(conflicts, "groupId")
+	}
+	if annotation.GroupName != nil && *annotation.GroupName != ptr.Deref(spec.GroupName, "") {
+		conflicts = append(conflicts, "groupName")
+	}
+	if annotation.HostId != nil && *annotation.HostId != ptr.Deref(spec.HostID, "") {
+		conflicts = append(conflicts, "hostId")
+	}
+	if annotation.PartitionNumber != nil && *annotation.PartitionNumber != ptr.Deref(spec.PartitionNumber, 0) {
+		conflicts = append(conflicts, "partitionNumber")
+	}
+	return conflicts
 }
 
 // DeleteMachine handles a machine deletion request
@@ -430,7 +752,7 @@ func (d *Driver) DeleteMachine(ctx context.Context, req *driver.DeleteMachineReq
 	klog.V(3).Infof("Machine deletion request has been received for %q", req.Machine.Name)
 	defer klog.V(3).Infof("Machine deletion request has been processed for %q", req.Machine.Name)
 
-	providerSpec, err := decodeProviderSpecAndSecret(req.MachineClass, secret)
+	providerSpec, err := d.decodeProviderSpecAndSecret(req.MachineClass, secret, false)
 	if err != nil {
 		klog.Error(err)
 		return nil, err
@@ -441,6 +763,12 @@ func (d *Driver) DeleteMachine(ctx context.Context, req *driver.DeleteMachineReq
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
+	if providerSpec.ElasticIP != nil {
+		if err = releaseElasticIP(ctx, client, req.Machine.Name); err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+	}
+
 	if req.Machine.Spec.ProviderID != "" {
 		// ProviderID exists for machine object, hence terminate the correponding VM
 		_, instanceID, err = decodeRegionAndInstanceID(req.Machine.Spec.ProviderID)
@@ -457,13 +785,12 @@ func (d *Driver) DeleteMachine(ctx context.Context, req *driver.DeleteMachineReq
 	} else {
 		// ProviderID doesn't exist, hence check for any existing machine and then delete if exists
 		instances, err = getMachineInstancesByTagsAndStatus(ctx, client, req.Machine.Name, providerSpec.Tags)
-		if err != nil {
-			if isNotFoundError(err) {
-				klog.V(3).Infof("No matching VM found. Termination successful for machine object %q", req.Machine.Name)
-				return &driver.DeleteMachineResponse{}, nil
-			}
+		if err != nil && !isNotFoundError(err) {
 			return nil, err
 		}
+		if len(instances) == 0 {
+			klog.V(3).Infof("No matching VM found. Termination successful for machine object %q", req.Machine.Name)
+		}
 
 		// If instance(s) exist, terminate them
 		for _, instance := range instances {
@@ -476,6 +803,13 @@ func (d *Driver) DeleteMachine(ctx context.Context, req *driver.DeleteMachineReq
 		}
 	}
 
+	if placement := providerSpec.Placement; placement != nil && placement.DedicatedHosts != nil && placement.DedicatedHosts.Allocate {
+		// The hosts of the machine are also released if a retried deletion does not find its instance anymore
+		if err = releaseEmptyDedicatedHosts(ctx, client, providerSpec.Tags, req.Machine.Name); err != nil {
+			return nil, err
+		}
+	}
+
 	return &driver.DeleteMachineResponse{}, nil
 }
 
@@ -496,7 +830,7 @@ func (d *Driver) GetMachineStatus(ctx context.Context, req *driver.GetMachineSta
 
 	// Log messages to track start and end of request
 	klog.V(3).Infof("Get request has been recieved for %q", req.Machine.Name)
-	providerSpec, err := decodeProviderSpecAndSecret(machineClass, secret)
+	providerSpec, err := d.decodeProviderSpecAndSecret(machineClass, secret, false)
 	if err != nil {
 		return nil, err
 	}
@@ -521,10 +855,25 @@ func (d *Driver) GetMachineStatus(ctx context.Context, req *driver.GetMachineSta
 
 	requiredInstance := instances[0]
 	response := &driver.GetMachineStatusResponse{
-		NodeName:   ptr.Deref(requiredInstance.PrivateDnsName, ""),
+		NodeName:   instanceNodeName(&requiredInstance, providerSpec.Region, providerSpec.PrivateDNSNameOptions),
 		ProviderID: encodeInstanceID(providerSpec.Region, ptr.Deref(requiredInstance.InstanceId, "")),
 	}
 
+	// Hibernated instances still back the machine, but they are not healthy. They are reported as Uninitialized after
+	// all other checks, which neither makes MCM replace the machine as missing nor blocks its deletion.
+	var stoppedMsg string
+	if providerSpec.HibernationOptions != nil && providerSpec.HibernationOptions.Configured {
+		stoppedMsg = stoppedInstanceMessage(&requiredInstance)
+	}
+
+	// Instances of a Capacity Block are terminated when the block ends, so the machine is flagged shortly before. Like
+	// hibernated instances, it is reported as Uninitialized after all other checks. The end of the block is also
+	// exposed as a metric.
+	endingMsg, err := capacityBlockEndingMessage(ctx, client, &requiredInstance)
+	if err != nil {
+		klog.Warningf("Failed to check the Capacity Block of VM %q associated with machine %q: %v", ptr.Deref(requiredInstance.InstanceId, ""), req.Machine.Name, err)
+	}
+
 	// if SrcAnDstCheckEnabled is false then check attribute on instance and return Uninitialized error if not matching.
 	// For instances with EFA interfaces, check per-interface since EFA interfaces don't support SourceDestCheck modification.
 	if providerSpec.SrcAndDstChecksEnabled != nil && !*providerSpec.SrcAndDstChecksEnabled {
@@ -547,7 +896,8 @@ func (d *Driver) GetMachineStatus(ctx context.Context, req *driver.GetMachineSta
 		}
 	}
 
-	// if ipv6PrefixCount is set in providerSpec but Ipv6Prefixes are not assigned to instance, return Uninitialized error
+	// if ipv6PrefixCount, secondaryPrivateIPAddressCount or ipv4PrefixCount is set in providerSpec but the addresses or
+	// prefixes are not assigned to instance, return Uninitialized error
 	for _, instanceNetIf := range requiredInstance.NetworkInterfaces {
 		if instanceNetIf.Attachment == nil {
 			continue
@@ -564,6 +914,61 @@ func (d *Driver) GetMachineStatus(ctx context.Context, req *driver.GetMachineSta
 				ptr.Deref(requiredInstance.InstanceId, ""), req.Machine.Name, ptr.Deref(instanceNetIf.NetworkInterfaceId, ""), idx, *netIf.Ipv6PrefixCount)
 			return response, status.Error(codes.Uninitialized, msg)
 		}
+		if secondaryIPs := countSecondaryPrivateIPAddresses(instanceNetIf); netIf.SecondaryPrivateIPAddressCount != nil && secondaryIPs < *netIf.SecondaryPrivateIPAddressCount {
+			msg := fmt.Sprintf("VM %q associated with machine %q has %d secondary private ipv4 addresses assigned on network interface %q despite providerSpec.NetworkInterfaces[%d].SecondaryPrivateIPAddressCount=%d",
+				ptr.Deref(requiredInstance.InstanceId, ""), req.Machine.Name, secondaryIPs, ptr.Deref(instanceNetIf.NetworkInterfaceId, ""), idx, *netIf.SecondaryPrivateIPAddressCount)
+			return response, status.Error(codes.Uninitialized, msg)
+		}
+		// #nosec: G115 -- index will not exceed int32 limits
+		if netIf.Ipv4PrefixCount != nil && int32(len(instanceNetIf.Ipv4Prefixes)) < *netIf.Ipv4PrefixCount {
+			msg := fmt.Sprintf("VM %q associated with machine %q has %d ipv4 prefixes assigned on network interface %q despite providerSpec.NetworkInterfaces[%d].Ipv4PrefixCount=%d",
+				ptr.Deref(requiredInstance.InstanceId, ""), req.Machine.Name, len(instanceNetIf.Ipv4Prefixes), ptr.Deref(instanceNetIf.NetworkInterfaceId, ""), idx, *netIf.Ipv4PrefixCount)
+			return response, status.Error(codes.Uninitialized, msg)
+		}
+	}
+
+	// if rebootMigration is set in providerSpec but the instance reports another one, return Uninitialized error
+	if rebootMigrationDrifted(&requiredInstance, providerSpec.MaintenanceOptions) {
+		msg := fmt.Sprintf("VM %q associated with machine %q has rebootMigration %q despite providerSpec.MaintenanceOptions.RebootMigration=%q",
+			ptr.Deref(requiredInstance.InstanceId, ""), req.Machine.Name, requiredInstance.MaintenanceOptions.RebootMigration, *providerSpec.MaintenanceOptions.RebootMigration)
+		return response, status.Error(codes.Uninitialized, msg)
+	}
+
+	// if creditSpecification is set in providerSpec but the instance has another credit option, return Uninitialized error
+	if providerSpec.CreditSpecification != nil {
+		cpuCredits, err := getInstanceCPUCredits(ctx, client, ptr.Deref(requiredInstance.InstanceId, ""))
+		if err != nil {
+			return response, status.Error(codes.Internal, err.Error())
+		}
+		if cpuCredits != providerSpec.CreditSpecification.CPUCredits {
+			msg := fmt.Sprintf("VM %q associated with machine %q has cpuCredits %q despite providerSpec.CreditSpecification.CPUCredits=%q",
+				ptr.Deref(requiredInstance.InstanceId, ""), req.Machine.Name, cpuCredits, providerSpec.CreditSpecification.CPUCredits)
+			return response, status.Error(codes.Uninitialized, msg)
+		}
+	}
+
+	// if elasticIP is set in providerSpec but the Elastic IP of the machine is not associated, return Uninitialized error
+	if providerSpec.ElasticIP != nil {
+		address, err := getMachineElasticIP(ctx, client, req.Machine.Name)
+		if err != nil {
+			return response, status.Error(codes.Internal, err.Error())
+		}
+		if address == nil || ptr.Deref(address.InstanceId, "") != ptr.Deref(requiredInstance.InstanceId, "") {
+			msg := fmt.Sprintf("VM %q associated with machine %q has no Elastic IP associated despite providerSpec.ElasticIP being set",
+				ptr.Deref(requiredInstance.InstanceId, ""), req.Machine.Name)
+			return response, status.Error(codes.Uninitialized, msg)
+		}
+	}
+
+	if stoppedMsg != "" {
+		msg := fmt.Sprintf("VM %q associated with machine %q %s", ptr.Deref(requiredInstance.InstanceId, ""), req.Machine.Name, stoppedMsg)
+		klog.V(2).Info(msg)
+		return response, status.Error(codes.Uninitialized, msg)
+	}
+	if endingMsg != "" {
+		msg := fmt.Sprintf("VM %q associated with machine %q %s", ptr.Deref(requiredInstance.InstanceId, ""), req.Machine.Name, endingMsg)
+		klog.Warning(msg)
+		return response, status.Error(codes.Uninitialized, msg)
 	}
 
 	klog.V(3).Infof("Machine get request has been processed successfully for %q", req.Machine.Name)
@@ -588,7 +993,7 @@ func (d *Driver) ListMachines(ctx context.Context, req *driver.ListMachinesReque
 	// Log messages to track start and end of request
 	klog.V(3).Infof("List machines request has been recieved for %q", machineClass.Name)
 
-	providerSpec, err := decodeProviderSpecAndSecret(machineClass, secret)
+	providerSpec, err := d.decodeProviderSpecAndSecret(machineClass, secret, false)
 	if err != nil {
 		return nil, err
 	}
@@ -7,6 +7,8 @@ package aws
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
@@ -15,11 +17,13 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
 
 	"github.com/gardener/machine-controller-manager/pkg/util/provider/driver"
 	"github.com/gardener/machine-controller-manager/pkg/util/provider/machinecodes/codes"
 
 	"github.com/gardener/machine-controller-manager-provider-aws/pkg/mockclient"
+	"github.com/gardener/machine-controller-manager-provider-aws/pkg/replay"
 )
 
 const (
@@ -48,6 +52,8 @@ var _ = Describe("MachineServer", func() {
 	Describe("#CreateMachine", func() {
 		type setup struct {
 			maxElapsedTimeForRetry time.Duration
+			options                Options
+			capacityReservations   []ec2types.CapacityReservation
 		}
 		type action struct {
 			machineRequest *driver.CreateMachineRequest
@@ -64,8 +70,11 @@ var _ = Describe("MachineServer", func() {
 		}
 		DescribeTable("##table",
 			func(data *data) {
-				mockClientProvider := &mockclient.MockClientProvider{FakeInstances: make([]ec2types.Instance, 0)}
-				md := NewAWSDriver(mockClientProvider)
+				mockClientProvider := &mockclient.MockClientProvider{
+					FakeInstances:            make([]ec2types.Instance, 0),
+					FakeCapacityReservations: data.setup.capacityReservations,
+				}
+				md := NewAWSDriverWithOptions(mockClientProvider, data.setup.options)
 
 				ctx := context.Background()
 				var temp time.Duration
@@ -104,6 +113,23 @@ var _ = Describe("MachineServer", func() {
 					errToHaveOccurred: false,
 				},
 			}),
+			Entry("Machine Creation Request with resource-name hostnames", &data{
+				setup: setup{},
+				action: action{
+					machineRequest: &driver.CreateMachineRequest{
+						Machine:      newMachine(-1, nil),
+						MachineClass: newMachineClass([]byte(`{"ami":"ami-123456789","blockDevices":[{"ebs":{"volumeSize":50,"volumeType":"gp2"}}],"iam":{"name":"test-iam"},"machineType":"m4.large","networkInterfaces":[{"ipv6AddressCount":1,"securityGroupIDs":["sg-00002132323"],"subnetID":"subnet-123456"}],"privateDNSNameOptions":{"hostnameType":"resource-name","enableResourceNameDNSAAAARecord":true},"region":"eu-west-1","tags":{"kubernetes.io/cluster/shoot--test":"1","kubernetes.io/role/test":"1"}}`)),
+						Secret:       providerSecret,
+					},
+				},
+				expect: expect{
+					machineResponse: &driver.CreateMachineResponse{
+						ProviderID: "aws:///eu-west-1/i-0123456789-0",
+						NodeName:   "i-0123456789-0.eu-west-1.compute.internal",
+					},
+					errToHaveOccurred: false,
+				},
+			}),
 			Entry("Simple Machine Creation Request with missing provider in MachineClass", &data{
 				action: action{
 					machineRequest: &driver.CreateMachineRequest{
@@ -133,6 +159,48 @@ var _ = Describe("MachineServer", func() {
 					errToHaveOccurred: false,
 				},
 			}),
+			Entry("Machine creation request with CPU options unsupported by the machine type", &data{
+				action: action{
+					machineRequest: &driver.CreateMachineRequest{
+						Machine:      newMachine(-1, nil),
+						MachineClass: newMachineClass([]byte(`{"ami":"ami-123456789","blockDevices":[{"ebs":{"volumeSize":50,"volumeType":"gp2"}}],"cpuOptions":{"coreCount":2,"threadsPerCore":1},"iam":{"name":"test-iam"},"keyName":"test-ssh-publickey","machineType":"m4.large","networkInterfaces":[{"securityGroupIDs":["sg-00002132323"],"subnetID":"subnet-123456"}],"region":"eu-west-1","tags":{"kubernetes.io/cluster/shoot--test":"1","kubernetes.io/role/test":"1"}}`)),
+						Secret:       providerSecret,
+					},
+				},
+				expect: expect{
+					errToHaveOccurred: true,
+					errMessage:        "machine codes error: code = [InvalidArgument] message = [error while validating ProviderSpec against instance type m4.large providerSpec.cpuOptions.coreCount: Invalid value: 2: instance type m4.large supports the core counts [1]]",
+				},
+			}),
+			Entry("Machine creation request with efa on a machine type with multiple network cards", &data{
+				action: action{
+					machineRequest: &driver.CreateMachineRequest{
+						Machine:      newMachine(-1, nil),
+						MachineClass: newMachineClass([]byte(`{"ami":"ami-123456789","blockDevices":[{"ebs":{"volumeSize":50,"volumeType":"gp2"}}],"efa":{},"iam":{"name":"test-iam"},"keyName":"test-ssh-publickey","machineType":"p5.48xlarge","networkInterfaces":[{"securityGroupIDs":["sg-00002132323"],"subnetID":"subnet-123456"}],"region":"eu-west-1","tags":{"kubernetes.io/cluster/shoot--test":"1","kubernetes.io/role/test":"1"}}`)),
+						Secret:       providerSecret,
+					},
+				},
+				expect: expect{
+					machineResponse: &driver.CreateMachineResponse{
+						ProviderID: "aws:///eu-west-1/i-0123456789-0",
+						NodeName:   "ip-0",
+					},
+					errToHaveOccurred: false,
+				},
+			}),
+			Entry("Machine creation request with efa on a machine type without EFA support", &data{
+				action: action{
+					machineRequest: &driver.CreateMachineRequest{
+						Machine:      newMachine(-1, nil),
+						MachineClass: newMachineClass([]byte(`{"ami":"ami-123456789","blockDevices":[{"ebs":{"volumeSize":50,"volumeType":"gp2"}}],"efa":{},"iam":{"name":"test-iam"},"keyName":"test-ssh-publickey","machineType":"m4.large","networkInterfaces":[{"securityGroupIDs":["sg-00002132323"],"subnetID":"subnet-123456"}],"region":"eu-west-1","tags":{"kubernetes.io/cluster/shoot--test":"1","kubernetes.io/role/test":"1"}}`)),
+						Secret:       providerSecret,
+					},
+				},
+				expect: expect{
+					errToHaveOccurred: true,
+					errMessage:        "machine codes error: code = [InvalidArgument] message = [efa is set, but machine type m4.large does not support Elastic Fabric Adapters]",
+				},
+			}),
 			Entry("Machine creation request with volume type io1", &data{
 				action: action{
 					machineRequest: &driver.CreateMachineRequest{
@@ -181,6 +249,35 @@ var _ = Describe("MachineServer", func() {
 					errToHaveOccurred: false,
 				},
 			}),
+			Entry("Machine creation request for v1alpha2 spot instance type with max price", &data{
+				action: action{
+					machineRequest: &driver.CreateMachineRequest{
+						Machine:      newMachine(-1, nil),
+						MachineClass: newMachineClass([]byte(`{"apiVersion":"mcm.gardener.cloud/v1alpha2","ami":"ami-123456789","blockDevices":[{"ebs":{"volumeSize":50,"volumeType":"gp2"}}],"iam":{"name":"test-iam"},"keyName":"test-ssh-publickey","machineType":"m4.large","networkInterfaces":[{"securityGroupIDs":["sg-00002132323"],"subnetID":"subnet-123456"}],"region":"eu-west-1","instanceMarketOptions":{"marketType":"spot","spotOptions":{"maxPrice":"500"}},"tags":{"kubernetes.io/cluster/shoot--test":"1","kubernetes.io/role/test":"1"}}`)),
+						Secret:       providerSecret,
+					},
+				},
+				expect: expect{
+					machineResponse: &driver.CreateMachineResponse{
+						ProviderID: "aws:///eu-west-1/i-0123456789-0",
+						NodeName:   "ip-0",
+					},
+					errToHaveOccurred: false,
+				},
+			}),
+			Entry("Machine creation request with unsupported apiVersion", &data{
+				action: action{
+					machineRequest: &driver.CreateMachineRequest{
+						Machine:      newMachine(-1, nil),
+						MachineClass: newMachineClass([]byte(`{"apiVersion":"mcm.gardener.cloud/v2","ami":"ami-123456789"}`)),
+						Secret:       providerSecret,
+					},
+				},
+				expect: expect{
+					errToHaveOccurred: true,
+					errMessage:        `machine codes error: code = [InvalidArgument] message = [providerSpec.apiVersion: Unsupported value: "mcm.gardener.cloud/v2": supported values: "mcm.gardener.cloud/v1alpha1", "mcm.gardener.cloud/v1alpha2"]`,
+				},
+			}),
 			Entry("Machine creation request for capacity reservations fails if more than one type given", &data{
 				action: action{
 					machineRequest: &driver.CreateMachineRequest{
@@ -211,6 +308,14 @@ var _ = Describe("MachineServer", func() {
 				},
 			}),
 			Entry("Machine creation request for capacity reservations with capacityReservationId", &data{
+				setup: setup{
+					capacityReservations: []ec2types.CapacityReservation{{
+						CapacityReservationId:  ptr.To("cr-05c28b843c05abcde"),
+						InstanceType:           ptr.To("m4.large"),
+						State:                  ec2types.CapacityReservationStateActive,
+						AvailableInstanceCount: ptr.To[int32](1),
+					}},
+				},
 				action: action{
 					machineRequest: &driver.CreateMachineRequest{
 						Machine:      newMachine(-1, nil),
@@ -255,6 +360,38 @@ var _ = Describe("MachineServer", func() {
 					errMessage:        "machine codes error: code = [Internal] message = [unexpected end of JSON input]",
 				},
 			}),
+			Entry("Unknown fields in provider spec are rejected", &data{
+				action: action{
+					machineRequest: &driver.CreateMachineRequest{
+						Machine:      newMachine(-1, nil),
+						MachineClass: newMachineClass([]byte(`{"ami":"ami-123456789","blockDevices":[{"ebs":{"volumeSize":50,"volumetype":"gp2"}}],"iam":{"name":"test-iam"},"keyName":"test-ssh-publickey","machineType":"m4.large","networkInterfaces":[{"securityGroupIDs":["sg-00002132323"],"subnetID":"subnet-123456","ipv6PrefixCnt":1}],"region":"eu-west-1","tags":{"kubernetes.io/cluster/shoot--test":"1","kubernetes.io/role/test":"1"}}`)),
+						Secret:       providerSecret,
+					},
+				},
+				expect: expect{
+					errToHaveOccurred: true,
+					errMessage:        "machine codes error: code = [InvalidArgument] message = [error while decoding ProviderSpec providerSpec.networkInterfaces[0].ipv6PrefixCnt: Forbidden: unknown field]",
+				},
+			}),
+			Entry("Unknown fields in provider spec are accepted in compatibility mode", &data{
+				setup: setup{
+					options: Options{AllowUnknownProviderSpecFields: true},
+				},
+				action: action{
+					machineRequest: &driver.CreateMachineRequest{
+						Machine:      newMachine(-1, nil),
+						MachineClass: newMachineClass([]byte(`{"ami":"ami-123456789","blockDevices":[{"ebs":{"volumeSize":50,"volumeType":"gp2"}}],"iam":{"name":"test-iam"},"keyName":"test-ssh-publickey","machineType":"m4.large","networkInterfaces":[{"securityGroupIDs":["sg-00002132323"],"subnetID":"subnet-123456","ipv6PrefixCnt":1}],"region":"eu-west-1","tags":{"kubernetes.io/cluster/shoot--test":"1","kubernetes.io/role/test":"1"}}`)),
+						Secret:       providerSecret,
+					},
+				},
+				expect: expect{
+					machineResponse: &driver.CreateMachineResponse{
+						ProviderID: "aws:///eu-west-1/i-0123456789-0",
+						NodeName:   "ip-0",
+					},
+					errToHaveOccurred: false,
+				},
+			}),
 			Entry("providerAccessKeyId missing for secret", &data{
 				action: action{
 					machineRequest: &driver.CreateMachineRequest{
@@ -351,6 +488,32 @@ var _ = Describe("MachineServer", func() {
 					},
 				},
 			}),
+			Entry("Placement annotation conflicting with placement in providerSpec", &data{
+				action: action{
+					machineRequest: &driver.CreateMachineRequest{
+						Machine:      newMachine(0, annotations),
+						MachineClass: newMachineClass([]byte(`{"ami":"ami-123456789","blockDevices":[{"ebs":{"volumeSize":50,"volumeType":"gp2"}}],"iam":{"name":"test-iam"},"keyName":"test-ssh-publickey","machineType":"m4.large","networkInterfaces":[{"securityGroupIDs":["sg-00002132323"],"subnetID":"subnet-123456"}],"placement":{"tenancy":"dedicated"},"region":"eu-west-1","tags":{"kubernetes.io/cluster/shoot--test":"1","kubernetes.io/role/test":"1"}}`)),
+						Secret:       providerSecret,
+					},
+				},
+				expect: expect{
+					errToHaveOccurred: true,
+					errMessage:        "machine codes error: code = [InvalidArgument] message = [the deprecated machine.sapcloud.io/awsPlacement annotation conflicts with providerSpec.placement in [tenancy affinity], remove the annotation]",
+				},
+			}),
+			Entry("Machine creation request with spotPrice conflicting with instanceMarketOptions", &data{
+				action: action{
+					machineRequest: &driver.CreateMachineRequest{
+						Machine:      newMachine(-1, nil),
+						MachineClass: newMachineClass([]byte(`{"ami":"ami-123456789","blockDevices":[{"ebs":{"volumeSize":50,"volumeType":"gp2"}}],"iam":{"name":"test-iam"},"keyName":"test-ssh-publickey","machineType":"m4.large","networkInterfaces":[{"securityGroupIDs":["sg-00002132323"],"subnetID":"subnet-123456"}],"region":"eu-west-1","spotPrice":"500","instanceMarketOptions":{"marketType":"spot"},"tags":{"kubernetes.io/cluster/shoot--test":"1","kubernetes.io/role/test":"1"}}`)),
+						Secret:       providerSecret,
+					},
+				},
+				expect: expect{
+					errToHaveOccurred: true,
+					errMessage:        `machine codes error: code = [InvalidArgument] message = [error while validating ProviderSpec providerSpec.spotPrice: Invalid value: "500": conflicts with instanceMarketOptions, remove the deprecated spotPrice and use instanceMarketOptions.spotOptions.maxPrice instead]`,
+				},
+			}),
 			Entry("Invalid image ID that doesn't exist", &data{
 				action: action{
 					machineRequest: &driver.CreateMachineRequest{
@@ -423,6 +586,56 @@ var _ = Describe("MachineServer", func() {
 				},
 			}),
 		)
+
+		Context("with existing network interfaces", func() {
+			const existingNetworkInterfacesProviderSpec = `{"ami":"ami-123456789","blockDevices":[{"ebs":{"volumeSize":50,"volumeType":"gp2"}}],"iam":{"name":"test-iam"},"machineType":"m4.large","networkInterfaces":[{"networkInterfaceID":"eni-fixed"},{"networkInterfaceTags":{"appliance":"fw"}}],"region":"eu-west-1","tags":{"kubernetes.io/cluster/shoot--test":"1","kubernetes.io/role/test":"1"}}`
+			var mockClientProvider *mockclient.MockClientProvider
+
+			BeforeEach(func() {
+				mockClientProvider = &mockclient.MockClientProvider{
+					FakeNetworkInterfaces: []ec2types.NetworkInterface{
+						{NetworkInterfaceId: ptr.To("eni-fixed"), Status: ec2types.NetworkInterfaceStatusAvailable},
+						{NetworkInterfaceId: ptr.To("eni-fw-attached"), Status: ec2types.NetworkInterfaceStatusInUse, TagSet: []ec2types.Tag{{Key: ptr.To("appliance"), Value: ptr.To("fw")}}},
+						{NetworkInterfaceId: ptr.To("eni-fw-available"), Status: ec2types.NetworkInterfaceStatusAvailable, TagSet: []ec2types.Tag{{Key: ptr.To("appliance"), Value: ptr.To("fw")}}},
+					},
+				}
+			})
+
+			It("should attach them without deleting them on termination", func() {
+				md := NewAWSDriver(mockClientProvider)
+				machineClass := newMachineClass([]byte(existingNetworkInterfacesProviderSpec))
+
+				_, err := md.CreateMachine(context.Background(), &driver.CreateMachineRequest{Machine: newMachine(-1, nil), MachineClass: machineClass, Secret: providerSecret})
+				Expect(err).ToNot(HaveOccurred())
+				networkInterfaces := mockClientProvider.FakeInstances[0].NetworkInterfaces
+				Expect(networkInterfaces).To(HaveLen(2))
+				Expect(networkInterfaces[0].NetworkInterfaceId).To(Equal(ptr.To("eni-fixed")))
+				Expect(networkInterfaces[0].Attachment.DeviceIndex).To(Equal(ptr.To[int32](0)))
+				Expect(networkInterfaces[0].Attachment.DeleteOnTermination).To(Equal(ptr.To(false)))
+				Expect(networkInterfaces[1].NetworkInterfaceId).To(Equal(ptr.To("eni-fw-available")))
+				Expect(networkInterfaces[1].Attachment.DeviceIndex).To(Equal(ptr.To[int32](1)))
+				Expect(networkInterfaces[1].Attachment.DeleteOnTermination).To(Equal(ptr.To(false)))
+
+				_, err = md.DeleteMachine(context.Background(), &driver.DeleteMachineRequest{Machine: newMachine(0, nil), MachineClass: machineClass, Secret: providerSecret})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(mockClientProvider.FakeNetworkInterfaces).To(HaveLen(3))
+				for _, networkInterface := range mockClientProvider.FakeNetworkInterfaces {
+					if ptr.Deref(networkInterface.NetworkInterfaceId, "") != "eni-fw-attached" {
+						Expect(networkInterface.Status).To(Equal(ec2types.NetworkInterfaceStatusAvailable))
+					}
+				}
+			})
+
+			It("should fail with ResourceExhausted if no network interface with the tags is available", func() {
+				mockClientProvider.FakeNetworkInterfaces[2].Status = ec2types.NetworkInterfaceStatusInUse
+				md := NewAWSDriver(mockClientProvider)
+
+				_, err := md.CreateMachine(context.Background(), &driver.CreateMachineRequest{Machine: newMachine(-1, nil), MachineClass: newMachineClass([]byte(existingNetworkInterfacesProviderSpec)), Secret: providerSecret})
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("machine codes error: code = [ResourceExhausted] message = [no available network interface with tags map[appliance:fw] found for providerSpec.networkInterfaces[1]]"))
+				Expect(mockClientProvider.FakeInstances).To(BeEmpty())
+			})
+		})
 	})
 
 	Describe("#InitializeMachine", func() {
@@ -536,6 +749,282 @@ var _ = Describe("MachineServer", func() {
 				},
 			}),
 		)
+
+		It("should assign missing secondary private ipv4 addresses and ipv4 prefixes", func() {
+			mockClientProvider := &mockclient.MockClientProvider{FakeInstances: make([]ec2types.Instance, 0)}
+			md := NewAWSDriver(mockClientProvider)
+			ctx := context.Background()
+			machineClass := newMachineClass([]byte(strings.ReplaceAll(string(providerSpec), `"subnetID":"subnet-123456"`,
+				`"subnetID":"subnet-123456","secondaryPrivateIPAddressCount":2,"ipv4PrefixCount":1`)))
+
+			_, err := md.CreateMachine(ctx, &driver.CreateMachineRequest{Machine: newMachine(0, nil), MachineClass: machineClass, Secret: providerSecret})
+			Expect(err).ToNot(HaveOccurred())
+			// Simulate that only one secondary address was assigned at launch
+			mockClientProvider.FakeInstances[0].NetworkInterfaces = []ec2types.InstanceNetworkInterface{{
+				NetworkInterfaceId: ptr.To("eni-0"),
+				Attachment:         &ec2types.InstanceNetworkInterfaceAttachment{DeviceIndex: ptr.To[int32](0)},
+				PrivateIpAddresses: []ec2types.InstancePrivateIpAddress{
+					{PrivateIpAddress: ptr.To("10.0.0.1"), Primary: ptr.To(true)},
+					{PrivateIpAddress: ptr.To("10.0.0.2"), Primary: ptr.To(false)},
+				},
+			}}
+
+			getMachineStatusRequest := &driver.GetMachineStatusRequest{Machine: newMachine(0, nil), MachineClass: machineClass, Secret: providerSecret}
+			_, err = md.GetMachineStatus(ctx, getMachineStatusRequest)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("code = [Uninitialized]"))
+			Expect(err.Error()).To(ContainSubstring(`has 1 secondary private ipv4 addresses assigned on network interface "eni-0" despite providerSpec.NetworkInterfaces[0].SecondaryPrivateIPAddressCount=2`))
+
+			_, err = md.InitializeMachine(ctx, &driver.InitializeMachineRequest{Machine: newMachine(0, nil), MachineClass: machineClass, Secret: providerSecret})
+			Expect(err).ToNot(HaveOccurred())
+			netIf := mockClientProvider.FakeInstances[0].NetworkInterfaces[0]
+			Expect(netIf.PrivateIpAddresses).To(HaveLen(3))
+			Expect(netIf.Ipv4Prefixes).To(HaveLen(1))
+
+			_, err = md.GetMachineStatus(ctx, getMachineStatusRequest)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should launch burstable instances with the cpu credits and restore them", func() {
+			mockClientProvider := &mockclient.MockClientProvider{FakeInstances: make([]ec2types.Instance, 0)}
+			md := NewAWSDriver(mockClientProvider)
+			ctx := context.Background()
+			machineClass := newMachineClass([]byte(strings.ReplaceAll(string(providerSpec), `"machineType":"m4.large"`,
+				`"creditSpecification":{"cpuCredits":"unlimited"},"machineType":"t3.medium"`)))
+
+			_, err := md.CreateMachine(ctx, &driver.CreateMachineRequest{Machine: newMachine(0, nil), MachineClass: machineClass, Secret: providerSecret})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(mockClientProvider.FakeCPUCredits).To(Equal(map[string]string{"i-0123456789-0": "unlimited"}))
+
+			getMachineStatusRequest := &driver.GetMachineStatusRequest{Machine: newMachine(0, nil), MachineClass: machineClass, Secret: providerSecret}
+			_, err = md.GetMachineStatus(ctx, getMachineStatusRequest)
+			Expect(err).ToNot(HaveOccurred())
+
+			// Simulate that the credit option was changed outside of the driver
+			mockClientProvider.FakeCPUCredits["i-0123456789-0"] = "standard"
+			_, err = md.GetMachineStatus(ctx, getMachineStatusRequest)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("code = [Uninitialized]"))
+			Expect(err.Error()).To(ContainSubstring(`has cpuCredits "standard" despite providerSpec.CreditSpecification.CPUCredits="unlimited"`))
+
+			_, err = md.InitializeMachine(ctx, &driver.InitializeMachineRequest{Machine: newMachine(0, nil), MachineClass: machineClass, Secret: providerSecret})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(mockClientProvider.FakeCPUCredits["i-0123456789-0"]).To(Equal("unlimited"))
+			_, err = md.GetMachineStatus(ctx, getMachineStatusRequest)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should launch instances with the auto-recovery and apply the reboot migration", func() {
+			mockClientProvider := &mockclient.MockClientProvider{FakeInstances: make([]ec2types.Instance, 0)}
+			md := NewAWSDriver(mockClientProvider)
+			ctx := context.Background()
+			machineClass := newMachineClass([]byte(strings.ReplaceAll(string(providerSpec), `"machineType":"m4.large"`,
+				`"machineType":"m4.large","maintenanceOptions":{"autoRecovery":"disabled","rebootMigration":"disabled"}`)))
+
+			_, err := md.CreateMachine(ctx, &driver.CreateMachineRequest{Machine: newMachine(0, nil), MachineClass: machineClass, Secret: providerSecret})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(mockClientProvider.FakeInstances[0].MaintenanceOptions.AutoRecovery).To(Equal(ec2types.InstanceAutoRecoveryStateDisabled))
+
+			getMachineStatusRequest := &driver.GetMachineStatusRequest{Machine: newMachine(0, nil), MachineClass: machineClass, Secret: providerSecret}
+			_, err = md.GetMachineStatus(ctx, getMachineStatusRequest)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("code = [Uninitialized]"))
+			Expect(err.Error()).To(ContainSubstring(`has rebootMigration "default" despite providerSpec.MaintenanceOptions.RebootMigration="disabled"`))
+
+			_, err = md.InitializeMachine(ctx, &driver.InitializeMachineRequest{Machine: newMachine(0, nil), MachineClass: machineClass, Secret: providerSecret})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(mockClientProvider.FakeInstances[0].MaintenanceOptions.RebootMigration).To(Equal(ec2types.InstanceRebootMigrationStateDisabled))
+			_, err = md.GetMachineStatus(ctx, getMachineStatusRequest)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should launch hibernation-capable instances and report hibernated instances as uninitialized", func() {
+			mockClientProvider := &mockclient.MockClientProvider{FakeInstances: make([]ec2types.Instance, 0)}
+			md := NewAWSDriver(mockClientProvider)
+			ctx := context.Background()
+			machineClass := newMachineClass([]byte(strings.ReplaceAll(string(providerSpec), `"blockDevices":[{"ebs":{"volumeSize":50,"volumeType":"gp2"}}]`,
+				`"blockDevices":[{"ebs":{"encrypted":true,"volumeSize":50,"volumeType":"gp2"}}],"hibernationOptions":{"configured":true}`)))
+
+			_, err := md.CreateMachine(ctx, &driver.CreateMachineRequest{Machine: newMachine(0, nil), MachineClass: machineClass, Secret: providerSecret})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(mockClientProvider.FakeInstances[0].HibernationOptions.Configured).To(Equal(ptr.To(true)))
+
+			// Simulate that the instance was hibernated
+			mockClientProvider.FakeInstances[0].State = &ec2types.InstanceState{Name: ec2types.InstanceStateNameStopped}
+			mockClientProvider.FakeInstances[0].StateReason = &ec2types.StateReason{Code: ptr.To("Client.UserInitiatedHibernate")}
+			statusResponse, err := md.GetMachineStatus(ctx, &driver.GetMachineStatusRequest{Machine: newMachine(0, nil), MachineClass: machineClass, Secret: providerSecret})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("code = [Uninitialized]"))
+			Expect(err.Error()).To(ContainSubstring(`VM "i-0123456789-0" associated with machine "machine-0" is hibernated`))
+			Expect(statusResponse.ProviderID).To(Equal("aws:///eu-west-1/i-0123456789-0"))
+
+			// The checks of running instances still apply to hibernated ones
+			mockClientProvider.FakeInstances[0].MaintenanceOptions = &ec2types.InstanceMaintenanceOptions{RebootMigration: ec2types.InstanceRebootMigrationStateDefault}
+			hibernatedMachineClass := newMachineClass([]byte(strings.ReplaceAll(string(machineClass.ProviderSpec.Raw), `"hibernationOptions"`,
+				`"maintenanceOptions":{"rebootMigration":"disabled"},"hibernationOptions"`)))
+			_, err = md.GetMachineStatus(ctx, &driver.GetMachineStatusRequest{Machine: newMachine(0, nil), MachineClass: hibernatedMachineClass, Secret: providerSecret})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring(`has rebootMigration "default" despite providerSpec.MaintenanceOptions.RebootMigration="disabled"`))
+
+			listResponse, err := md.ListMachines(ctx, &driver.ListMachinesRequest{MachineClass: machineClass, Secret: providerSecret})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(listResponse.MachineList).To(Equal(map[string]string{"aws:///eu-west-1/i-0123456789-0": "machine-0"}))
+
+			_, err = md.DeleteMachine(ctx, &driver.DeleteMachineRequest{Machine: newMachine(0, nil), MachineClass: machineClass, Secret: providerSecret})
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should reject hibernation if the root volume is not larger than the memory of the machine type", func() {
+			md := NewAWSDriver(&mockclient.MockClientProvider{})
+			machineClass := newMachineClass([]byte(strings.ReplaceAll(string(providerSpec), `"blockDevices":[{"ebs":{"volumeSize":50,"volumeType":"gp2"}}]`,
+				`"blockDevices":[{"ebs":{"encrypted":true,"volumeSize":8,"volumeType":"gp2"}}],"hibernationOptions":{"configured":true}`)))
+
+			_, err := md.CreateMachine(context.Background(), &driver.CreateMachineRequest{Machine: newMachine(0, nil), MachineClass: machineClass, Secret: providerSecret})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("code = [InvalidArgument]"))
+			Expect(err.Error()).To(ContainSubstring("the root volume must be larger than the 8192 MiB memory of instance type m4.large for hibernation"))
+		})
+
+		It("should launch instances with Nitro Enclaves and NitroTPM from UEFI AMIs", func() {
+			mockClientProvider := &mockclient.MockClientProvider{
+				FakeInstances: make([]ec2types.Instance, 0),
+				FakeImages: []ec2types.Image{{
+					ImageId:        ptr.To("ami-123456789"),
+					RootDeviceName: ptr.To("/dev/xvda"),
+					BootMode:       ec2types.BootModeValuesUefi,
+					TpmSupport:     ec2types.TpmSupportValuesV20,
+				}},
+			}
+			md := NewAWSDriver(mockClientProvider)
+			machineClass := newMachineClass([]byte(strings.ReplaceAll(string(providerSpec), `"machineType":"m4.large"`,
+				`"bootMode":"uefi","enclaveOptions":{"enabled":true},"machineType":"m4.large","tpmSupport":"v2.0"`)))
+
+			_, err := md.CreateMachine(context.Background(), &driver.CreateMachineRequest{Machine: newMachine(0, nil), MachineClass: machineClass, Secret: providerSecret})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(mockClientProvider.FakeInstances[0].EnclaveOptions.Enabled).To(Equal(ptr.To(true)))
+		})
+
+		It("should fail with InvalidArgument if the AMI does not support the boot mode or NitroTPM", func() {
+			mockClientProvider := &mockclient.MockClientProvider{
+				FakeInstances: make([]ec2types.Instance, 0),
+				FakeImages: []ec2types.Image{{
+					ImageId:        ptr.To("ami-123456789"),
+					RootDeviceName: ptr.To("/dev/xvda"),
+					BootMode:       ec2types.BootModeValuesLegacyBios,
+				}},
+			}
+			md := NewAWSDriver(mockClientProvider)
+
+			machineClass := newMachineClass([]byte(strings.ReplaceAll(string(providerSpec), `"machineType":"m4.large"`,
+				`"bootMode":"uefi","machineType":"m4.large"`)))
+			_, err := md.CreateMachine(context.Background(), &driver.CreateMachineRequest{Machine: newMachine(0, nil), MachineClass: machineClass, Secret: providerSecret})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("code = [InvalidArgument]"))
+			Expect(err.Error()).To(ContainSubstring(`AMI ami-123456789 has the boot mode "legacy-bios", but providerSpec.BootMode is "uefi"`))
+
+			machineClass = newMachineClass([]byte(strings.ReplaceAll(string(providerSpec), `"machineType":"m4.large"`,
+				`"machineType":"m4.large","tpmSupport":"v2.0"`)))
+			_, err = md.CreateMachine(context.Background(), &driver.CreateMachineRequest{Machine: newMachine(0, nil), MachineClass: machineClass, Secret: providerSecret})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("code = [InvalidArgument]"))
+			Expect(err.Error()).To(ContainSubstring(`AMI ami-123456789 has the tpmSupport "", but providerSpec.TPMSupport is "v2.0"`))
+			Expect(mockClientProvider.FakeInstances).To(BeEmpty())
+		})
+
+		It("should launch instances with license configurations into a host resource group", func() {
+			mockClientProvider := &mockclient.MockClientProvider{FakeInstances: make([]ec2types.Instance, 0)}
+			md := NewAWSDriver(mockClientProvider)
+			machineClass := newMachineClass([]byte(strings.ReplaceAll(string(providerSpec), `"machineType":"m4.large"`,
+				`"licenseConfigurationArns":["arn:aws:license-manager:eu-west-1:123456789012:license-configuration:lic-0123456789abcdef0"],`+
+					`"machineType":"m4.large","placement":{"tenancy":"host","hostResourceGroupName":"byol"}`)))
+
+			_, err := md.CreateMachine(context.Background(), &driver.CreateMachineRequest{Machine: newMachine(0, nil), MachineClass: machineClass, Secret: providerSecret})
+			Expect(err).ToNot(HaveOccurred())
+			instance := mockClientProvider.FakeInstances[0]
+			Expect(instance.Placement.Tenancy).To(Equal(ec2types.TenancyHost))
+			Expect(instance.Placement.HostResourceGroupArn).To(Equal(ptr.To("arn:aws:resource-groups:eu-west-1:" + mockclient.FakeAccountID + ":group/byol")))
+			Expect(instance.Licenses).To(ConsistOf(ec2types.LicenseConfiguration{
+				LicenseConfigurationArn: ptr.To("arn:aws:license-manager:eu-west-1:123456789012:license-configuration:lic-0123456789abcdef0"),
+			}))
+		})
+
+		It("should fail with ResourceExhausted if the host resource group has no capacity", func() {
+			md := NewAWSDriver(&mockclient.MockClientProvider{FakeInstances: make([]ec2types.Instance, 0)})
+			machineClass := newMachineClass([]byte(strings.ReplaceAll(string(providerSpec), `"machineType":"m4.large"`,
+				`"machineType":"m4.large","placement":{"tenancy":"host","hostResourceGroupArn":"`+mockclient.FullHostResourceGroup+`"}`)))
+
+			_, err := md.CreateMachine(context.Background(), &driver.CreateMachineRequest{Machine: newMachine(0, nil), MachineClass: machineClass, Secret: providerSecret})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("code = [ResourceExhausted]"))
+		})
+
+		It("should spread the machines of a MachineClass round-robin across the partitions of a placement group", func() {
+			mockClientProvider := &mockclient.MockClientProvider{
+				FakeInstances: make([]ec2types.Instance, 0),
+				FakePlacementGroups: []ec2types.PlacementGroup{{
+					GroupId:        ptr.To("pg-0123456789"),
+					GroupName:      ptr.To("spread"),
+					Strategy:       ec2types.PlacementStrategyPartition,
+					PartitionCount: ptr.To[int32](2),
+				}},
+			}
+			md := NewAWSDriver(mockClientProvider)
+			machineClass := newMachineClass([]byte(strings.ReplaceAll(string(providerSpec), `"machineType":"m4.large"`,
+				`"machineType":"m4.large","placement":{"groupName":"spread","spreadPartitions":true}`)))
+
+			for i := range 3 {
+				_, err := md.CreateMachine(context.Background(), &driver.CreateMachineRequest{Machine: newMachine(i, nil), MachineClass: machineClass, Secret: providerSecret})
+				Expect(err).ToNot(HaveOccurred())
+			}
+			var partitions []int32
+			for _, instance := range mockClientProvider.FakeInstances {
+				Expect(instance.Placement.GroupName).To(Equal(ptr.To("spread")))
+				Expect(instance.Tags).To(ContainElement(ec2types.Tag{Key: ptr.To(machineClassTagKey), Value: ptr.To(machineClass.Name)}))
+				Expect(instance.Tags).To(ContainElement(ec2types.Tag{Key: ptr.To(placementPartitionTagKey), Value: ptr.To(strconv.Itoa(int(*instance.Placement.PartitionNumber)))}))
+				partitions = append(partitions, *instance.Placement.PartitionNumber)
+			}
+			Expect(partitions).To(Equal([]int32{1, 2, 1}))
+		})
+
+		It("should fail with InvalidArgument if partitions are spread across a placement group without partitions", func() {
+			md := NewAWSDriver(&mockclient.MockClientProvider{
+				FakeInstances: make([]ec2types.Instance, 0),
+				FakePlacementGroups: []ec2types.PlacementGroup{{
+					GroupId:   ptr.To("pg-0123456789"),
+					GroupName: ptr.To("cluster"),
+					Strategy:  ec2types.PlacementStrategyCluster,
+				}},
+			})
+			machineClass := newMachineClass([]byte(strings.ReplaceAll(string(providerSpec), `"machineType":"m4.large"`,
+				`"machineType":"m4.large","placement":{"groupId":"pg-0123456789","spreadPartitions":true}`)))
+
+			_, err := md.CreateMachine(context.Background(), &driver.CreateMachineRequest{Machine: newMachine(0, nil), MachineClass: machineClass, Secret: providerSecret})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("code = [InvalidArgument]"))
+			Expect(err.Error()).To(ContainSubstring(`placement group cluster has the strategy "cluster", spreading partitions requires the "partition" strategy`))
+		})
+
+		It("should fail with InvalidArgument if the machine type does not support auto-recovery", func() {
+			md := NewAWSDriver(&mockclient.MockClientProvider{})
+			machineClass := newMachineClass([]byte(strings.ReplaceAll(string(providerSpec), `"machineType":"m4.large"`,
+				`"machineType":"`+mockclient.UnsupportedAutoRecoveryInstanceType+`","maintenanceOptions":{"autoRecovery":"default"}`)))
+
+			_, err := md.CreateMachine(context.Background(), &driver.CreateMachineRequest{Machine: newMachine(0, nil), MachineClass: machineClass, Secret: providerSecret})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("code = [InvalidArgument]"))
+			Expect(err.Error()).To(ContainSubstring("does not support simplified automatic recovery"))
+		})
+
+		It("should reject cpu credits for non-burstable machine types", func() {
+			md := NewAWSDriver(&mockclient.MockClientProvider{})
+			machineClass := newMachineClass([]byte(strings.ReplaceAll(string(providerSpec), `"machineType":"m4.large"`,
+				`"creditSpecification":{"cpuCredits":"unlimited"},"machineType":"m5.large"`)))
+
+			_, err := md.CreateMachine(context.Background(), &driver.CreateMachineRequest{Machine: newMachine(0, nil), MachineClass: machineClass, Secret: providerSecret})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("code = [InvalidArgument]"))
+			Expect(err.Error()).To(ContainSubstring("providerSpec.creditSpecification: Forbidden: instance type m5.large is not a burstable performance instance type"))
+		})
 	})
 
 	Describe("#GetPlacementObject", func() {
@@ -548,6 +1037,7 @@ var _ = Describe("MachineServer", func() {
 		type expect struct {
 			placementobj      *ec2types.Placement
 			errToHaveOccurred bool
+			errMessage        string
 		}
 		type data struct {
 			setup  setup
@@ -562,6 +1052,9 @@ var _ = Describe("MachineServer", func() {
 
 				if data.expect.errToHaveOccurred {
 					Expect(err).To(HaveOccurred())
+					if data.expect.errMessage != "" {
+						Expect(err.Error()).To(Equal(data.expect.errMessage))
+					}
 				} else {
 					Expect(err).ToNot(HaveOccurred())
 					Expect(obj).To(Equal(data.expect.placementobj))
@@ -692,11 +1185,11 @@ var _ = Describe("MachineServer", func() {
 					},
 				},
 			}),
-			Entry("when just hostId and partitionNumber is set", &data{
+			Entry("when hostId, partitionNumber and tenancy host is set", &data{
 				setup: setup{
 					objectmeta: v1.ObjectMeta{
 						Annotations: map[string]string{
-							awsPlacement: `{ "hostId": "h-0123b456af7f89123", "partitionNumber": 7}`,
+							awsPlacement: `{ "hostId": "h-0123b456af7f89123", "partitionNumber": 7, "tenancy": "host"}`,
 						},
 					},
 				},
@@ -712,8 +1205,69 @@ var _ = Describe("MachineServer", func() {
 					placementobj: &ec2types.Placement{
 						HostId:          getStringPtr("h-0123b456af7f89123"),
 						PartitionNumber: getInt32PtrForString("7"),
+						Tenancy:         ec2types.TenancyHost,
+					},
+				},
+			}),
+			Entry("when just hostId and partitionNumber is set", &data{
+				setup: setup{
+					objectmeta: v1.ObjectMeta{
+						Annotations: map[string]string{
+							awsPlacement: `{ "hostId": "h-0123b456af7f89123", "partitionNumber": 7}`,
+						},
+					},
+				},
+				action: action{
+					createMachineRequest: &driver.CreateMachineRequest{
+						Machine:      newMachine(0, nil),
+						MachineClass: newMachineClass(providerSpec),
+						Secret:       providerSecret,
+					},
+				},
+				expect: expect{
+					errToHaveOccurred: true,
+					errMessage:        `machine codes error: code = [InvalidArgument] message = [invalid machine.sapcloud.io/awsPlacement annotation: spec.nodeTemplate.metadata.annotations[machine.sapcloud.io/awsPlacement].hostId: Forbidden: hostId can only be set when tenancy is "host"]`,
+				},
+			}),
+			Entry("when tenancy is invalid", &data{
+				setup: setup{
+					objectmeta: v1.ObjectMeta{
+						Annotations: map[string]string{
+							awsPlacement: `{ "tenancy": "shared"}`,
+						},
+					},
+				},
+				action: action{
+					createMachineRequest: &driver.CreateMachineRequest{
+						Machine:      newMachine(0, nil),
+						MachineClass: newMachineClass(providerSpec),
+						Secret:       providerSecret,
+					},
+				},
+				expect: expect{
+					errToHaveOccurred: true,
+					errMessage:        `machine codes error: code = [InvalidArgument] message = [invalid machine.sapcloud.io/awsPlacement annotation: spec.nodeTemplate.metadata.annotations[machine.sapcloud.io/awsPlacement].tenancy: Unsupported value: "shared": supported values: "default", "dedicated", "host"]`,
+				},
+			}),
+			Entry("when an unknown key is set", &data{
+				setup: setup{
+					objectmeta: v1.ObjectMeta{
+						Annotations: map[string]string{
+							awsPlacement: `{ "spreadDomain": "foo"}`,
+						},
+					},
+				},
+				action: action{
+					createMachineRequest: &driver.CreateMachineRequest{
+						Machine:      newMachine(0, nil),
+						MachineClass: newMachineClass(providerSpec),
+						Secret:       providerSecret,
 					},
 				},
+				expect: expect{
+					errToHaveOccurred: true,
+					errMessage:        `machine codes error: code = [InvalidArgument] message = [invalid machine.sapcloud.io/awsPlacement annotation: json: unknown field "spreadDomain"]`,
+				},
 			}),
 		)
 	})
@@ -929,6 +1483,46 @@ var _ = Describe("MachineServer", func() {
 					errToHaveOccurred:     false,
 				},
 			}),
+			Entry("Machine Delete Request with fields unknown to the driver in provider spec", &data{
+				setup: setup{
+					createMachineRequest: &driver.CreateMachineRequest{
+						Machine:      newMachine(0, nil),
+						MachineClass: newMachineClass(providerSpec),
+						Secret:       providerSecret,
+					},
+				},
+				action: action{
+					deleteMachineRequest: &driver.DeleteMachineRequest{
+						Machine:      newMachine(0, nil),
+						MachineClass: newMachineClass([]byte(strings.Replace(string(providerSpec), `"region":`, `"newerDriverField":true,"region":`, 1))),
+						Secret:       providerSecret,
+					},
+				},
+				expect: expect{
+					deleteMachineResponse: &driver.DeleteMachineResponse{},
+					errToHaveOccurred:     false,
+				},
+			}),
+			Entry("Machine Delete Request with spotPrice conflicting with instanceMarketOptions in provider spec", &data{
+				setup: setup{
+					createMachineRequest: &driver.CreateMachineRequest{
+						Machine:      newMachine(0, nil),
+						MachineClass: newMachineClass(providerSpec),
+						Secret:       providerSecret,
+					},
+				},
+				action: action{
+					deleteMachineRequest: &driver.DeleteMachineRequest{
+						Machine:      newMachine(0, nil),
+						MachineClass: newMachineClass([]byte(strings.Replace(string(providerSpec), `"region":`, `"spotPrice":"500","instanceMarketOptions":{"marketType":"spot"},"region":`, 1))),
+						Secret:       providerSecret,
+					},
+				},
+				expect: expect{
+					deleteMachineResponse: &driver.DeleteMachineResponse{},
+					errToHaveOccurred:     false,
+				},
+			}),
 		)
 	})
 
@@ -1117,6 +1711,23 @@ var _ = Describe("MachineServer", func() {
 				},
 			}),
 		)
+
+		It("should not find shutting-down or terminated instances which are still visible by their providerID", func() {
+			mockClientProvider := &mockclient.MockClientProvider{FakeInstances: make([]ec2types.Instance, 0)}
+			md := NewAWSDriver(mockClientProvider)
+			ctx := context.Background()
+			getMachineRequest := &driver.GetMachineStatusRequest{Machine: newMachine(0, nil), MachineClass: newMachineClass(providerSpec), Secret: providerSecret}
+
+			_, err := md.CreateMachine(ctx, &driver.CreateMachineRequest{Machine: newMachine(0, nil), MachineClass: newMachineClass(providerSpec), Secret: providerSecret})
+			Expect(err).ToNot(HaveOccurred())
+
+			for _, state := range []ec2types.InstanceStateName{ec2types.InstanceStateNameShuttingDown, ec2types.InstanceStateNameTerminated} {
+				mockClientProvider.FakeInstances[0].State = &ec2types.InstanceState{Name: state}
+				_, err = md.GetMachineStatus(ctx, getMachineRequest)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("machine codes error: code = [NotFound] message = [AWS plugin is returning no VM instances backing this machine object]"))
+			}
+		})
 	})
 
 	Describe("#ListMachines", func() {
@@ -1413,6 +2024,24 @@ var _ = Describe("MachineServer", func() {
 				},
 			}),
 		)
+
+		It("should stop paginating when recorded interactions return a duplicate token", func() {
+			player, err := replay.NewPlayerFromFixture("testdata/list_machines_duplicate_token.jsonl")
+			Expect(err).ToNot(HaveOccurred())
+			md := NewAWSDriver(&replay.ClientProvider{Player: player})
+
+			listResponse, err := md.ListMachines(context.Background(), &driver.ListMachinesRequest{
+				MachineClass: newMachineClass(providerSpec),
+				Secret:       providerSecret,
+			})
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(listResponse.MachineList).To(Equal(map[string]string{
+				"aws:///eu-west-1/i-0a0a0a0a0a0a0a0a1": "machine-0",
+				"aws:///eu-west-1/i-0a0a0a0a0a0a0a0a2": "machine-1",
+			}))
+			Expect(player.Remaining()).To(Equal(0))
+		})
 	})
 
 	Describe("#GetVolumeIDs", func() {
@@ -6,18 +6,22 @@ package aws
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
 	"github.com/gardener/machine-controller-manager-provider-aws/pkg/aws/interfaces"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
 	api "github.com/gardener/machine-controller-manager-provider-aws/pkg/aws/apis"
 	validation "github.com/gardener/machine-controller-manager-provider-aws/pkg/aws/apis/validation"
 	awserror "github.com/gardener/machine-controller-manager-provider-aws/pkg/aws/errors"
+	"github.com/gardener/machine-controller-manager-provider-aws/pkg/aws/instancetypes"
 	"github.com/gardener/machine-controller-manager-provider-aws/pkg/instrument"
 	v1alpha1 "github.com/gardener/machine-controller-manager/pkg/apis/machine/v1alpha1"
 	"github.com/gardener/machine-controller-manager/pkg/util/provider/driver"
@@ -29,6 +33,9 @@ import (
 	"k8s.io/utils/ptr"
 )
 
+// hibernateStateReasonCode is the state reason of instances which were stopped with hibernation.
+const hibernateStateReasonCode = "Client.UserInitiatedHibernate"
+
 // labels used for recording prometheus metrics
 const (
 	instanceDisableSourceDestCheckServiceLabel = "instance_disable_source_dest_check"
@@ -36,21 +43,57 @@ const (
 	instanceGetByMachineServiceLabel           = "instance_get_by_machine"
 	instanceGetByIDServiceLabel                = "instance_get_by_id"
 	instanceTerminateServiceLabel              = "instance_terminate"
+	networkInterfaceGetByTagsServiceLabel      = "network_interface_get_by_tags"
+	instanceGetCPUCreditsServiceLabel          = "instance_get_cpu_credits"
+	instanceModifyCPUCreditsServiceLabel       = "instance_modify_cpu_credits"
+	instanceModifyMaintenanceServiceLabel      = "instance_modify_maintenance_options"
+	callerIdentityGetServiceLabel              = "caller_identity_get"
+	placementGroupGetPartitionsServiceLabel    = "placement_group_get_partitions"
+	instanceGetByMachineClassServiceLabel      = "instance_get_by_machine_class"
 )
 
 // decodeProviderSpecAndSecret converts request parameters to api.ProviderSpec & api.Secrets
-func decodeProviderSpecAndSecret(machineClass *v1alpha1.MachineClass, secret *corev1.Secret) (*api.AWSProviderSpec, error) {
-	var (
-		providerSpec *api.AWSProviderSpec
-	)
-
-	err := json.Unmarshal(machineClass.ProviderSpec.Raw, &providerSpec)
+// Fields of the providerSpec which are unknown to the driver are rejected with their JSON path if creating is set, i.e.
+// for new machines, unless Options.AllowUnknownProviderSpecFields is set. Deprecated fields which conflict with their
+// replacements are only rejected for new machines as well. Otherwise both are only logged, so that existing machines
+// can still be managed and deleted.
+func (d *Driver) decodeProviderSpecAndSecret(machineClass *v1alpha1.MachineClass, secret *corev1.Secret, creating bool) (*api.AWSProviderSpec, error) {
+	fldPath := field.NewPath("providerSpec")
+	providerSpec, unknownFieldErrs, caseMismatchErrs, err := validation.DecodeAWSProviderSpec(machineClass.ProviderSpec.Raw, fldPath)
 	if err != nil {
+		var fieldErr *field.Error
+		if errors.As(err, &fieldErr) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
+	if len(caseMismatchErrs) > 0 {
+		klog.Warningf("ProviderSpec of MachineClass %q contains field names with a different case, they are still decoded: %v", machineClass.Name, caseMismatchErrs.ToAggregate().Error())
+	}
+	instrument.RecordMachineClassUnknownFields(machineClass.Name, machineClass.Namespace, len(unknownFieldErrs))
+	if len(unknownFieldErrs) > 0 {
+		if creating && !d.Options.AllowUnknownProviderSpecFields {
+			err = fmt.Errorf("error while decoding ProviderSpec %v", unknownFieldErrs.ToAggregate().Error())
+			klog.V(2).Infof("Decoding of AWSMachineClass %q failed %s", machineClass.Name, err)
+
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		klog.Warningf("ProviderSpec of MachineClass %q contains fields unknown to the driver, they are ignored: %v", machineClass.Name, unknownFieldErrs.ToAggregate().Error())
+	}
+
+	deprecations.observe(featureSpotPrice, machineClass, providerSpec.SpotPrice != nil)
+	if deprecationErrs := validation.ValidateDeprecatedFields(providerSpec, fldPath); len(deprecationErrs) > 0 {
+		if creating {
+			err = fmt.Errorf("error while validating ProviderSpec %v", deprecationErrs.ToAggregate().Error())
+			klog.V(2).Infof("Validation of AWSMachineClass %q failed %s", machineClass.Name, err)
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		klog.Warningf("ProviderSpec of MachineClass %q uses deprecated fields which conflict with their replacements: %v", machineClass.Name, deprecationErrs.ToAggregate().Error())
+	}
+
 	// Validate the Spec and Secrets
-	validationErr := validation.ValidateAWSProviderSpec(providerSpec, secret, field.NewPath("providerSpec"))
+	validationErr := validation.ValidateAWSProviderSpec(providerSpec, secret, fldPath)
 	if validationErr.ToAggregate() != nil && len(validationErr.ToAggregate().Errors()) > 0 {
 		err = fmt.Errorf("error while validating ProviderSpec %v", validationErr.ToAggregate().Error())
 		klog.V(2).Infof("Validation of AWSMachineClass failed %s", err)
@@ -188,7 +231,13 @@ func (d *Driver) getMatchingInstancesForMachine(ctx context.Context, machine *v1
 			return nil, err
 		}
 		for _, reservation := range runResult.Reservations {
-			instances = append(instances, reservation.Instances...)
+			for _, instance := range reservation.Instances {
+				// Terminated instances remain visible for a while, but no longer back the machine
+				if instance.State != nil && (instance.State.Name == ec2types.InstanceStateNameShuttingDown || instance.State.Name == ec2types.InstanceStateNameTerminated) {
+					continue
+				}
+				instances = append(instances, instance)
+			}
 		}
 		if len(instances) == 0 {
 			errMessage := "AWS plugin is returning no VM instances backing this machine object"
@@ -263,6 +312,45 @@ func (d *Driver) generateBlockDevices(blockDevices []api.AWSBlockDeviceMappingSp
 	return blkDeviceMappings, nil
 }
 
+// expandEFANetworkInterfaces returns the network interfaces requested by providerSpec.EFA for the machine type: the
+// single network interface of the providerSpec as "efa" interface on network card 0, and one interface on device index
+// 1 of every further network card using the subnet and security groups of the primary interface. The number of
+// interfaces is limited by the maximum number of EFA interfaces of the machine type.
+func expandEFANetworkInterfaces(providerSpec *api.AWSProviderSpec, info *instancetypes.Info) ([]api.AWSNetworkInterfaceSpec, error) {
+	if info == nil {
+		return nil, fmt.Errorf("efa requires the network cards of machine type %s, which is unknown", providerSpec.MachineType)
+	}
+	if !info.EfaSupported {
+		return nil, fmt.Errorf("efa is set, but machine type %s does not support Elastic Fabric Adapters", providerSpec.MachineType)
+	}
+
+	primary := providerSpec.NetworkInterfaces[0]
+	primary.InterfaceType = aws.String(string(ec2types.NetworkInterfaceTypeEfa))
+	primary.NetworkCardIndex = aws.Int32(0)
+	primary.DeviceIndex = aws.Int32(0)
+	networkInterfaces := []api.AWSNetworkInterfaceSpec{primary}
+
+	interfaceType := ptr.Deref(providerSpec.EFA.InterfaceType, string(ec2types.NetworkInterfaceTypeEfaOnly))
+	for _, card := range info.NetworkCards {
+		// #nosec: G115 -- number of network interfaces will not exceed int32 limits
+		if info.MaximumEfaInterfaces > 0 && int32(len(networkInterfaces)) >= info.MaximumEfaInterfaces {
+			break
+		}
+		if card.NetworkCardIndex == 0 {
+			continue
+		}
+		networkInterfaces = append(networkInterfaces, api.AWSNetworkInterfaceSpec{
+			SubnetID:            primary.SubnetID,
+			SecurityGroupIDs:    primary.SecurityGroupIDs,
+			DeleteOnTermination: aws.Bool(true),
+			InterfaceType:       aws.String(interfaceType),
+			NetworkCardIndex:    aws.Int32(card.NetworkCardIndex),
+			DeviceIndex:         aws.Int32(1),
+		})
+	}
+	return networkInterfaces, nil
+}
+
 func (d *Driver) generateTags(tags map[string]string, resourceType string, machineName string) (ec2types.TagSpecification, error) {
 
 	// Add tags to the created machine
@@ -319,3 +407,320 @@ func terminateInstance(ctx context.Context, req *driver.DeleteMachineRequest, sv
 
 	return nil
 }
+
+// countSecondaryPrivateIPAddresses returns the number of private IPv4 addresses of the network interface which are not
+// its primary address.
+func countSecondaryPrivateIPAddresses(netIf ec2types.InstanceNetworkInterface) int32 {
+	var count int32
+	for _, address := range netIf.PrivateIpAddresses {
+		if !ptr.Deref(address.Primary, false) {
+			count++
+		}
+	}
+	return count
+}
+
+// hostResourceGroupARN expands the name of a host resource group into its ARN, which RunInstances requires. The group
+// is looked up in the account of the caller.
+func hostResourceGroupARN(ctx context.Context, svc interfaces.StsClient, region, name string) (groupARN string, err error) {
+	defer instrument.AwsAPIMetricRecorderFn(callerIdentityGetServiceLabel, &err)()
+
+	output, err := svc.GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+	if err != nil {
+		return "", status.Error(codes.Internal, fmt.Sprintf("could not determine the account of host resource group %q: %v", name, err))
+	}
+	if ptr.Deref(output.Account, "") == "" {
+		return "", status.Error(codes.Internal, fmt.Sprintf("could not determine the account of host resource group %q", name))
+	}
+
+	return arn.ARN{
+		Partition: partitionForRegion(region),
+		Service:   "resource-groups",
+		Region:    region,
+		AccountID: *output.Account,
+		Resource:  "group/" + name,
+	}.String(), nil
+}
+
+// choosePlacementPartition returns the partition of the placement group with the fewest instances of the MachineClass,
+// preferring lower partition numbers, so that the machines are spread round-robin across the partitions. The spreading
+// is best-effort: the instances are counted without any lock, so machines which are created concurrently, e.g. by a
+// scale-up, may see the same counts and choose the same partition. Subsequent machines even out such imbalances.
+func choosePlacementPartition(ctx context.Context, svc interfaces.Ec2Client, providerSpec *api.AWSProviderSpec, machineClassName string) (int32, error) {
+	group, err := describePlacementGroup(ctx, svc, providerSpec.Placement)
+	if err != nil {
+		return 0, err
+	}
+	if group.Strategy != ec2types.PlacementStrategyPartition || ptr.Deref(group.PartitionCount, 0) < 1 {
+		return 0, status.Error(codes.InvalidArgument, fmt.Sprintf("placement group %s has the strategy %q, spreading partitions requires the %q strategy",
+			ptr.Deref(group.GroupName, ""), group.Strategy, ec2types.PlacementStrategyPartition))
+	}
+
+	instances, err := getMachineClassInstances(ctx, svc, machineClassName, providerSpec.Tags)
+	if err != nil {
+		return 0, err
+	}
+	counts := make([]int, *group.PartitionCount)
+	for _, instance := range instances {
+		for _, tag := range instance.Tags {
+			if ptr.Deref(tag.Key, "") != placementPartitionTagKey {
+				continue
+			}
+			if partition, err := strconv.Atoi(ptr.Deref(tag.Value, "")); err == nil && partition >= 1 && partition <= len(counts) {
+				counts[partition-1]++
+			}
+		}
+	}
+
+	chosen := 0
+	for i, count := range counts {
+		if count < counts[chosen] {
+			chosen = i
+		}
+	}
+	klog.V(3).Infof("Chose partition %d of placement group %s for MachineClass %q with partition counts %v", chosen+1, ptr.Deref(group.GroupName, ""), machineClassName, counts)
+	return int32(chosen + 1), nil // #nosec: G115 -- partition count will not exceed int32 limits
+}
+
+// describePlacementGroup returns the placement group referenced by the placement.
+func describePlacementGroup(ctx context.Context, svc interfaces.Ec2Client, placement *api.AWSPlacementSpec) (group ec2types.PlacementGroup, err error) {
+	defer instrument.AwsAPIMetricRecorderFn(placementGroupGetPartitionsServiceLabel, &err)()
+
+	input := &ec2.DescribePlacementGroupsInput{}
+	if placement.GroupID != nil {
+		input.GroupIds = []string{*placement.GroupID}
+	} else {
+		input.GroupNames = []string{ptr.Deref(placement.GroupName, "")}
+	}
+	output, err := svc.DescribePlacementGroups(ctx, input)
+	if awserror.HasErrorCode(err, awserror.InvalidPlacementGroupUnknown) {
+		return group, status.Error(codes.InvalidArgument, err.Error())
+	} else if err != nil {
+		return group, status.Error(codes.Internal, err.Error())
+	}
+	if len(output.PlacementGroups) == 0 {
+		return group, status.Error(codes.InvalidArgument, fmt.Sprintf("placement group %s does not exist", ptr.Deref(placement.GroupID, ptr.Deref(placement.GroupName, ""))))
+	}
+	return output.PlacementGroups[0], nil
+}
+
+// getMachineClassInstances returns the instances which were tagged with the MachineClass when their partition was
+// chosen.
+func getMachineClassInstances(ctx context.Context, svc interfaces.Ec2Client, machineClassName string, providerSpecTags map[string]string) (instances []ec2types.Instance, err error) {
+	defer instrument.AwsAPIMetricRecorderFn(instanceGetByMachineClassServiceLabel, &err)()
+
+	filters := []ec2types.Filter{
+		{
+			Name:   aws.String("tag:" + machineClassTagKey),
+			Values: []string{machineClassName},
+		},
+		{
+			Name: aws.String("instance-state-name"),
+			Values: []string{
+				string(ec2types.InstanceStateNamePending),
+				string(ec2types.InstanceStateNameRunning),
+				string(ec2types.InstanceStateNameStopping),
+				string(ec2types.InstanceStateNameStopped),
+			},
+		},
+	}
+	for key := range providerSpecTags {
+		if strings.Contains(key, "kubernetes.io/cluster/") || strings.Contains(key, "kubernetes.io/role/") {
+			filters = append(filters, ec2types.Filter{Name: aws.String("tag-key"), Values: []string{key}})
+		}
+	}
+
+	paginator := ec2.NewDescribeInstancesPaginator(svc, &ec2.DescribeInstancesInput{Filters: filters}, func(opt *ec2.DescribeInstancesPaginatorOptions) {
+		opt.StopOnDuplicateToken = true
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		for _, reservation := range page.Reservations {
+			instances = append(instances, reservation.Instances...)
+		}
+	}
+	return instances, nil
+}
+
+// partitionForRegion returns the ARN partition of the region.
+func partitionForRegion(region string) string {
+	switch {
+	case strings.HasPrefix(region, "cn-"):
+		return "aws-cn"
+	case strings.HasPrefix(region, "us-gov-"):
+		return "aws-us-gov"
+	default:
+		return "aws"
+	}
+}
+
+// getInstanceCPUCredits returns the credit option for CPU usage of the instance, or an empty string if the instance is
+// not a burstable performance instance.
+func getInstanceCPUCredits(ctx context.Context, svc interfaces.Ec2Client, instanceID string) (cpuCredits string, err error) {
+	defer instrument.AwsAPIMetricRecorderFn(instanceGetCPUCreditsServiceLabel, &err)()
+
+	output, err := svc.DescribeInstanceCreditSpecifications(ctx, &ec2.DescribeInstanceCreditSpecificationsInput{
+		InstanceIds: []string{instanceID},
+	})
+	if err != nil {
+		return "", err
+	}
+	for _, spec := range output.InstanceCreditSpecifications {
+		if ptr.Deref(spec.InstanceId, "") == instanceID {
+			return ptr.Deref(spec.CpuCredits, ""), nil
+		}
+	}
+	return "", nil
+}
+
+// modifyInstanceCPUCredits sets the credit option for CPU usage of the instance.
+func modifyInstanceCPUCredits(ctx context.Context, svc interfaces.Ec2Client, instanceID, cpuCredits string) (err error) {
+	defer instrument.AwsAPIMetricRecorderFn(instanceModifyCPUCreditsServiceLabel, &err)()
+
+	output, err := svc.ModifyInstanceCreditSpecification(ctx, &ec2.ModifyInstanceCreditSpecificationInput{
+		InstanceCreditSpecifications: []ec2types.InstanceCreditSpecificationRequest{{
+			InstanceId: aws.String(instanceID),
+			CpuCredits: aws.String(cpuCredits),
+		}},
+	})
+	if err != nil {
+		return err
+	}
+	for _, item := range output.UnsuccessfulInstanceCreditSpecifications {
+		if item.Error != nil {
+			return fmt.Errorf("could not modify the CPU credits of instance %s: %s: %s", instanceID, item.Error.Code, ptr.Deref(item.Error.Message, ""))
+		}
+	}
+	return nil
+}
+
+// modifyInstanceRebootMigration sets the reboot migration of the instance, which cannot be requested at launch.
+func modifyInstanceRebootMigration(ctx context.Context, svc interfaces.Ec2Client, instanceID, rebootMigration string) (err error) {
+	defer instrument.AwsAPIMetricRecorderFn(instanceModifyMaintenanceServiceLabel, &err)()
+
+	_, err = svc.ModifyInstanceMaintenanceOptions(ctx, &ec2.ModifyInstanceMaintenanceOptionsInput{
+		InstanceId:      aws.String(instanceID),
+		RebootMigration: ec2types.InstanceRebootMigrationState(rebootMigration),
+	})
+	return err
+}
+
+// rebootMigrationDrifted returns true if the instance reports another reboot migration than configured. Instances which
+// do not report their reboot migration are not considered drifted.
+func rebootMigrationDrifted(instance *ec2types.Instance, maintenanceOptions *api.AWSMaintenanceOptions) bool {
+	if maintenanceOptions == nil || maintenanceOptions.RebootMigration == nil || instance.MaintenanceOptions == nil {
+		return false
+	}
+	actual := string(instance.MaintenanceOptions.RebootMigration)
+	return actual != "" && actual != *maintenanceOptions.RebootMigration
+}
+
+// validateImageBootOptions makes sure that the AMI boots the instance with the boot mode and the NitroTPM required by
+// the providerSpec. AMIs without a boot mode boot with the default boot mode of the machine type, so they are not
+// rejected, and "uefi-preferred" AMIs boot with either boot mode.
+func validateImageBootOptions(image *ec2types.Image, providerSpec *api.AWSProviderSpec) error {
+	imageID := ptr.Deref(image.ImageId, providerSpec.AMI)
+	if providerSpec.BootMode != nil && image.BootMode != "" && image.BootMode != ec2types.BootModeValuesUefiPreferred &&
+		string(image.BootMode) != *providerSpec.BootMode {
+		return fmt.Errorf("AMI %s has the boot mode %q, but providerSpec.BootMode is %q", imageID, image.BootMode, *providerSpec.BootMode)
+	}
+	if providerSpec.TPMSupport != nil && string(image.TpmSupport) != *providerSpec.TPMSupport {
+		return fmt.Errorf("AMI %s has the tpmSupport %q, but providerSpec.TPMSupport is %q", imageID, image.TpmSupport, *providerSpec.TPMSupport)
+	}
+	return nil
+}
+
+// stoppedInstanceMessage describes why the instance is not running, if it is stopping or stopped, and returns an empty
+// string otherwise.
+func stoppedInstanceMessage(instance *ec2types.Instance) string {
+	if instance.State == nil || (instance.State.Name != ec2types.InstanceStateNameStopping && instance.State.Name != ec2types.InstanceStateNameStopped) {
+		return ""
+	}
+	if instance.StateReason != nil && ptr.Deref(instance.StateReason.Code, "") == hibernateStateReasonCode {
+		if instance.State.Name == ec2types.InstanceStateNameStopping {
+			return "is hibernating"
+		}
+		return "is hibernated"
+	}
+	return fmt.Sprintf("is %s", instance.State.Name)
+}
+
+// instanceNodeName returns the name of the node of the instance, which is its private DNS name. Instances with
+// resource-name hostnames, e.g. in IPv6-only subnets, may not expose a private DNS name, then the name is derived from
+// the instance ID like the hostname of the instance.
+func instanceNodeName(instance *ec2types.Instance, region string, dnsOptions *api.AWSPrivateDNSNameOptions) string {
+	if name := ptr.Deref(instance.PrivateDnsName, ""); name != "" {
+		return name
+	}
+
+	var hostnameType string
+	if instance.PrivateDnsNameOptions != nil {
+		hostnameType = string(instance.PrivateDnsNameOptions.HostnameType)
+	}
+	if hostnameType == "" && dnsOptions != nil {
+		hostnameType = ptr.Deref(dnsOptions.HostnameType, "")
+	}
+	if hostnameType != string(ec2types.HostnameTypeResourceName) || instance.InstanceId == nil {
+		return ""
+	}
+	if region == "us-east-1" {
+		return *instance.InstanceId + ".ec2.internal"
+	}
+	return fmt.Sprintf("%s.%s.compute.internal", *instance.InstanceId, region)
+}
+
+// resolveNetworkInterfaceTags sets the ID of every network interface selected by NetworkInterfaceTags to the first
+// available network interface carrying all of the tags. An interface is selected for one network interface spec only.
+func resolveNetworkInterfaceTags(ctx context.Context, svc interfaces.Ec2Client, networkInterfaces []api.AWSNetworkInterfaceSpec) (err error) {
+	selected := map[string]struct{}{}
+	for i := range networkInterfaces {
+		if networkInterfaces[i].NetworkInterfaceID != nil {
+			selected[*networkInterfaces[i].NetworkInterfaceID] = struct{}{}
+		}
+	}
+
+	for i := range networkInterfaces {
+		netIf := &networkInterfaces[i]
+		if netIf.NetworkInterfaceID != nil || len(netIf.NetworkInterfaceTags) == 0 {
+			continue
+		}
+
+		networkInterfaceID, err := getAvailableNetworkInterfaceByTags(ctx, svc, netIf.NetworkInterfaceTags, selected)
+		if err != nil {
+			return status.Error(codes.Internal, err.Error())
+		}
+		if networkInterfaceID == "" {
+			// The network interface might still be attached to the terminating instance of a replaced machine
+			return status.Error(codes.ResourceExhausted, fmt.Sprintf("no available network interface with tags %v found for providerSpec.networkInterfaces[%d]", netIf.NetworkInterfaceTags, i))
+		}
+		klog.V(3).Infof("Selected network interface %q with tags %v for providerSpec.networkInterfaces[%d]", networkInterfaceID, netIf.NetworkInterfaceTags, i)
+		netIf.NetworkInterfaceID = aws.String(networkInterfaceID)
+		selected[networkInterfaceID] = struct{}{}
+	}
+	return nil
+}
+
+func getAvailableNetworkInterfaceByTags(ctx context.Context, svc interfaces.Ec2Client, tags map[string]string, excluded map[string]struct{}) (networkInterfaceID string, err error) {
+	defer instrument.AwsAPIMetricRecorderFn(networkInterfaceGetByTagsServiceLabel, &err)()
+
+	filters := []ec2types.Filter{{
+		Name:   aws.String("status"),
+		Values: []string{string(ec2types.NetworkInterfaceStatusAvailable)},
+	}}
+	for key, value := range tags {
+		filters = append(filters, ec2types.Filter{Name: aws.String("tag:" + key), Values: []string{value}})
+	}
+	output, err := svc.DescribeNetworkInterfaces(ctx, &ec2.DescribeNetworkInterfacesInput{Filters: filters})
+	if err != nil {
+		return "", err
+	}
+	for _, networkInterface := range output.NetworkInterfaces {
+		if _, ok := excluded[ptr.Deref(networkInterface.NetworkInterfaceId, "")]; !ok {
+			return ptr.Deref(networkInterface.NetworkInterfaceId, ""), nil
+		}
+	}
+	return "", nil
+}
@@ -15,6 +15,7 @@ import (
 	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
 
 	api "github.com/gardener/machine-controller-manager-provider-aws/pkg/aws/apis"
+	"github.com/gardener/machine-controller-manager-provider-aws/pkg/aws/instancetypes"
 	"github.com/gardener/machine-controller-manager-provider-aws/pkg/aws/interfaces"
 	"github.com/gardener/machine-controller-manager-provider-aws/pkg/mockclient"
 )
@@ -274,6 +275,57 @@ var _ = Describe("CoreUtils", func() {
 		})
 	})
 
+	Context("#expandEFANetworkInterfaces", func() {
+		newProviderSpec := func(machineType string, efa *api.AWSEFASpec) *api.AWSProviderSpec {
+			return &api.AWSProviderSpec{
+				MachineType:       machineType,
+				NetworkInterfaces: []api.AWSNetworkInterfaceSpec{{SubnetID: "subnet-1", SecurityGroupIDs: []string{"sg-1"}, Ipv6AddressCount: aws.Int32(1)}},
+				EFA:               efa,
+			}
+		}
+
+		It("should add an efa-only interface on every further network card", func() {
+			networkInterfaces, err := expandEFANetworkInterfaces(newProviderSpec("p4d.24xlarge", &api.AWSEFASpec{}), instancetypes.Snapshot("p4d.24xlarge"))
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(networkInterfaces).To(Equal([]api.AWSNetworkInterfaceSpec{
+				{SubnetID: "subnet-1", SecurityGroupIDs: []string{"sg-1"}, Ipv6AddressCount: aws.Int32(1), InterfaceType: aws.String("efa"), NetworkCardIndex: aws.Int32(0), DeviceIndex: aws.Int32(0)},
+				{SubnetID: "subnet-1", SecurityGroupIDs: []string{"sg-1"}, DeleteOnTermination: aws.Bool(true), InterfaceType: aws.String("efa-only"), NetworkCardIndex: aws.Int32(1), DeviceIndex: aws.Int32(1)},
+				{SubnetID: "subnet-1", SecurityGroupIDs: []string{"sg-1"}, DeleteOnTermination: aws.Bool(true), InterfaceType: aws.String("efa-only"), NetworkCardIndex: aws.Int32(2), DeviceIndex: aws.Int32(1)},
+				{SubnetID: "subnet-1", SecurityGroupIDs: []string{"sg-1"}, DeleteOnTermination: aws.Bool(true), InterfaceType: aws.String("efa-only"), NetworkCardIndex: aws.Int32(3), DeviceIndex: aws.Int32(1)},
+			}))
+		})
+
+		It("should use the configured interface type for all 32 network cards of a p5.48xlarge", func() {
+			networkInterfaces, err := expandEFANetworkInterfaces(newProviderSpec("p5.48xlarge", &api.AWSEFASpec{InterfaceType: aws.String("efa")}), instancetypes.Snapshot("p5.48xlarge"))
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(networkInterfaces).To(HaveLen(32))
+			for i, networkInterface := range networkInterfaces {
+				Expect(*networkInterface.InterfaceType).To(Equal("efa"))
+				Expect(*networkInterface.NetworkCardIndex).To(Equal(int32(i)))
+			}
+		})
+
+		It("should be limited by the maximum number of EFA interfaces", func() {
+			info := *instancetypes.Snapshot("p4d.24xlarge")
+			info.MaximumEfaInterfaces = 2
+
+			networkInterfaces, err := expandEFANetworkInterfaces(newProviderSpec("p4d.24xlarge", &api.AWSEFASpec{}), &info)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(networkInterfaces).To(HaveLen(2))
+		})
+
+		It("should fail for machine types without EFA support", func() {
+			_, err := expandEFANetworkInterfaces(newProviderSpec("m5.large", &api.AWSEFASpec{}), instancetypes.Snapshot("m5.large"))
+			Expect(err).To(MatchError("efa is set, but machine type m5.large does not support Elastic Fabric Adapters"))
+
+			_, err = expandEFANetworkInterfaces(newProviderSpec("unknown.large", &api.AWSEFASpec{}), nil)
+			Expect(err).To(MatchError("efa requires the network cards of machine type unknown.large, which is unknown"))
+		})
+	})
+
 	Context("#getMachineInstancesByTagsAndStatus", func() {
 		var (
 			ctx                context.Context
@@ -402,4 +454,28 @@ var _ = Describe("CoreUtils", func() {
 			Expect(*instances[1].InstanceId).To(Equal("i-test-instance-2"))
 		})
 	})
+
+	Context("#instanceNodeName", func() {
+		resourceName := &api.AWSPrivateDNSNameOptions{HostnameType: aws.String("resource-name")}
+
+		It("should use the private DNS name of the instance", func() {
+			instance := &ec2types.Instance{InstanceId: aws.String("i-1"), PrivateDnsName: aws.String("ip-10-0-1-5.eu-west-1.compute.internal")}
+			Expect(instanceNodeName(instance, "eu-west-1", resourceName)).To(Equal("ip-10-0-1-5.eu-west-1.compute.internal"))
+		})
+
+		It("should derive the resource name if the instance has no private DNS name", func() {
+			instance := &ec2types.Instance{InstanceId: aws.String("i-1")}
+			Expect(instanceNodeName(instance, "eu-west-1", resourceName)).To(Equal("i-1.eu-west-1.compute.internal"))
+			Expect(instanceNodeName(instance, "us-east-1", resourceName)).To(Equal("i-1.ec2.internal"))
+
+			instance.PrivateDnsNameOptions = &ec2types.PrivateDnsNameOptionsResponse{HostnameType: ec2types.HostnameTypeResourceName}
+			Expect(instanceNodeName(instance, "eu-west-1", nil)).To(Equal("i-1.eu-west-1.compute.internal"))
+		})
+
+		It("should not derive a name for ip-name hostnames", func() {
+			instance := &ec2types.Instance{InstanceId: aws.String("i-1")}
+			Expect(instanceNodeName(instance, "eu-west-1", nil)).To(BeEmpty())
+			Expect(instanceNodeName(instance, "eu-west-1", &api.AWSPrivateDNSNameOptions{HostnameType: aws.String("ip-name")})).To(BeEmpty())
+		})
+	})
 })
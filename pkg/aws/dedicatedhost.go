@@ -0,0 +1,339 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package aws
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/gardener/machine-controller-manager/pkg/util/provider/machinecodes/codes"
+	"github.com/gardener/machine-controller-manager/pkg/util/provider/machinecodes/status"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
+
+	api "github.com/gardener/machine-controller-manager-provider-aws/pkg/aws/apis"
+	awserror "github.com/gardener/machine-controller-manager-provider-aws/pkg/aws/errors"
+	"github.com/gardener/machine-controller-manager-provider-aws/pkg/aws/interfaces"
+	"github.com/gardener/machine-controller-manager-provider-aws/pkg/instrument"
+)
+
+const (
+	dedicatedHostSelectServiceLabel   = "dedicated_host_select"
+	dedicatedHostAllocateServiceLabel = "dedicated_host_allocate"
+	dedicatedHostReleaseServiceLabel  = "dedicated_host_release"
+
+	resourceTypeDedicatedHost = "dedicated-host"
+
+	// dedicatedHostAllocatedTagKey marks Dedicated Hosts which have been allocated for machines and are released once
+	// they are empty, in contrast to hosts which have been allocated otherwise.
+	dedicatedHostAllocatedTagKey = "machine.sapcloud.io/dedicated-host-allocated"
+	// dedicatedHostHoldUntilTagKey is the tag key of the time until which an allocated host is not released, if
+	// providerSpec.placement.dedicatedHosts.minimumHoldDuration was set when it was allocated.
+	dedicatedHostHoldUntilTagKey = "machine.sapcloud.io/dedicated-host-hold-until"
+)
+
+// chooseDedicatedHost returns the ID of the Dedicated Host of the cluster with the least free capacity for the machine
+// type in the availability zone of the machine. If no host has free capacity, a new host is allocated if
+// providerSpec.placement.dedicatedHosts.allocate is set, and allocated is true.
+func (d *Driver) chooseDedicatedHost(ctx context.Context, client interfaces.Ec2Client, providerSpec *api.AWSProviderSpec, machineName string) (hostID string, allocated bool, err error) {
+	availabilityZone, err := machineAvailabilityZone(ctx, client, providerSpec)
+	if err != nil {
+		return "", false, err
+	}
+
+	hosts, err := describeClusterHosts(ctx, client, providerSpec.Tags, ec2types.Filter{Name: aws.String("availability-zone"), Values: []string{availabilityZone}})
+	if err != nil {
+		return "", false, status.Error(codes.Internal, err.Error())
+	}
+
+	var minCapacity int32
+	for _, host := range hosts {
+		capacity := availableHostCapacity(host, providerSpec.MachineType)
+		if capacity < 1 {
+			continue
+		}
+		if hostID == "" || capacity < minCapacity || (capacity == minCapacity && ptr.Deref(host.HostId, "") < hostID) {
+			hostID, minCapacity = ptr.Deref(host.HostId, ""), capacity
+		}
+	}
+	if hostID != "" {
+		klog.V(3).Infof("Chose Dedicated Host %s with capacity for %d more instances of type %s for machine %q", hostID, minCapacity, providerSpec.MachineType, machineName)
+		return hostID, false, nil
+	}
+
+	msg := fmt.Sprintf("no Dedicated Host of the cluster has free capacity for machine type %s in availability zone %s", providerSpec.MachineType, availabilityZone)
+	if !providerSpec.Placement.DedicatedHosts.Allocate {
+		return "", false, status.Error(codes.ResourceExhausted, msg)
+	}
+	klog.V(3).Infof("%s, allocating a new host for machine %q", msg, machineName)
+	hostID, err = d.allocateDedicatedHost(ctx, client, providerSpec, availabilityZone)
+	if err != nil {
+		return "", false, err
+	}
+	return hostID, true, nil
+}
+
+// allocateDedicatedHost allocates a new Dedicated Host for the machine type, which is released once it is empty.
+func (d *Driver) allocateDedicatedHost(ctx context.Context, client interfaces.Ec2Client, providerSpec *api.AWSProviderSpec, availabilityZone string) (hostID string, err error) {
+	defer instrument.AwsAPIMetricRecorderFn(dedicatedHostAllocateServiceLabel, &err)()
+
+	tagSpec, err := d.generateTags(providerSpec.Tags, resourceTypeDedicatedHost, "")
+	if err != nil {
+		return "", status.Error(codes.Internal, err.Error())
+	}
+	// The host is shared by several machines, so it is not named after the machine it is allocated for
+	tagSpec.Tags = slices.DeleteFunc(tagSpec.Tags, func(tag ec2types.Tag) bool { return ptr.Deref(tag.Key, "") == "Name" })
+	tagSpec.Tags = append(tagSpec.Tags, ec2types.Tag{Key: aws.String(dedicatedHostAllocatedTagKey), Value: aws.String("true")})
+	if holdDuration := providerSpec.Placement.DedicatedHosts.MinimumHoldDuration; holdDuration != nil {
+		// The duration has already been validated with the providerSpec
+		duration, err := time.ParseDuration(*holdDuration)
+		if err != nil {
+			return "", status.Error(codes.InvalidArgument, err.Error())
+		}
+		holdUntil := time.Now().Add(duration).UTC().Format(time.RFC3339)
+		tagSpec.Tags = append(tagSpec.Tags, ec2types.Tag{Key: aws.String(dedicatedHostHoldUntilTagKey), Value: aws.String(holdUntil)})
+	}
+
+	output, err := client.AllocateHosts(ctx, &ec2.AllocateHostsInput{
+		AvailabilityZone: aws.String(availabilityZone),
+		InstanceType:     aws.String(providerSpec.MachineType),
+		Quantity:         aws.Int32(1),
+		// Only instances which target the host are placed on it, so that it can be released once they are deleted
+		AutoPlacement:     ec2types.AutoPlacementOff,
+		TagSpecifications: []ec2types.TagSpecification{tagSpec},
+	})
+	if err != nil {
+		return "", status.Error(awserror.GetMCMErrorCodeForCreateMachine(err), err.Error())
+	}
+	if len(output.HostIds) == 0 {
+		return "", status.Error(codes.Internal, fmt.Sprintf("no Dedicated Host has been allocated for machine type %s in availability zone %s", providerSpec.MachineType, availabilityZone))
+	}
+	klog.V(2).Infof("Allocated Dedicated Host %s for machine type %s in availability zone %s", output.HostIds[0], providerSpec.MachineType, availabilityZone)
+	return output.HostIds[0], nil
+}
+
+// releaseAllocatedDedicatedHost releases a Dedicated Host which has just been allocated for a machine whose instance
+// could not be launched, unless providerSpec.placement.dedicatedHosts.minimumHoldDuration is set, in which case the
+// empty host is kept for the next attempt. Failures are only logged, as the empty host is also released by a later
+// deletion.
+func releaseAllocatedDedicatedHost(ctx context.Context, client interfaces.Ec2Client, providerSpec *api.AWSProviderSpec, hostID string) {
+	if providerSpec.Placement.DedicatedHosts.MinimumHoldDuration != nil {
+		klog.V(3).Infof("Keeping allocated Dedicated Host %s for the next launch during its minimum hold", hostID)
+		return
+	}
+
+	var err error
+	defer instrument.AwsAPIMetricRecorderFn(dedicatedHostReleaseServiceLabel, &err)()
+
+	output, err := client.ReleaseHosts(ctx, &ec2.ReleaseHostsInput{HostIds: []string{hostID}})
+	if err != nil {
+		klog.Warningf("Dedicated Host %s could not be released after the launch failed, it is released by a later deletion: %v", hostID, err)
+		return
+	}
+	for _, item := range output.Unsuccessful {
+		var code, message string
+		if item.Error != nil {
+			code, message = ptr.Deref(item.Error.Code, ""), ptr.Deref(item.Error.Message, "")
+		}
+		klog.Warningf("Dedicated Host %s could not be released after the launch failed, it is released by a later deletion: %s %s", hostID, code, message)
+	}
+	if len(output.Successful) > 0 {
+		klog.V(2).Infof("Released Dedicated Host %s after the launch failed", hostID)
+	}
+}
+
+// releaseEmptyDedicatedHosts releases the allocated Dedicated Hosts of the cluster which have no instances left and
+// whose minimum hold has passed. AWS only releases a host once its instances have been shut down, so if hosts are only
+// held by instances of the machine which are still shutting down, a retryable error is returned until they can be
+// released. Otherwise the last deletion on a host would leave it allocated.
+func releaseEmptyDedicatedHosts(ctx context.Context, client interfaces.Ec2Client, providerSpecTags map[string]string, machineName string) error {
+	hosts, err := describeClusterHosts(ctx, client, providerSpecTags, ec2types.Filter{Name: aws.String("tag:" + dedicatedHostAllocatedTagKey), Values: []string{"true"}})
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	var (
+		hostIDs           []string
+		occupiedHosts     []ec2types.Host
+		shuttingDownIDs   []string
+		waitingForHostIDs []string
+	)
+	for _, host := range hosts {
+		hostID := ptr.Deref(host.HostId, "")
+		if holdUntil := hostHoldUntil(host); time.Now().Before(holdUntil) {
+			klog.V(3).Infof("Keeping empty Dedicated Host %s until %s", hostID, holdUntil.Format(time.RFC3339))
+			continue
+		}
+		if len(host.Instances) == 0 {
+			hostIDs = append(hostIDs, hostID)
+		} else {
+			occupiedHosts = append(occupiedHosts, host)
+		}
+	}
+	if len(occupiedHosts) > 0 {
+		if shuttingDownIDs, err = getShuttingDownMachineInstanceIDs(ctx, client, machineName, providerSpecTags); err != nil {
+			return status.Error(codes.Internal, err.Error())
+		}
+	}
+	for _, host := range occupiedHosts {
+		if len(shuttingDownIDs) > 0 && !slices.ContainsFunc(host.Instances, func(instance ec2types.HostInstance) bool {
+			return !slices.Contains(shuttingDownIDs, ptr.Deref(instance.InstanceId, ""))
+		}) {
+			waitingForHostIDs = append(waitingForHostIDs, ptr.Deref(host.HostId, ""))
+		}
+	}
+
+	if len(hostIDs) > 0 {
+		if err = releaseHosts(ctx, client, hostIDs); err != nil {
+			return status.Error(codes.Internal, err.Error())
+		}
+	}
+	if len(waitingForHostIDs) > 0 {
+		return status.Error(codes.Unavailable, fmt.Sprintf("Dedicated Hosts %v can only be released once the instances of machine %q have been shut down", waitingForHostIDs, machineName))
+	}
+	return nil
+}
+
+// releaseHosts releases the given Dedicated Hosts. Hosts which cannot be released are only logged, as they are
+// released by a later deletion.
+func releaseHosts(ctx context.Context, client interfaces.Ec2Client, hostIDs []string) (err error) {
+	defer instrument.AwsAPIMetricRecorderFn(dedicatedHostReleaseServiceLabel, &err)()
+
+	output, err := client.ReleaseHosts(ctx, &ec2.ReleaseHostsInput{HostIds: hostIDs})
+	if err != nil {
+		return err
+	}
+	if len(output.Successful) > 0 {
+		klog.V(2).Infof("Released empty Dedicated Hosts %v", output.Successful)
+	}
+	for _, item := range output.Unsuccessful {
+		var code, message string
+		if item.Error != nil {
+			code, message = ptr.Deref(item.Error.Code, ""), ptr.Deref(item.Error.Message, "")
+		}
+		klog.Warningf("Dedicated Host %s could not be released, it is released by a later deletion: %s %s", ptr.Deref(item.ResourceId, ""), code, message)
+	}
+	return nil
+}
+
+// getShuttingDownMachineInstanceIDs returns the IDs of the instances of the machine which have been terminated but may
+// still occupy their Dedicated Host.
+func getShuttingDownMachineInstanceIDs(ctx context.Context, client interfaces.Ec2Client, machineName string, providerSpecTags map[string]string) (instanceIDs []string, err error) {
+	defer instrument.AwsAPIMetricRecorderFn(instanceGetByTagsAndStatusServiceLabel, &err)()
+
+	filters := []ec2types.Filter{
+		{Name: aws.String("tag:Name"), Values: []string{machineName}},
+		{Name: aws.String("instance-state-name"), Values: []string{string(ec2types.InstanceStateNameShuttingDown), string(ec2types.InstanceStateNameTerminated)}},
+	}
+	for key := range providerSpecTags {
+		if strings.Contains(key, "kubernetes.io/cluster/") {
+			filters = append(filters, ec2types.Filter{Name: aws.String("tag-key"), Values: []string{key}})
+		}
+	}
+
+	paginator := ec2.NewDescribeInstancesPaginator(client, &ec2.DescribeInstancesInput{Filters: filters}, func(opt *ec2.DescribeInstancesPaginatorOptions) {
+		opt.StopOnDuplicateToken = true
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, reservation := range page.Reservations {
+			for _, instance := range reservation.Instances {
+				instanceIDs = append(instanceIDs, ptr.Deref(instance.InstanceId, ""))
+			}
+		}
+	}
+	return instanceIDs, nil
+}
+
+// describeClusterHosts returns the available Dedicated Hosts which carry the cluster tag of the providerSpec and match
+// the filters.
+func describeClusterHosts(ctx context.Context, client interfaces.Ec2Client, providerSpecTags map[string]string, filters ...ec2types.Filter) (hosts []ec2types.Host, err error) {
+	defer instrument.AwsAPIMetricRecorderFn(dedicatedHostSelectServiceLabel, &err)()
+
+	filters = append(filters, ec2types.Filter{Name: aws.String("state"), Values: []string{string(ec2types.AllocationStateAvailable)}})
+	for key := range providerSpecTags {
+		if strings.Contains(key, "kubernetes.io/cluster/") {
+			filters = append(filters, ec2types.Filter{Name: aws.String("tag-key"), Values: []string{key}})
+		}
+	}
+
+	paginator := ec2.NewDescribeHostsPaginator(client, &ec2.DescribeHostsInput{Filter: filters}, func(opt *ec2.DescribeHostsPaginatorOptions) {
+		opt.StopOnDuplicateToken = true
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		hosts = append(hosts, page.Hosts...)
+	}
+	return hosts, nil
+}
+
+// machineAvailabilityZone returns the availability zone of the primary network interface of the machine, which is
+// the availability zone of its subnet or of the existing network interface.
+func machineAvailabilityZone(ctx context.Context, client interfaces.Ec2Client, providerSpec *api.AWSProviderSpec) (string, error) {
+	if len(providerSpec.NetworkInterfaces) == 0 {
+		return "", status.Error(codes.InvalidArgument, "the availability zone of the machine cannot be determined without network interfaces")
+	}
+	netIf := providerSpec.NetworkInterfaces[0]
+
+	if netIf.NetworkInterfaceID != nil {
+		output, err := client.DescribeNetworkInterfaces(ctx, &ec2.DescribeNetworkInterfacesInput{NetworkInterfaceIds: []string{*netIf.NetworkInterfaceID}})
+		if awserror.HasErrorCode(err, awserror.InvalidNetworkInterfaceIDNotFound) {
+			return "", status.Error(codes.InvalidArgument, fmt.Sprintf("network interface %s does not exist", *netIf.NetworkInterfaceID))
+		} else if err != nil {
+			return "", status.Error(codes.Internal, err.Error())
+		}
+		if len(output.NetworkInterfaces) == 0 {
+			return "", status.Error(codes.InvalidArgument, fmt.Sprintf("network interface %s does not exist", *netIf.NetworkInterfaceID))
+		}
+		return ptr.Deref(output.NetworkInterfaces[0].AvailabilityZone, ""), nil
+	}
+
+	subnet, err := describeSubnet(ctx, client, netIf.SubnetID)
+	if err != nil {
+		return "", err
+	}
+	if subnet == nil {
+		return "", status.Error(codes.InvalidArgument, fmt.Sprintf("subnet %s does not exist", netIf.SubnetID))
+	}
+	return ptr.Deref(subnet.AvailabilityZone, ""), nil
+}
+
+// availableHostCapacity returns the number of instances of the machine type which still fit on the host.
+func availableHostCapacity(host ec2types.Host, machineType string) int32 {
+	if host.AvailableCapacity == nil {
+		return 0
+	}
+	for _, capacity := range host.AvailableCapacity.AvailableInstanceCapacity {
+		if ptr.Deref(capacity.InstanceType, "") == machineType {
+			return ptr.Deref(capacity.AvailableCapacity, 0)
+		}
+	}
+	return 0
+}
+
+// hostHoldUntil returns the time until which the host is not released, or the zero time if it has no minimum hold.
+func hostHoldUntil(host ec2types.Host) time.Time {
+	for _, tag := range host.Tags {
+		if ptr.Deref(tag.Key, "") != dedicatedHostHoldUntilTagKey {
+			continue
+		}
+		if holdUntil, err := time.Parse(time.RFC3339, ptr.Deref(tag.Value, "")); err == nil {
+			return holdUntil
+		}
+	}
+	return time.Time{}
+}
@@ -0,0 +1,215 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package aws
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/gardener/machine-controller-manager/pkg/util/provider/driver"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+
+	"github.com/gardener/machine-controller-manager-provider-aws/pkg/mockclient"
+)
+
+var _ = Describe("DedicatedHost", func() {
+	const (
+		providerSpec         = `{"ami":"ami-123456789","blockDevices":[{"ebs":{"volumeSize":50,"volumeType":"gp2"}}],"iam":{"name":"test-iam"},"machineType":"m4.large","networkInterfaces":[{"securityGroupIDs":["sg-1"],"subnetID":"subnet-1"}],"placement":{"tenancy":"host","dedicatedHosts":{}},"region":"eu-west-1","tags":{"kubernetes.io/cluster/shoot--test":"1","kubernetes.io/role/test":"1"}}`
+		allocateProviderSpec = `{"ami":"ami-123456789","blockDevices":[{"ebs":{"volumeSize":50,"volumeType":"gp2"}}],"iam":{"name":"test-iam"},"machineType":"m4.large","networkInterfaces":[{"securityGroupIDs":["sg-1"],"subnetID":"subnet-1"}],"placement":{"tenancy":"host","dedicatedHosts":{"allocate":true}},"region":"eu-west-1","tags":{"kubernetes.io/cluster/shoot--test":"1","kubernetes.io/role/test":"1"}}`
+		clusterTagKey        = "kubernetes.io/cluster/shoot--test"
+	)
+	providerSecret := &corev1.Secret{
+		Data: map[string][]byte{
+			"providerAccessKeyId":     []byte("dummy-id"),
+			"providerSecretAccessKey": []byte("dummy-secret"),
+			"userData":                []byte("dummy-user-data"),
+		},
+	}
+
+	var (
+		ctx                context.Context
+		mockClientProvider *mockclient.MockClientProvider
+		d                  driver.Driver
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		mockClientProvider = &mockclient.MockClientProvider{
+			FakeSubnets: []ec2types.Subnet{{SubnetId: ptr.To("subnet-1"), AvailabilityZone: ptr.To("eu-west-1a")}},
+		}
+		d = NewAWSDriver(mockClientProvider)
+	})
+
+	host := func(id, availabilityZone string, availableCapacity int32, tags ...ec2types.Tag) ec2types.Host {
+		return ec2types.Host{
+			HostId:           ptr.To(id),
+			AvailabilityZone: ptr.To(availabilityZone),
+			State:            ec2types.AllocationStateAvailable,
+			AvailableCapacity: &ec2types.AvailableCapacity{
+				AvailableInstanceCapacity: []ec2types.InstanceCapacity{{InstanceType: ptr.To("m4.large"), AvailableCapacity: ptr.To(availableCapacity)}},
+			},
+			Tags: tags,
+		}
+	}
+	clusterTag := ec2types.Tag{Key: ptr.To(clusterTagKey), Value: ptr.To("1")}
+
+	createMachine := func(spec string, index int) error {
+		_, err := d.CreateMachine(ctx, &driver.CreateMachineRequest{Machine: newMachine(index, nil), MachineClass: newMachineClass([]byte(spec)), Secret: providerSecret})
+		return err
+	}
+	// Instances placed by the mock carry their placement in their ID, so machines are deleted by their tags
+	deleteMachine := func(spec string, index int) error {
+		machine := newMachine(-1, nil)
+		machine.Name = fmt.Sprintf("machine-%d", index)
+		_, err := d.DeleteMachine(ctx, &driver.DeleteMachineRequest{Machine: machine, MachineClass: newMachineClass([]byte(spec)), Secret: providerSecret})
+		return err
+	}
+	instanceHostID := func(index int) string {
+		return ptr.Deref(mockClientProvider.FakeInstances[index].Placement.HostId, "")
+	}
+	tagValue := func(host ec2types.Host, key string) string {
+		for _, tag := range host.Tags {
+			if ptr.Deref(tag.Key, "") == key {
+				return ptr.Deref(tag.Value, "")
+			}
+		}
+		return ""
+	}
+
+	It("should place machines on the fullest host of the cluster in their availability zone", func() {
+		mockClientProvider.FakeHosts = []ec2types.Host{
+			host("h-free", "eu-west-1a", 3, clusterTag),
+			host("h-full", "eu-west-1a", 0, clusterTag),
+			host("h-other-zone", "eu-west-1b", 1, clusterTag),
+			host("h-other-cluster", "eu-west-1a", 1),
+			host("h-almost-full", "eu-west-1a", 1, clusterTag),
+		}
+
+		Expect(createMachine(providerSpec, 0)).To(Succeed())
+		Expect(createMachine(providerSpec, 1)).To(Succeed())
+
+		Expect(instanceHostID(0)).To(Equal("h-almost-full"))
+		Expect(instanceHostID(1)).To(Equal("h-free"))
+		Expect(mockClientProvider.FakeHosts).To(HaveLen(5))
+	})
+
+	It("should only allocate a host without free capacity if allocation is enabled", func() {
+		mockClientProvider.FakeHosts = []ec2types.Host{host("h-full", "eu-west-1a", 0, clusterTag)}
+
+		err := createMachine(providerSpec, 0)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("code = [ResourceExhausted]"))
+		Expect(err.Error()).To(ContainSubstring("no Dedicated Host of the cluster has free capacity for machine type m4.large in availability zone eu-west-1a"))
+
+		Expect(createMachine(allocateProviderSpec, 0)).To(Succeed())
+		Expect(createMachine(allocateProviderSpec, 1)).To(Succeed())
+
+		Expect(mockClientProvider.FakeHosts).To(HaveLen(2))
+		allocated := mockClientProvider.FakeHosts[1]
+		Expect(allocated.AvailabilityZone).To(Equal(ptr.To("eu-west-1a")))
+		Expect(allocated.AutoPlacement).To(Equal(ec2types.AutoPlacementOff))
+		Expect(tagValue(allocated, clusterTagKey)).To(Equal("1"))
+		Expect(tagValue(allocated, dedicatedHostAllocatedTagKey)).To(Equal("true"))
+		Expect(allocated.Tags).ToNot(ContainElement(HaveField("Key", ptr.To("Name"))))
+		Expect(instanceHostID(0)).To(Equal(ptr.Deref(allocated.HostId, "")))
+		Expect(instanceHostID(1)).To(Equal(ptr.Deref(allocated.HostId, "")))
+	})
+
+	It("should release allocated hosts once their last machine has been deleted", func() {
+		mockClientProvider.FakeHosts = []ec2types.Host{host("h-preallocated", "eu-west-1a", 0, clusterTag)}
+		Expect(createMachine(allocateProviderSpec, 0)).To(Succeed())
+		Expect(createMachine(allocateProviderSpec, 1)).To(Succeed())
+		mockClientProvider.FakeHosts[0].AvailableCapacity.AvailableInstanceCapacity[0].AvailableCapacity = ptr.To[int32](1)
+
+		Expect(deleteMachine(allocateProviderSpec, 0)).To(Succeed())
+		Expect(mockClientProvider.FakeHosts[1].State).To(Equal(ec2types.AllocationStateAvailable))
+
+		Expect(deleteMachine(allocateProviderSpec, 1)).To(Succeed())
+		Expect(mockClientProvider.FakeHosts[1].State).To(Equal(ec2types.AllocationStateReleased))
+		Expect(mockClientProvider.FakeHosts[0].State).To(Equal(ec2types.AllocationStateAvailable))
+	})
+
+	It("should retry the deletion of the last machine on an allocated host until the host is released", func() {
+		mockClientProvider.ShuttingDownInstances = true
+		Expect(createMachine(allocateProviderSpec, 0)).To(Succeed())
+		Expect(createMachine(allocateProviderSpec, 1)).To(Succeed())
+		Expect(mockClientProvider.FakeHosts).To(HaveLen(1))
+		hostID := ptr.Deref(mockClientProvider.FakeHosts[0].HostId, "")
+
+		// The host still holds the other machine
+		Expect(deleteMachine(allocateProviderSpec, 0)).To(Succeed())
+		mockClientProvider.ShutDownInstances()
+
+		err := deleteMachine(allocateProviderSpec, 1)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("code = [Unavailable]"))
+		Expect(err.Error()).To(ContainSubstring(fmt.Sprintf("Dedicated Hosts [%s] can only be released once the instances of machine \"machine-1\" have been shut down", hostID)))
+		Expect(mockClientProvider.FakeHosts[0].State).To(Equal(ec2types.AllocationStateAvailable))
+
+		mockClientProvider.ShutDownInstances()
+		Expect(deleteMachine(allocateProviderSpec, 1)).To(Succeed())
+		Expect(mockClientProvider.FakeHosts[0].State).To(Equal(ec2types.AllocationStateReleased))
+	})
+
+	It("should keep empty allocated hosts during their minimum hold", func() {
+		holdProviderSpec := strings.Replace(allocateProviderSpec, `"allocate":true`, `"allocate":true,"minimumHoldDuration":"24h"`, 1)
+		Expect(createMachine(holdProviderSpec, 0)).To(Succeed())
+
+		Expect(mockClientProvider.FakeHosts).To(HaveLen(1))
+		holdUntil := tagValue(mockClientProvider.FakeHosts[0], dedicatedHostHoldUntilTagKey)
+		Expect(time.Parse(time.RFC3339, holdUntil)).To(BeTemporally("~", time.Now().Add(24*time.Hour), time.Minute))
+
+		Expect(deleteMachine(holdProviderSpec, 0)).To(Succeed())
+		Expect(mockClientProvider.FakeHosts[0].State).To(Equal(ec2types.AllocationStateAvailable))
+
+		// The empty host is reused and released by a later deletion once the hold has passed
+		for i, tag := range mockClientProvider.FakeHosts[0].Tags {
+			if ptr.Deref(tag.Key, "") == dedicatedHostHoldUntilTagKey {
+				mockClientProvider.FakeHosts[0].Tags[i].Value = ptr.To(time.Now().Add(-time.Minute).UTC().Format(time.RFC3339))
+			}
+		}
+		Expect(createMachine(holdProviderSpec, 1)).To(Succeed())
+		Expect(mockClientProvider.FakeHosts).To(HaveLen(1))
+		Expect(deleteMachine(holdProviderSpec, 1)).To(Succeed())
+		Expect(mockClientProvider.FakeHosts[0].State).To(Equal(ec2types.AllocationStateReleased))
+	})
+
+	It("should release a newly allocated host if the instance cannot be launched", func() {
+		failingProviderSpec := strings.Replace(allocateProviderSpec, `"ami":"ami-123456789"`, `"ami":"`+mockclient.FailQueryAtRunInstances+`","keyName":"`+mockclient.InsufficientCapacity+`"`, 1)
+		mockClientProvider.FakeHosts = []ec2types.Host{host("h-full", "eu-west-1a", 0, clusterTag)}
+
+		err := createMachine(failingProviderSpec, 0)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("code = [ResourceExhausted]"))
+
+		Expect(mockClientProvider.FakeHosts).To(HaveLen(2))
+		Expect(mockClientProvider.FakeHosts[1].State).To(Equal(ec2types.AllocationStateReleased))
+		Expect(mockClientProvider.FakeHosts[0].State).To(Equal(ec2types.AllocationStateAvailable))
+	})
+
+	It("should keep a newly allocated host with a minimum hold if the instance cannot be launched", func() {
+		failingProviderSpec := strings.Replace(allocateProviderSpec, `"ami":"ami-123456789"`, `"ami":"`+mockclient.FailQueryAtRunInstances+`","keyName":"`+mockclient.InsufficientCapacity+`"`, 1)
+		failingProviderSpec = strings.Replace(failingProviderSpec, `"allocate":true`, `"allocate":true,"minimumHoldDuration":"24h"`, 1)
+
+		Expect(createMachine(failingProviderSpec, 0)).ToNot(Succeed())
+
+		Expect(mockClientProvider.FakeHosts).To(HaveLen(1))
+		Expect(mockClientProvider.FakeHosts[0].State).To(Equal(ec2types.AllocationStateAvailable))
+	})
+
+	It("should reject dedicated hosts in the deprecated placement annotation", func() {
+		machine := newMachine(0, map[string]string{awsPlacement: `{"tenancy":"host","dedicatedHosts":{"allocate":true}}`})
+		_, err := d.CreateMachine(ctx, &driver.CreateMachineRequest{Machine: machine, MachineClass: newMachineClass([]byte(providerSpec)), Secret: providerSecret})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("code = [InvalidArgument]"))
+		Expect(err.Error()).To(ContainSubstring("dedicatedHosts is only supported in providerSpec.placement"))
+	})
+})
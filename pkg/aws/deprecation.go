@@ -0,0 +1,77 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package aws
+
+import (
+	"sync"
+	"time"
+
+	v1alpha1 "github.com/gardener/machine-controller-manager/pkg/apis/machine/v1alpha1"
+	"k8s.io/klog/v2"
+
+	"github.com/gardener/machine-controller-manager-provider-aws/pkg/instrument"
+)
+
+const (
+	// featureSpotPrice is the deprecated providerSpec.spotPrice field
+	featureSpotPrice = "spotPrice"
+	// featureAWSPlacementAnnotation is the deprecated machine.sapcloud.io/awsPlacement node template annotation
+	featureAWSPlacementAnnotation = "awsPlacementAnnotation"
+)
+
+// deprecatedFeatureReplacements names the replacement of each deprecated feature in warnings.
+var deprecatedFeatureReplacements = map[string]string{
+	featureSpotPrice:              `providerSpec.instanceMarketOptions with marketType "spot"`,
+	featureAWSPlacementAnnotation: "providerSpec.placement",
+}
+
+// deprecationWarningInterval is the minimum interval between two warnings for the same MachineClass and feature.
+var deprecationWarningInterval = time.Hour
+
+// deprecations tracks the MachineClasses using deprecated features.
+var deprecations = newDeprecationTracker()
+
+type deprecationTracker struct {
+	mu sync.Mutex
+	// machineClasses contains the keys of the MachineClasses using a feature per feature.
+	machineClasses map[string]map[string]struct{}
+	// lastWarnings contains the time of the last warning per feature and MachineClass key.
+	lastWarnings map[string]map[string]time.Time
+}
+
+func newDeprecationTracker() *deprecationTracker {
+	return &deprecationTracker{
+		machineClasses: map[string]map[string]struct{}{},
+		lastWarnings:   map[string]map[string]time.Time{},
+	}
+}
+
+// observe records whether the MachineClass uses the deprecated feature. Usage is logged as warning at most once per
+// deprecationWarningInterval and MachineClass, and the number of MachineClasses using the feature is exposed as metric.
+func (t *deprecationTracker) observe(feature string, machineClass *v1alpha1.MachineClass, used bool) {
+	key := machineClass.Namespace + "/" + machineClass.Name
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.machineClasses[feature] == nil {
+		t.machineClasses[feature] = map[string]struct{}{}
+		t.lastWarnings[feature] = map[string]time.Time{}
+	}
+	if !used {
+		delete(t.machineClasses[feature], key)
+		delete(t.lastWarnings[feature], key)
+		instrument.RecordDeprecatedFeatureMachineClasses(feature, len(t.machineClasses[feature]))
+		return
+	}
+
+	t.machineClasses[feature][key] = struct{}{}
+	instrument.RecordDeprecatedFeatureMachineClasses(feature, len(t.machineClasses[feature]))
+	if lastWarning, ok := t.lastWarnings[feature][key]; ok && time.Since(lastWarning) < deprecationWarningInterval {
+		return
+	}
+	t.lastWarnings[feature][key] = time.Now()
+	klog.Warningf("MachineClass %q uses the deprecated %s, please use %s instead", key, feature, deprecatedFeatureReplacements[feature])
+}
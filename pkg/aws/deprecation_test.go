@@ -0,0 +1,70 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package aws
+
+import (
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	v1alpha1 "github.com/gardener/machine-controller-manager/pkg/apis/machine/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	api "github.com/gardener/machine-controller-manager-provider-aws/pkg/aws/apis"
+	"github.com/gardener/machine-controller-manager-provider-aws/pkg/instrument"
+)
+
+var _ = Describe("Deprecation", func() {
+	Describe("#observe", func() {
+		const feature = "testFeature"
+
+		machineClass := func(name string) *v1alpha1.MachineClass {
+			return &v1alpha1.MachineClass{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace}}
+		}
+		usage := func() float64 {
+			return testutil.ToFloat64(instrument.DeprecatedFeatureMachineClasses.WithLabelValues("aws", feature))
+		}
+
+		It("should count the MachineClasses using a feature", func() {
+			tracker := newDeprecationTracker()
+
+			tracker.observe(feature, machineClass("class-a"), true)
+			tracker.observe(feature, machineClass("class-a"), true)
+			tracker.observe(feature, machineClass("class-b"), true)
+			Expect(usage()).To(Equal(float64(2)))
+			Expect(tracker.lastWarnings[feature]).To(HaveKey(testNamespace + "/class-a"))
+
+			tracker.observe(feature, machineClass("class-a"), false)
+			Expect(usage()).To(Equal(float64(1)))
+			Expect(tracker.lastWarnings[feature]).ToNot(HaveKey(testNamespace + "/class-a"))
+		})
+
+		It("should rate limit warnings per MachineClass", func() {
+			tracker := newDeprecationTracker()
+
+			tracker.observe(feature, machineClass("class-a"), true)
+			lastWarning := tracker.lastWarnings[feature][testNamespace+"/class-a"]
+			tracker.observe(feature, machineClass("class-a"), true)
+			Expect(tracker.lastWarnings[feature][testNamespace+"/class-a"]).To(Equal(lastWarning))
+		})
+	})
+
+	Describe("#placementConflicts", func() {
+		It("should not report conflicts for matching values", func() {
+			Expect(placementConflicts(
+				&api.AWSPlacementSpec{Tenancy: ptr.To("host"), Affinity: ptr.To("host"), HostID: ptr.To("h-123")},
+				&ec2types.Placement{Tenancy: ec2types.TenancyHost, Affinity: ptr.To("host"), AvailabilityZone: ptr.To("eu-west-1a")},
+			)).To(BeEmpty())
+		})
+
+		It("should report values which differ or are missing in the providerSpec", func() {
+			Expect(placementConflicts(
+				&api.AWSPlacementSpec{Tenancy: ptr.To("host"), GroupID: ptr.To("pg-1")},
+				&ec2types.Placement{Tenancy: ec2types.TenancyDedicated, GroupId: ptr.To("pg-2"), PartitionNumber: ptr.To[int32](1)},
+			)).To(ConsistOf("tenancy", "groupId", "partitionNumber"))
+		})
+	})
+})
@@ -0,0 +1,247 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package aws
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
+
+	api "github.com/gardener/machine-controller-manager-provider-aws/pkg/aws/apis"
+	awserror "github.com/gardener/machine-controller-manager-provider-aws/pkg/aws/errors"
+	"github.com/gardener/machine-controller-manager-provider-aws/pkg/aws/interfaces"
+	"github.com/gardener/machine-controller-manager-provider-aws/pkg/instrument"
+)
+
+const (
+	elasticIPGetByMachineServiceLabel = "elastic_ip_get_by_machine"
+	elasticIPAssociateServiceLabel    = "elastic_ip_associate"
+	elasticIPReleaseServiceLabel      = "elastic_ip_release"
+
+	resourceTypeElasticIP = "elastic-ip"
+
+	// maxElasticIPClaimAttempts limits how often an address is taken from a pool after the previous one has been taken
+	// by a concurrently created machine.
+	maxElasticIPClaimAttempts = 5
+
+	// elasticIPMachineTagKey is the key of the tag whose value is the name of the machine using the Elastic IP.
+	elasticIPMachineTagKey = "machine.sapcloud.io/machine-name"
+	// elasticIPAllocatedTagKey marks Elastic IPs which have been allocated for a machine and are released together with
+	// it, in contrast to addresses taken from a pool.
+	elasticIPAllocatedTagKey = "machine.sapcloud.io/elastic-ip-allocated"
+)
+
+// getMachineElasticIP returns the Elastic IP tagged with the name of the machine, or nil if there is none.
+func getMachineElasticIP(ctx context.Context, client interfaces.Ec2Client, machineName string) (address *ec2types.Address, err error) {
+	defer instrument.AwsAPIMetricRecorderFn(elasticIPGetByMachineServiceLabel, &err)()
+
+	output, err := client.DescribeAddresses(ctx, &ec2.DescribeAddressesInput{
+		Filters: []ec2types.Filter{{
+			Name:   aws.String("tag:" + elasticIPMachineTagKey),
+			Values: []string{machineName},
+		}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(output.Addresses) == 0 {
+		return nil, nil
+	}
+	return &output.Addresses[0], nil
+}
+
+// associateElasticIP associates the Elastic IP of the machine with the primary network interface of the instance. If
+// the machine has no Elastic IP yet, one is allocated or taken from the pool selected by spec.PoolTags. An address which
+// is associated with another instance is never moved to the instance.
+func (d *Driver) associateElasticIP(ctx context.Context, client interfaces.Ec2Client, spec *api.AWSElasticIPSpec, providerSpecTags map[string]string, machineName string, instance ec2types.Instance) (err error) {
+	address, err := getMachineElasticIP(ctx, client, machineName)
+	if err != nil {
+		return err
+	}
+
+	defer instrument.AwsAPIMetricRecorderFn(elasticIPAssociateServiceLabel, &err)()
+
+	if address == nil && len(spec.PoolTags) > 0 {
+		return claimPooledElasticIP(ctx, client, spec.PoolTags, machineName, instance)
+	}
+	if address == nil {
+		if address, err = d.allocateElasticIP(ctx, client, providerSpecTags, machineName); err != nil {
+			return err
+		}
+	}
+
+	instanceID := ptr.Deref(instance.InstanceId, "")
+	switch associatedInstanceID := ptr.Deref(address.InstanceId, ""); associatedInstanceID {
+	case instanceID:
+		return nil
+	case "":
+		return associateAddress(ctx, client, address, machineName, instance)
+	default:
+		return fmt.Errorf("Elastic IP %q of machine %q is associated with another VM %q", ptr.Deref(address.PublicIp, ""), machineName, associatedInstanceID)
+	}
+}
+
+// associateAddress associates the unassociated Elastic IP with the primary network interface of the instance. It fails
+// with the error code Resource.AlreadyAssociated if the address has been associated concurrently.
+func associateAddress(ctx context.Context, client interfaces.Ec2Client, address *ec2types.Address, machineName string, instance ec2types.Instance) error {
+	instanceID := ptr.Deref(instance.InstanceId, "")
+	input := &ec2.AssociateAddressInput{
+		AllocationId:       address.AllocationId,
+		AllowReassociation: aws.Bool(false),
+	}
+	// Instances with several network interfaces require the network interface to be specified
+	if networkInterfaceID := primaryNetworkInterfaceID(instance); networkInterfaceID != "" {
+		input.NetworkInterfaceId = aws.String(networkInterfaceID)
+	} else {
+		input.InstanceId = aws.String(instanceID)
+	}
+	klog.V(3).Infof("Associating Elastic IP %q with VM %q of machine %q", ptr.Deref(address.PublicIp, ""), instanceID, machineName)
+	_, err := client.AssociateAddress(ctx, input)
+	return err
+}
+
+// allocateElasticIP allocates a new Elastic IP for the machine, which is released when the machine is deleted.
+func (d *Driver) allocateElasticIP(ctx context.Context, client interfaces.Ec2Client, providerSpecTags map[string]string, machineName string) (*ec2types.Address, error) {
+	tagSpec, err := d.generateTags(providerSpecTags, resourceTypeElasticIP, machineName)
+	if err != nil {
+		return nil, err
+	}
+	tagSpec.Tags = append(tagSpec.Tags,
+		ec2types.Tag{Key: aws.String(elasticIPMachineTagKey), Value: aws.String(machineName)},
+		ec2types.Tag{Key: aws.String(elasticIPAllocatedTagKey), Value: aws.String("true")},
+	)
+
+	output, err := client.AllocateAddress(ctx, &ec2.AllocateAddressInput{
+		Domain:            ec2types.DomainTypeVpc,
+		TagSpecifications: []ec2types.TagSpecification{tagSpec},
+	})
+	if err != nil {
+		return nil, err
+	}
+	klog.V(3).Infof("Allocated Elastic IP %q for machine %q", ptr.Deref(output.PublicIp, ""), machineName)
+	return &ec2types.Address{AllocationId: output.AllocationId, PublicIp: output.PublicIp}, nil
+}
+
+// claimPooledElasticIP associates an unassociated Elastic IP of the pool with the instance and tags it with the name of
+// the machine. The association claims the address, so that concurrently created machines cannot take the same address:
+// if another machine associated it first, the next address of the pool is tried.
+func claimPooledElasticIP(ctx context.Context, client interfaces.Ec2Client, poolTags map[string]string, machineName string, instance ec2types.Instance) error {
+	var filters []ec2types.Filter
+	for key, value := range poolTags {
+		filters = append(filters, ec2types.Filter{Name: aws.String("tag:" + key), Values: []string{value}})
+	}
+
+	for range maxElasticIPClaimAttempts {
+		output, err := client.DescribeAddresses(ctx, &ec2.DescribeAddressesInput{Filters: filters})
+		if err != nil {
+			return err
+		}
+
+		address := selectPooledElasticIP(output.Addresses, ptr.Deref(instance.InstanceId, ""))
+		if address == nil {
+			return fmt.Errorf("no unassociated Elastic IP is left in the pool with tags %v", poolTags)
+		}
+		if address.InstanceId == nil {
+			if err := associateAddress(ctx, client, address, machineName, instance); err != nil {
+				if awserror.HasErrorCode(err, awserror.ResourceAlreadyAssociated) {
+					klog.V(3).Infof("Elastic IP %q has been taken from the pool by another machine, retrying for machine %q", ptr.Deref(address.PublicIp, ""), machineName)
+					continue
+				}
+				return err
+			}
+		}
+
+		_, err = client.CreateTags(ctx, &ec2.CreateTagsInput{
+			Resources: []string{ptr.Deref(address.AllocationId, "")},
+			Tags:      []ec2types.Tag{{Key: aws.String(elasticIPMachineTagKey), Value: aws.String(machineName)}},
+		})
+		if err != nil {
+			return err
+		}
+		klog.V(3).Infof("Took Elastic IP %q for machine %q from the pool with tags %v", ptr.Deref(address.PublicIp, ""), machineName, poolTags)
+		return nil
+	}
+	return fmt.Errorf("Elastic IPs of the pool with tags %v have been taken by other machines %d times", poolTags, maxElasticIPClaimAttempts)
+}
+
+// selectPooledElasticIP returns the untagged address of the pool which is associated with the instance already, e.g. if
+// tagging it failed before, or else the first unassociated untagged address.
+func selectPooledElasticIP(addresses []ec2types.Address, instanceID string) *ec2types.Address {
+	var free *ec2types.Address
+	for i, address := range addresses {
+		if hasTag(address.Tags, elasticIPMachineTagKey) {
+			continue
+		}
+		if ptr.Deref(address.InstanceId, "") == instanceID {
+			return &addresses[i]
+		}
+		if free == nil && address.AssociationId == nil {
+			free = &addresses[i]
+		}
+	}
+	return free
+}
+
+// releaseElasticIP disassociates the Elastic IP of the machine. An allocated address is released, an address of a pool
+// is returned to the pool by removing the machine tag.
+func releaseElasticIP(ctx context.Context, client interfaces.Ec2Client, machineName string) (err error) {
+	address, err := getMachineElasticIP(ctx, client, machineName)
+	if err != nil || address == nil {
+		return err
+	}
+
+	defer instrument.AwsAPIMetricRecorderFn(elasticIPReleaseServiceLabel, &err)()
+
+	if address.AssociationId != nil {
+		_, err = client.DisassociateAddress(ctx, &ec2.DisassociateAddressInput{AssociationId: address.AssociationId})
+		if err != nil && !awserror.HasErrorCode(err, awserror.InvalidAssociationIDNotFound) {
+			return err
+		}
+	}
+
+	if hasTag(address.Tags, elasticIPAllocatedTagKey) {
+		_, err = client.ReleaseAddress(ctx, &ec2.ReleaseAddressInput{AllocationId: address.AllocationId})
+		if err != nil {
+			return err
+		}
+		klog.V(3).Infof("Released Elastic IP %q of machine %q", ptr.Deref(address.PublicIp, ""), machineName)
+		return nil
+	}
+
+	_, err = client.DeleteTags(ctx, &ec2.DeleteTagsInput{
+		Resources: []string{ptr.Deref(address.AllocationId, "")},
+		Tags:      []ec2types.Tag{{Key: aws.String(elasticIPMachineTagKey)}},
+	})
+	if err != nil {
+		return err
+	}
+	klog.V(3).Infof("Returned Elastic IP %q of machine %q to its pool", ptr.Deref(address.PublicIp, ""), machineName)
+	return nil
+}
+
+// primaryNetworkInterfaceID returns the ID of the network interface with device index 0 on network card 0, or an empty
+// string if the instance has no such interface.
+func primaryNetworkInterfaceID(instance ec2types.Instance) string {
+	for _, netIf := range instance.NetworkInterfaces {
+		if netIf.Attachment != nil && ptr.Deref(netIf.Attachment.DeviceIndex, -1) == 0 && ptr.Deref(netIf.Attachment.NetworkCardIndex, 0) == 0 {
+			return ptr.Deref(netIf.NetworkInterfaceId, "")
+		}
+	}
+	return ""
+}
+
+func hasTag(tags []ec2types.Tag, key string) bool {
+	for _, tag := range tags {
+		if ptr.Deref(tag.Key, "") == key {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,191 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package aws
+
+import (
+	"context"
+
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/gardener/machine-controller-manager/pkg/util/provider/driver"
+	"github.com/gardener/machine-controller-manager/pkg/util/provider/machinecodes/codes"
+	"github.com/gardener/machine-controller-manager/pkg/util/provider/machinecodes/status"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+
+	"github.com/gardener/machine-controller-manager-provider-aws/pkg/mockclient"
+)
+
+var _ = Describe("ElasticIP", func() {
+	const (
+		allocateProviderSpec = `{"ami":"ami-123456789","blockDevices":[{"ebs":{"volumeSize":50,"volumeType":"gp2"}}],"elasticIP":{},"iam":{"name":"test-iam"},"machineType":"m4.large","networkInterfaces":[{"securityGroupIDs":["sg-1"],"subnetID":"subnet-1"}],"region":"eu-west-1","tags":{"kubernetes.io/cluster/shoot--test":"1","kubernetes.io/role/test":"1"}}`
+		poolProviderSpec     = `{"ami":"ami-123456789","blockDevices":[{"ebs":{"volumeSize":50,"volumeType":"gp2"}}],"elasticIP":{"poolTags":{"pool":"bastion"}},"iam":{"name":"test-iam"},"machineType":"m4.large","networkInterfaces":[{"securityGroupIDs":["sg-1"],"subnetID":"subnet-1"}],"region":"eu-west-1","tags":{"kubernetes.io/cluster/shoot--test":"1","kubernetes.io/role/test":"1"}}`
+	)
+	providerSecret := &corev1.Secret{
+		Data: map[string][]byte{
+			"providerAccessKeyId":     []byte("dummy-id"),
+			"providerSecretAccessKey": []byte("dummy-secret"),
+			"userData":                []byte("dummy-user-data"),
+		},
+	}
+
+	var (
+		ctx                context.Context
+		mockClientProvider *mockclient.MockClientProvider
+		d                  driver.Driver
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		mockClientProvider = &mockclient.MockClientProvider{}
+		d = NewAWSDriver(mockClientProvider)
+	})
+
+	createAndInitialize := func(providerSpec string) error {
+		machineClass := newMachineClass([]byte(providerSpec))
+		_, err := d.CreateMachine(ctx, &driver.CreateMachineRequest{Machine: newMachine(0, nil), MachineClass: machineClass, Secret: providerSecret})
+		Expect(err).ToNot(HaveOccurred())
+		_, err = d.InitializeMachine(ctx, &driver.InitializeMachineRequest{Machine: newMachine(0, nil), MachineClass: machineClass, Secret: providerSecret})
+		return err
+	}
+	getMachineStatus := func(providerSpec string) error {
+		_, err := d.GetMachineStatus(ctx, &driver.GetMachineStatusRequest{Machine: newMachine(0, nil), MachineClass: newMachineClass([]byte(providerSpec)), Secret: providerSecret})
+		return err
+	}
+	deleteMachine := func(providerSpec string) error {
+		_, err := d.DeleteMachine(ctx, &driver.DeleteMachineRequest{Machine: newMachine(0, nil), MachineClass: newMachineClass([]byte(providerSpec)), Secret: providerSecret})
+		return err
+	}
+	tagValue := func(address ec2types.Address, key string) string {
+		for _, tag := range address.Tags {
+			if ptr.Deref(tag.Key, "") == key {
+				return ptr.Deref(tag.Value, "")
+			}
+		}
+		return ""
+	}
+
+	It("should allocate an Elastic IP, associate it and release it on deletion", func() {
+		Expect(createAndInitialize(allocateProviderSpec)).To(Succeed())
+
+		Expect(mockClientProvider.FakeAddresses).To(HaveLen(1))
+		address := mockClientProvider.FakeAddresses[0]
+		Expect(address.InstanceId).To(Equal(ptr.To("i-0123456789-0")))
+		Expect(tagValue(address, elasticIPMachineTagKey)).To(Equal("machine-0"))
+		Expect(tagValue(address, elasticIPAllocatedTagKey)).To(Equal("true"))
+		Expect(tagValue(address, "kubernetes.io/cluster/shoot--test")).To(Equal("1"))
+		Expect(getMachineStatus(allocateProviderSpec)).To(Succeed())
+
+		Expect(deleteMachine(allocateProviderSpec)).To(Succeed())
+		Expect(mockClientProvider.FakeAddresses).To(BeEmpty())
+	})
+
+	It("should associate the Elastic IP with the primary network interface", func() {
+		machineClass := newMachineClass([]byte(allocateProviderSpec))
+		_, err := d.CreateMachine(ctx, &driver.CreateMachineRequest{Machine: newMachine(0, nil), MachineClass: machineClass, Secret: providerSecret})
+		Expect(err).ToNot(HaveOccurred())
+		mockClientProvider.FakeInstances[0].NetworkInterfaces = []ec2types.InstanceNetworkInterface{
+			{NetworkInterfaceId: ptr.To("eni-1"), Attachment: &ec2types.InstanceNetworkInterfaceAttachment{DeviceIndex: ptr.To[int32](1)}},
+			{NetworkInterfaceId: ptr.To("eni-0"), Attachment: &ec2types.InstanceNetworkInterfaceAttachment{DeviceIndex: ptr.To[int32](0)}},
+		}
+
+		_, err = d.InitializeMachine(ctx, &driver.InitializeMachineRequest{Machine: newMachine(0, nil), MachineClass: machineClass, Secret: providerSecret})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(mockClientProvider.FakeAddresses[0].NetworkInterfaceId).To(Equal(ptr.To("eni-0")))
+		Expect(mockClientProvider.FakeAddresses[0].InstanceId).To(Equal(ptr.To("i-0123456789-0")))
+	})
+
+	It("should report the machine as uninitialized until the Elastic IP is associated", func() {
+		_, err := d.CreateMachine(ctx, &driver.CreateMachineRequest{Machine: newMachine(0, nil), MachineClass: newMachineClass([]byte(allocateProviderSpec)), Secret: providerSecret})
+		Expect(err).ToNot(HaveOccurred())
+
+		err = getMachineStatus(allocateProviderSpec)
+		Expect(err).To(HaveOccurred())
+		statusErr, ok := status.FromError(err)
+		Expect(ok).To(BeTrue())
+		Expect(statusErr.Code()).To(Equal(codes.Uninitialized))
+		Expect(err.Error()).To(ContainSubstring("has no Elastic IP associated despite providerSpec.ElasticIP being set"))
+	})
+
+	It("should take an Elastic IP from the pool and return it on deletion", func() {
+		mockClientProvider.FakeAddresses = []ec2types.Address{
+			{
+				AllocationId:  ptr.To("eipalloc-used"),
+				AssociationId: ptr.To("eipassoc-used"),
+				InstanceId:    ptr.To("i-other"),
+				Tags:          []ec2types.Tag{{Key: ptr.To("pool"), Value: ptr.To("bastion")}},
+			},
+			{
+				AllocationId: ptr.To("eipalloc-other-pool"),
+				Tags:         []ec2types.Tag{{Key: ptr.To("pool"), Value: ptr.To("nat")}},
+			},
+			{
+				AllocationId: ptr.To("eipalloc-free"),
+				Tags:         []ec2types.Tag{{Key: ptr.To("pool"), Value: ptr.To("bastion")}},
+			},
+		}
+
+		Expect(createAndInitialize(poolProviderSpec)).To(Succeed())
+		Expect(mockClientProvider.FakeAddresses).To(HaveLen(3))
+		address := mockClientProvider.FakeAddresses[2]
+		Expect(address.InstanceId).To(Equal(ptr.To("i-0123456789-0")))
+		Expect(tagValue(address, elasticIPMachineTagKey)).To(Equal("machine-0"))
+		Expect(getMachineStatus(poolProviderSpec)).To(Succeed())
+
+		Expect(deleteMachine(poolProviderSpec)).To(Succeed())
+		Expect(mockClientProvider.FakeAddresses).To(HaveLen(3))
+		address = mockClientProvider.FakeAddresses[2]
+		Expect(address.AssociationId).To(BeNil())
+		Expect(hasTag(address.Tags, elasticIPMachineTagKey)).To(BeFalse())
+		Expect(tagValue(address, "pool")).To(Equal("bastion"))
+	})
+
+	It("should take the next Elastic IP of the pool if another machine took the address concurrently", func() {
+		mockClientProvider.FakeAddresses = []ec2types.Address{
+			{AllocationId: ptr.To("eipalloc-raced"), Tags: []ec2types.Tag{{Key: ptr.To("pool"), Value: ptr.To("bastion")}}},
+			{AllocationId: ptr.To("eipalloc-free"), Tags: []ec2types.Tag{{Key: ptr.To("pool"), Value: ptr.To("bastion")}}},
+		}
+		mockClientProvider.ConcurrentAddressClaims = []string{"eipalloc-raced"}
+
+		Expect(createAndInitialize(poolProviderSpec)).To(Succeed())
+		raced, free := mockClientProvider.FakeAddresses[0], mockClientProvider.FakeAddresses[1]
+		Expect(raced.InstanceId).To(Equal(ptr.To("i-concurrent")))
+		Expect(hasTag(raced.Tags, elasticIPMachineTagKey)).To(BeFalse())
+		Expect(free.InstanceId).To(Equal(ptr.To("i-0123456789-0")))
+		Expect(tagValue(free, elasticIPMachineTagKey)).To(Equal("machine-0"))
+	})
+
+	It("should not move the Elastic IP of the machine from another instance", func() {
+		mockClientProvider.FakeAddresses = []ec2types.Address{{
+			AllocationId:  ptr.To("eipalloc-0"),
+			PublicIp:      ptr.To("198.51.100.7"),
+			AssociationId: ptr.To("eipassoc-0"),
+			InstanceId:    ptr.To("i-other"),
+			Tags:          []ec2types.Tag{{Key: ptr.To(elasticIPMachineTagKey), Value: ptr.To("machine-0")}},
+		}}
+
+		err := createAndInitialize(allocateProviderSpec)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring(`Elastic IP "198.51.100.7" of machine "machine-0" is associated with another VM "i-other"`))
+		Expect(mockClientProvider.FakeAddresses[0].InstanceId).To(Equal(ptr.To("i-other")))
+	})
+
+	It("should fail the initialization if the pool is exhausted", func() {
+		mockClientProvider.FakeAddresses = []ec2types.Address{{
+			AllocationId:  ptr.To("eipalloc-used"),
+			AssociationId: ptr.To("eipassoc-used"),
+			InstanceId:    ptr.To("i-other"),
+			Tags:          []ec2types.Tag{{Key: ptr.To("pool"), Value: ptr.To("bastion")}},
+		}}
+
+		err := createAndInitialize(poolProviderSpec)
+		Expect(err).To(HaveOccurred())
+		statusErr, ok := status.FromError(err)
+		Expect(ok).To(BeTrue())
+		Expect(statusErr.Code()).To(Equal(codes.Uninitialized))
+		Expect(err.Error()).To(ContainSubstring("no unassociated Elastic IP is left in the pool with tags map[pool:bastion]"))
+	})
+})
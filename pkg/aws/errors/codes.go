@@ -59,4 +59,67 @@ const (
 	// Unsupported is returned when the specified request is unsupported. For example, you might be trying to launch an instance in an
 	// Availability Zone that currently has constraints on that instance type. The returned message provides details of the unsupported request.
 	Unsupported = "Unsupported"
+
+	// DryRunOperation is returned for requests with DryRun set, if the request would have succeeded.
+	DryRunOperation = "DryRunOperation"
+
+	// UnauthorizedOperation is returned when the credentials are not permitted to perform the request.
+	UnauthorizedOperation = "UnauthorizedOperation"
+
+	// InvalidParameterValue is returned when a parameter of the request is malformed or refers to a resource that
+	// cannot be used, e.g. an IAM instance profile which does not exist.
+	InvalidParameterValue = "InvalidParameterValue"
+
+	// InvalidAMIIDNotFound is returned when the specified AMI does not exist or is not shared with the account.
+	InvalidAMIIDNotFound = "InvalidAMIID.NotFound"
+
+	// InvalidSubnetIDNotFound is returned when the specified subnet does not exist.
+	InvalidSubnetIDNotFound = "InvalidSubnetID.NotFound"
+
+	// InvalidGroupNotFound is returned when the specified security group does not exist.
+	InvalidGroupNotFound = "InvalidGroup.NotFound"
+
+	// InvalidInstanceType is returned when the specified instance type is not supported in the region.
+	InvalidInstanceType = "InvalidInstanceType"
+
+	// RequestLimitExceeded is returned when the request rate of the account exceeds the API throttling limits.
+	RequestLimitExceeded = "RequestLimitExceeded"
+
+	// InvalidAssociationIDNotFound is returned when the specified association, e.g. of an Elastic IP, does not exist.
+	InvalidAssociationIDNotFound = "InvalidAssociationID.NotFound"
+
+	// ResourceAlreadyAssociated is returned when an Elastic IP is associated without allowing reassociation, but it is
+	// associated already.
+	ResourceAlreadyAssociated = "Resource.AlreadyAssociated"
+
+	// InvalidNetworkInterfaceIDNotFound is returned when the specified network interface does not exist.
+	InvalidNetworkInterfaceIDNotFound = "InvalidNetworkInterfaceID.NotFound"
+
+	// UnsupportedOperation is returned when the requested operation or option is not supported, e.g. maintenance options
+	// for an instance type which does not support them.
+	UnsupportedOperation = "UnsupportedOperation"
+
+	// InvalidParameterCombination is returned when parameters of the request cannot be used together, e.g. maintenance
+	// options with an instance type which does not support them.
+	InvalidParameterCombination = "InvalidParameterCombination"
+
+	// InvalidIPAddressInUse is returned when a requested private IP address is already in use in the subnet.
+	InvalidIPAddressInUse = "InvalidIPAddress.InUse"
+
+	// InsufficientHostCapacity is returned when there is not enough capacity to allocate a Dedicated Host, e.g. for an
+	// instance launched into a host resource group.
+	InsufficientHostCapacity = "InsufficientHostCapacity"
+
+	// InvalidPlacementGroupUnknown is returned when the specified placement group does not exist.
+	InvalidPlacementGroupUnknown = "InvalidPlacementGroup.Unknown"
+
+	// ReservationCapacityExceeded is returned when the targeted capacity reservation does not have enough available
+	// capacity to fulfill your request.
+	ReservationCapacityExceeded = "ReservationCapacityExceeded"
+
+	// InvalidCapacityReservationIDNotFound is returned when the specified capacity reservation does not exist.
+	InvalidCapacityReservationIDNotFound = "InvalidCapacityReservationId.NotFound"
+
+	// HostLimitExceeded is returned when you've reached the limit on the number of Dedicated Hosts you can allocate.
+	HostLimitExceeded = "HostLimitExceeded"
 )
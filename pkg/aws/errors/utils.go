@@ -7,6 +7,7 @@ package errors
 import (
 	"errors"
 
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	"github.com/aws/smithy-go"
 	"github.com/gardener/machine-controller-manager/pkg/util/provider/machinecodes/codes"
 )
@@ -25,13 +26,36 @@ func GetMCMErrorCodeForCreateMachine(err error) codes.Code {
 			VolumeLimitExceeded,
 			MaxIOPSLimitExceeded,
 			RouteLimitExceeded,
+			InsufficientHostCapacity,
+			HostLimitExceeded,
+			ReservationCapacityExceeded,
 			Unsupported:
 			return codes.ResourceExhausted
+		case InvalidCapacityReservationIDNotFound:
+			return codes.InvalidArgument
 		}
 	}
 	return codes.Internal
 }
 
+// GetMCMErrorCodeForRunInstances takes the error returned from the EC2API for a RunInstances request and returns the
+// corresponding MCM error code like GetMCMErrorCodeForCreateMachine. If the request sets maintenance options, license
+// configurations or a host resource group, which cannot be used with every instance type or account, rejected
+// parameters are mapped to codes.InvalidArgument, since such a request does not succeed on retry either.
+func GetMCMErrorCodeForRunInstances(err error, input *ec2.RunInstancesInput) codes.Code {
+	if HasErrorCode(err, UnsupportedOperation, InvalidParameterCombination, InvalidParameterValue) && hasRestrictedOptions(input) {
+		return codes.InvalidArgument
+	}
+	return GetMCMErrorCodeForCreateMachine(err)
+}
+
+// hasRestrictedOptions checks if the request sets options which are only supported by some instance types or accounts.
+func hasRestrictedOptions(input *ec2.RunInstancesInput) bool {
+	return input.MaintenanceOptions != nil ||
+		len(input.LicenseSpecifications) > 0 ||
+		(input.Placement != nil && input.Placement.HostResourceGroupArn != nil)
+}
+
 // GetMCMErrorCodeForTerminateInstances takes the error returned from the EC2API during the terminateInstance call and returns the corresponding MCM error code.
 func GetMCMErrorCodeForTerminateInstances(err error) codes.Code {
 	var awsErr smithy.APIError
@@ -52,3 +76,39 @@ func IsInstanceIDNotFound(err error) bool {
 	}
 	return false
 }
+
+// GetMCMErrorCodeForDryRun takes the error returned from the EC2API for a request with DryRun set and returns the
+// corresponding MCM error code. codes.OK is returned if the request would have succeeded. Errors which do not indicate
+// a rejected request (e.g. throttling or server errors) are mapped to codes.Internal, capacity errors are mapped like
+// for CreateMachine and all other API errors are mapped to codes.InvalidArgument.
+func GetMCMErrorCodeForDryRun(err error) codes.Code {
+	if err == nil {
+		return codes.OK
+	}
+	var awsErr smithy.APIError
+	if !errors.As(err, &awsErr) {
+		return codes.Internal
+	}
+	switch {
+	case awsErr.ErrorCode() == DryRunOperation:
+		return codes.OK
+	case awsErr.ErrorCode() == RequestLimitExceeded, awsErr.ErrorFault() == smithy.FaultServer:
+		return codes.Internal
+	case GetMCMErrorCodeForCreateMachine(err) == codes.ResourceExhausted:
+		return codes.ResourceExhausted
+	}
+	return codes.InvalidArgument
+}
+
+// HasErrorCode checks if the provider returned an error with one of the given error codes
+func HasErrorCode(err error, errorCodes ...string) bool {
+	var awsErr smithy.APIError
+	if errors.As(err, &awsErr) {
+		for _, errorCode := range errorCodes {
+			if awsErr.ErrorCode() == errorCode {
+				return true
+			}
+		}
+	}
+	return false
+}
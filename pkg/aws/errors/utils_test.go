@@ -5,8 +5,12 @@
 package errors
 
 import (
+	"fmt"
 	"testing"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
 	"github.com/aws/smithy-go"
 	"github.com/gardener/machine-controller-manager/pkg/util/provider/machinecodes/codes"
 	. "github.com/onsi/gomega"
@@ -29,6 +33,11 @@ func TestGetMCMErrorCodeForCreateMachine(t *testing.T) {
 		{inputError: &smithy.GenericAPIError{Code: "MaxIOPSLimitExceeded"}, expectedCode: codes.ResourceExhausted},
 		{inputError: &smithy.GenericAPIError{Code: "RouteLimitExceeded"}, expectedCode: codes.ResourceExhausted},
 		{inputError: &smithy.GenericAPIError{Code: "Unsupported"}, expectedCode: codes.ResourceExhausted},
+		{inputError: &smithy.GenericAPIError{Code: "InsufficientHostCapacity"}, expectedCode: codes.ResourceExhausted},
+		{inputError: &smithy.GenericAPIError{Code: "HostLimitExceeded"}, expectedCode: codes.ResourceExhausted},
+		{inputError: &smithy.GenericAPIError{Code: "ReservationCapacityExceeded"}, expectedCode: codes.ResourceExhausted},
+		{inputError: &smithy.GenericAPIError{Code: "InvalidCapacityReservationId.NotFound"}, expectedCode: codes.InvalidArgument},
+		{inputError: &smithy.GenericAPIError{Code: "UnsupportedOperation", Message: "The instance type i3.large does not support simplified automatic recovery."}, expectedCode: codes.Internal},
 
 		{inputError: &smithy.GenericAPIError{Code: "unknown error"}, expectedCode: codes.Internal},
 		{inputError: &smithy.GenericAPIError{Code: "SomeOtherError"}, expectedCode: codes.Internal},
@@ -39,6 +48,29 @@ func TestGetMCMErrorCodeForCreateMachine(t *testing.T) {
 	}
 }
 
+func TestGetMCMErrorCodeForRunInstances(t *testing.T) {
+	g := NewWithT(t)
+
+	unsupported := &smithy.GenericAPIError{Code: "UnsupportedOperation", Message: "The instance type i3.large does not support simplified automatic recovery."}
+	invalidValue := &smithy.GenericAPIError{Code: "InvalidParameterValue", Message: "The specified value is not valid."}
+	for _, input := range []*ec2.RunInstancesInput{
+		{MaintenanceOptions: &ec2types.InstanceMaintenanceOptionsRequest{AutoRecovery: ec2types.InstanceAutoRecoveryStateDisabled}},
+		{LicenseSpecifications: []ec2types.LicenseConfigurationRequest{{LicenseConfigurationArn: aws.String("arn:aws:license-manager:eu-west-1:123456789012:license-configuration:lic-0123456789abcdef0")}}},
+		{Placement: &ec2types.Placement{HostResourceGroupArn: aws.String("arn:aws:resource-groups:eu-west-1:123456789012:group/byol")}},
+	} {
+		g.Expect(GetMCMErrorCodeForRunInstances(unsupported, input)).To(Equal(codes.InvalidArgument))
+		g.Expect(GetMCMErrorCodeForRunInstances(invalidValue, input)).To(Equal(codes.InvalidArgument))
+		g.Expect(GetMCMErrorCodeForRunInstances(&smithy.GenericAPIError{Code: "InsufficientInstanceCapacity"}, input)).To(Equal(codes.ResourceExhausted))
+		g.Expect(GetMCMErrorCodeForRunInstances(&smithy.GenericAPIError{Code: "InternalError"}, input)).To(Equal(codes.Internal))
+	}
+
+	// Without such options, the same errors are decided by their code only, independent of their message
+	plain := &ec2.RunInstancesInput{Placement: &ec2types.Placement{Tenancy: ec2types.TenancyHost}}
+	g.Expect(GetMCMErrorCodeForRunInstances(unsupported, plain)).To(Equal(codes.Internal))
+	g.Expect(GetMCMErrorCodeForRunInstances(invalidValue, plain)).To(Equal(codes.Internal))
+	g.Expect(GetMCMErrorCodeForRunInstances(&smithy.GenericAPIError{Code: "InsufficientInstanceCapacity"}, plain)).To(Equal(codes.ResourceExhausted))
+}
+
 func TestGetMCMErrorCodeForTerminateInstances(t *testing.T) {
 	table := []input{
 		{inputError: &smithy.GenericAPIError{Code: "InvalidInstanceID.NotFound"}, expectedCode: codes.NotFound},
@@ -49,3 +81,20 @@ func TestGetMCMErrorCodeForTerminateInstances(t *testing.T) {
 		g.Expect(GetMCMErrorCodeForTerminateInstances(entry.inputError)).To(Equal(entry.expectedCode))
 	}
 }
+
+func TestGetMCMErrorCodeForDryRun(t *testing.T) {
+	table := []input{
+		{inputError: nil, expectedCode: codes.OK},
+		{inputError: &smithy.GenericAPIError{Code: "DryRunOperation"}, expectedCode: codes.OK},
+		{inputError: &smithy.GenericAPIError{Code: "InvalidParameterValue"}, expectedCode: codes.InvalidArgument},
+		{inputError: &smithy.GenericAPIError{Code: "UnauthorizedOperation"}, expectedCode: codes.InvalidArgument},
+		{inputError: &smithy.GenericAPIError{Code: "VcpuLimitExceeded"}, expectedCode: codes.ResourceExhausted},
+		{inputError: &smithy.GenericAPIError{Code: "RequestLimitExceeded"}, expectedCode: codes.Internal},
+		{inputError: &smithy.GenericAPIError{Code: "InternalError", Fault: smithy.FaultServer}, expectedCode: codes.Internal},
+		{inputError: fmt.Errorf("connection reset"), expectedCode: codes.Internal},
+	}
+	g := NewWithT(t)
+	for _, entry := range table {
+		g.Expect(GetMCMErrorCodeForDryRun(entry.inputError)).To(Equal(entry.expectedCode))
+	}
+}
@@ -0,0 +1,183 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package instancetypes provides the capabilities of EC2 instance types, which are needed to validate a providerSpec
+// against its machine type. The capabilities are looked up with DescribeInstanceTypes and cached, an embedded offline
+// snapshot is used if the lookup fails (e.g. because the credentials lack the permission).
+package instancetypes
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
+
+	"github.com/gardener/machine-controller-manager-provider-aws/pkg/aws/interfaces"
+	"github.com/gardener/machine-controller-manager-provider-aws/pkg/instrument"
+)
+
+const describeInstanceTypesServiceLabel = "instance_type_describe"
+
+// DefaultTTL is the duration for which looked up instance types are cached by default.
+const DefaultTTL = 24 * time.Hour
+
+// Info is the subset of ec2types.InstanceTypeInfo which is relevant for the driver. Empty lists mean that the
+// capability is unknown and must not be validated.
+type Info struct {
+	// InstanceType is the name of the instance type, e.g. "m5.large".
+	InstanceType string `json:"instanceType"`
+	// SupportedArchitectures are the processor architectures supported by the instance type.
+	SupportedArchitectures []string `json:"supportedArchitectures,omitempty"`
+	// DefaultVCpus is the default number of vCPUs.
+	DefaultVCpus int32 `json:"defaultVCpus,omitempty"`
+	// MemoryMiB is the size of the memory in MiB.
+	MemoryMiB int64 `json:"memoryMiB,omitempty"`
+	// ValidCores are the valid numbers of cores which can be configured.
+	ValidCores []int32 `json:"validCores,omitempty"`
+	// ValidThreadsPerCore are the valid numbers of threads per core which can be configured.
+	ValidThreadsPerCore []int32 `json:"validThreadsPerCore,omitempty"`
+	// AmdSevSnpSupported indicates whether AMD SEV-SNP is supported.
+	AmdSevSnpSupported bool `json:"amdSevSnpSupported,omitempty"`
+	// BurstablePerformanceSupported indicates whether the instance type is a burstable performance instance type.
+	BurstablePerformanceSupported bool `json:"burstablePerformanceSupported,omitempty"`
+	// HibernationSupported indicates whether the instance can be hibernated.
+	HibernationSupported bool `json:"hibernationSupported,omitempty"`
+	// MaximumNetworkInterfaces is the maximum number of network interfaces of an instance.
+	MaximumNetworkInterfaces int32 `json:"maximumNetworkInterfaces,omitempty"`
+	// NetworkCards are the network cards of the instance type, ordered by their index.
+	NetworkCards []NetworkCard `json:"networkCards,omitempty"`
+	// EfaSupported indicates whether Elastic Fabric Adapters are supported.
+	EfaSupported bool `json:"efaSupported,omitempty"`
+	// MaximumEfaInterfaces is the maximum number of EFA interfaces of an instance.
+	MaximumEfaInterfaces int32 `json:"maximumEfaInterfaces,omitempty"`
+}
+
+// NetworkCard describes a network card of an instance type.
+type NetworkCard struct {
+	// NetworkCardIndex is the index of the network card.
+	NetworkCardIndex int32 `json:"networkCardIndex"`
+	// MaximumNetworkInterfaces is the maximum number of network interfaces attached to the network card.
+	MaximumNetworkInterfaces int32 `json:"maximumNetworkInterfaces"`
+}
+
+// FromEC2 converts the instance type information returned by DescribeInstanceTypes.
+func FromEC2(in ec2types.InstanceTypeInfo) *Info {
+	info := &Info{
+		InstanceType:                  string(in.InstanceType),
+		BurstablePerformanceSupported: ptr.Deref(in.BurstablePerformanceSupported, false),
+		HibernationSupported:          ptr.Deref(in.HibernationSupported, false),
+	}
+	if in.MemoryInfo != nil {
+		info.MemoryMiB = ptr.Deref(in.MemoryInfo.SizeInMiB, 0)
+	}
+	if in.ProcessorInfo != nil {
+		for _, arch := range in.ProcessorInfo.SupportedArchitectures {
+			info.SupportedArchitectures = append(info.SupportedArchitectures, string(arch))
+		}
+		info.AmdSevSnpSupported = slices.Contains(in.ProcessorInfo.SupportedFeatures, ec2types.SupportedAdditionalProcessorFeatureAmdSevSnp)
+	}
+	if in.VCpuInfo != nil {
+		info.DefaultVCpus = ptr.Deref(in.VCpuInfo.DefaultVCpus, 0)
+		info.ValidCores = in.VCpuInfo.ValidCores
+		info.ValidThreadsPerCore = in.VCpuInfo.ValidThreadsPerCore
+	}
+	if in.NetworkInfo != nil {
+		info.MaximumNetworkInterfaces = ptr.Deref(in.NetworkInfo.MaximumNetworkInterfaces, 0)
+		for _, card := range in.NetworkInfo.NetworkCards {
+			info.NetworkCards = append(info.NetworkCards, NetworkCard{
+				NetworkCardIndex:         ptr.Deref(card.NetworkCardIndex, 0),
+				MaximumNetworkInterfaces: ptr.Deref(card.MaximumNetworkInterfaces, 0),
+			})
+		}
+		slices.SortFunc(info.NetworkCards, func(a, b NetworkCard) int { return int(a.NetworkCardIndex - b.NetworkCardIndex) })
+		info.EfaSupported = ptr.Deref(in.NetworkInfo.EfaSupported, false)
+		if in.NetworkInfo.EfaInfo != nil {
+			info.MaximumEfaInterfaces = ptr.Deref(in.NetworkInfo.EfaInfo.MaximumEfaInterfaces, 0)
+		}
+	}
+	return info
+}
+
+// NetworkCard returns the network card with the given index, or nil if the instance type has no such card.
+func (i *Info) NetworkCard(index int32) *NetworkCard {
+	for _, card := range i.NetworkCards {
+		if card.NetworkCardIndex == index {
+			return &card
+		}
+	}
+	return nil
+}
+
+type catalogEntry struct {
+	info       *Info
+	validUntil time.Time
+}
+
+// Catalog caches the capabilities of instance types per region.
+type Catalog struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]catalogEntry
+}
+
+// NewCatalog returns an empty Catalog caching instance types for the given duration.
+func NewCatalog(ttl time.Duration) *Catalog {
+	return &Catalog{
+		ttl:     ttl,
+		entries: make(map[string]catalogEntry),
+	}
+}
+
+// Get returns the capabilities of the instance type in the region. They are looked up with DescribeInstanceTypes if
+// not cached, the embedded snapshot is used if the lookup fails. nil is returned if the instance type is unknown. A nil
+// Catalog looks up the instance type without caching it.
+func (c *Catalog) Get(ctx context.Context, client interfaces.Ec2Client, region, instanceType string) *Info {
+	key := region + "/" + instanceType
+	if c != nil {
+		c.mu.Lock()
+		entry, ok := c.entries[key]
+		c.mu.Unlock()
+		if ok && time.Now().Before(entry.validUntil) {
+			return entry.info
+		}
+	}
+
+	info, err := describeInstanceType(ctx, client, instanceType)
+	if err != nil {
+		klog.Warningf("Could not describe instance type %q in region %q, falling back to the offline snapshot: %v", instanceType, region, err)
+		info = Snapshot(instanceType)
+	}
+	if info == nil {
+		klog.V(3).Infof("Instance type %q is unknown in region %q, its capabilities are not validated", instanceType, region)
+	}
+
+	if c != nil {
+		c.mu.Lock()
+		c.entries[key] = catalogEntry{info: info, validUntil: time.Now().Add(c.ttl)}
+		c.mu.Unlock()
+	}
+	return info
+}
+
+func describeInstanceType(ctx context.Context, client interfaces.Ec2Client, instanceType string) (info *Info, err error) {
+	defer instrument.AwsAPIMetricRecorderFn(describeInstanceTypesServiceLabel, &err)()
+
+	output, err := client.DescribeInstanceTypes(ctx, &ec2.DescribeInstanceTypesInput{
+		InstanceTypes: []ec2types.InstanceType{ec2types.InstanceType(instanceType)},
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, instanceTypeInfo := range output.InstanceTypes {
+		if string(instanceTypeInfo.InstanceType) == instanceType {
+			return FromEC2(instanceTypeInfo), nil
+		}
+	}
+	return nil, nil
+}
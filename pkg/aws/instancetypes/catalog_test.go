@@ -0,0 +1,102 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package instancetypes
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	. "github.com/onsi/gomega"
+	"k8s.io/utils/ptr"
+
+	"github.com/gardener/machine-controller-manager-provider-aws/pkg/mockclient"
+)
+
+func newClient(instanceTypes ...ec2types.InstanceTypeInfo) *mockclient.MockEC2Client {
+	return &mockclient.MockEC2Client{FakeInstanceTypes: instanceTypes}
+}
+
+func TestSnapshotIsValid(t *testing.T) {
+	g := NewWithT(t)
+
+	var infos []*Info
+	g.Expect(json.Unmarshal(snapshotData, &infos)).To(Succeed())
+	g.Expect(infos).ToNot(BeEmpty())
+	for _, info := range infos {
+		g.Expect(info.InstanceType).ToNot(BeEmpty())
+		g.Expect(info.MaximumNetworkInterfaces).To(BeNumerically(">", 0), info.InstanceType)
+		g.Expect(info.NetworkCards).ToNot(BeEmpty(), info.InstanceType)
+		g.Expect(Snapshot(info.InstanceType)).To(Equal(info))
+	}
+	g.Expect(Snapshot("unknown.large")).To(BeNil())
+}
+
+func TestFromEC2(t *testing.T) {
+	g := NewWithT(t)
+
+	info := FromEC2(ec2types.InstanceTypeInfo{
+		InstanceType:  "p5.48xlarge",
+		ProcessorInfo: &ec2types.ProcessorInfo{SupportedArchitectures: []ec2types.ArchitectureType{ec2types.ArchitectureTypeX8664}},
+		VCpuInfo:      &ec2types.VCpuInfo{DefaultVCpus: ptr.To[int32](192), ValidThreadsPerCore: []int32{1, 2}},
+		NetworkInfo: &ec2types.NetworkInfo{
+			MaximumNetworkInterfaces: ptr.To[int32](64),
+			NetworkCards: []ec2types.NetworkCardInfo{
+				{NetworkCardIndex: ptr.To[int32](1), MaximumNetworkInterfaces: ptr.To[int32](2)},
+				{NetworkCardIndex: ptr.To[int32](0), MaximumNetworkInterfaces: ptr.To[int32](2)},
+			},
+			EfaSupported: ptr.To(true),
+			EfaInfo:      &ec2types.EfaInfo{MaximumEfaInterfaces: ptr.To[int32](32)},
+		},
+	})
+
+	g.Expect(info).To(Equal(&Info{
+		InstanceType:             "p5.48xlarge",
+		SupportedArchitectures:   []string{"x86_64"},
+		DefaultVCpus:             192,
+		ValidThreadsPerCore:      []int32{1, 2},
+		MaximumNetworkInterfaces: 64,
+		NetworkCards:             []NetworkCard{{NetworkCardIndex: 0, MaximumNetworkInterfaces: 2}, {NetworkCardIndex: 1, MaximumNetworkInterfaces: 2}},
+		EfaSupported:             true,
+		MaximumEfaInterfaces:     32,
+	}))
+	g.Expect(info.NetworkCard(1)).To(Equal(&NetworkCard{NetworkCardIndex: 1, MaximumNetworkInterfaces: 2}))
+	g.Expect(info.NetworkCard(2)).To(BeNil())
+}
+
+func TestCatalogGet(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+	catalog := NewCatalog(time.Hour)
+
+	// Described instance types take precedence over the snapshot
+	info := catalog.Get(ctx, newClient(ec2types.InstanceTypeInfo{InstanceType: "m5.large", VCpuInfo: &ec2types.VCpuInfo{ValidCores: []int32{42}}}), "eu-west-1", "m5.large")
+	g.Expect(info.ValidCores).To(Equal([]int32{42}))
+
+	// Cached per region
+	g.Expect(catalog.Get(ctx, newClient(), "eu-west-1", "m5.large").ValidCores).To(Equal([]int32{42}))
+	g.Expect(catalog.Get(ctx, newClient(), "eu-central-1", "m5.large")).To(Equal(Snapshot("m5.large")))
+
+	// Unknown instance types are not validated
+	g.Expect(catalog.Get(ctx, newClient(), "eu-west-1", "unknown.large")).To(BeNil())
+}
+
+func TestCatalogGetExpires(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+	catalog := NewCatalog(0)
+
+	g.Expect(catalog.Get(ctx, newClient(ec2types.InstanceTypeInfo{InstanceType: "m5.large"}), "eu-west-1", "m5.large").ValidCores).To(BeEmpty())
+	g.Expect(catalog.Get(ctx, newClient(), "eu-west-1", "m5.large")).To(Equal(Snapshot("m5.large")))
+}
+
+func TestNilCatalogGet(t *testing.T) {
+	g := NewWithT(t)
+
+	var catalog *Catalog
+	g.Expect(catalog.Get(context.Background(), newClient(), "eu-west-1", "m5.large")).To(Equal(Snapshot("m5.large")))
+}
@@ -0,0 +1,41 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package instancetypes
+
+import (
+	// embed is used for the offline snapshot
+	_ "embed"
+	"encoding/json"
+	"sync"
+)
+
+// SnapshotFileName is the name of the embedded offline snapshot. It can be refreshed with
+// `go run update_snapshot.go --region <region>`, which needs credentials permitted to describe instance types.
+const SnapshotFileName = "snapshot.json"
+
+//go:embed snapshot.json
+var snapshotData []byte
+
+var (
+	snapshotOnce sync.Once
+	snapshot     map[string]*Info
+)
+
+// Snapshot returns the capabilities of the instance type from the embedded offline snapshot, or nil if the instance
+// type is not part of it.
+func Snapshot(instanceType string) *Info {
+	snapshotOnce.Do(func() {
+		var infos []*Info
+		if err := json.Unmarshal(snapshotData, &infos); err != nil {
+			// The snapshot is verified by a unit test
+			panic(err)
+		}
+		snapshot = make(map[string]*Info, len(infos))
+		for _, info := range infos {
+			snapshot[info.InstanceType] = info
+		}
+	})
+	return snapshot[instanceType]
+}
@@ -0,0 +1,66 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build ignore
+
+// update_snapshot refreshes the embedded offline snapshot of the instancetypes package with the instance types of a
+// region. Credentials are taken from the default AWS configuration chain.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/spf13/pflag"
+
+	"github.com/gardener/machine-controller-manager-provider-aws/pkg/aws/instancetypes"
+)
+
+func main() {
+	region := pflag.String("region", "eu-west-1", "Region whose instance types are written into the snapshot")
+	families := pflag.StringSlice("families", nil, "If set, only instance types of these families (e.g. m5, p5) are written into the snapshot")
+	pflag.Parse()
+
+	if err := run(context.Background(), *region, *families); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, region string, families []string) error {
+	cfg, err := config.LoadDefaultConfig(ctx, config.WithRegion(region))
+	if err != nil {
+		return err
+	}
+	client := ec2.NewFromConfig(cfg)
+
+	var infos []*instancetypes.Info
+	paginator := ec2.NewDescribeInstanceTypesPaginator(client, &ec2.DescribeInstanceTypesInput{})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return err
+		}
+		for _, instanceType := range page.InstanceTypes {
+			family, _, _ := strings.Cut(string(instanceType.InstanceType), ".")
+			if len(families) > 0 && !slices.Contains(families, family) {
+				continue
+			}
+			infos = append(infos, instancetypes.FromEC2(instanceType))
+		}
+	}
+	slices.SortFunc(infos, func(a, b *instancetypes.Info) int { return strings.Compare(a.InstanceType, b.InstanceType) })
+
+	data, err := json.MarshalIndent(infos, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(instancetypes.SnapshotFileName, append(data, '\n'), 0600)
+}
@@ -0,0 +1,116 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package instancetypes
+
+import (
+	"fmt"
+	"slices"
+
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/ptr"
+
+	awsapi "github.com/gardener/machine-controller-manager-provider-aws/pkg/aws/apis"
+)
+
+// ValidateProviderSpec validates the CPU options, credit specification, hibernation options and network interfaces of
+// the providerSpec against the capabilities of its machine type, which must be described by info.
+func ValidateProviderSpec(info *Info, spec *awsapi.AWSProviderSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	allErrs = append(allErrs, validateCPUOptions(info, spec.CPUOptions, fldPath.Child("cpuOptions"))...)
+	allErrs = append(allErrs, validateNetworkInterfaces(info, spec.NetworkInterfaces, fldPath.Child("networkInterfaces"))...)
+	if spec.CreditSpecification != nil && !info.BurstablePerformanceSupported {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("creditSpecification"),
+			fmt.Sprintf("instance type %s is not a burstable performance instance type", info.InstanceType)))
+	}
+	allErrs = append(allErrs, validateHibernationOptions(info, spec, fldPath)...)
+	return allErrs
+}
+
+// validateHibernationOptions verifies that the instance type can be hibernated and that its memory fits into the root
+// volume, which also holds the operating system.
+func validateHibernationOptions(info *Info, spec *awsapi.AWSProviderSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if spec.HibernationOptions == nil || !spec.HibernationOptions.Configured {
+		return allErrs
+	}
+
+	if !info.HibernationSupported {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("hibernationOptions", "configured"), true,
+			fmt.Sprintf("instance type %s does not support hibernation", info.InstanceType)))
+		return allErrs
+	}
+	if rootIdx := spec.RootBlockDeviceIndex(); rootIdx >= 0 && info.MemoryMiB > 0 {
+		volumeSize := spec.BlockDevices[rootIdx].Ebs.VolumeSize
+		if int64(volumeSize)*1024 <= info.MemoryMiB {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("blockDevices").Index(rootIdx).Child("ebs", "volumeSize"), volumeSize,
+				fmt.Sprintf("the root volume must be larger than the %d MiB memory of instance type %s for hibernation", info.MemoryMiB, info.InstanceType)))
+		}
+	}
+	return allErrs
+}
+
+func validateCPUOptions(info *Info, cpuOptions *awsapi.CPUOptions, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if cpuOptions == nil {
+		return allErrs
+	}
+
+	if cpuOptions.CoreCount != nil && len(info.ValidCores) > 0 && !slices.Contains(info.ValidCores, *cpuOptions.CoreCount) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("coreCount"), *cpuOptions.CoreCount,
+			fmt.Sprintf("instance type %s supports the core counts %v", info.InstanceType, info.ValidCores)))
+	}
+	if cpuOptions.ThreadsPerCore != nil && len(info.ValidThreadsPerCore) > 0 && !slices.Contains(info.ValidThreadsPerCore, *cpuOptions.ThreadsPerCore) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("threadsPerCore"), *cpuOptions.ThreadsPerCore,
+			fmt.Sprintf("instance type %s supports the threads per core %v", info.InstanceType, info.ValidThreadsPerCore)))
+	}
+	if ptr.Deref(cpuOptions.AmdSevSnp, "") == string(ec2types.AmdSevSnpSpecificationEnabled) && !info.AmdSevSnpSupported {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("amdSevSnp"), *cpuOptions.AmdSevSnp,
+			fmt.Sprintf("instance type %s does not support AMD SEV-SNP", info.InstanceType)))
+	}
+	return allErrs
+}
+
+func validateNetworkInterfaces(info *Info, networkInterfaces []awsapi.AWSNetworkInterfaceSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if info.MaximumNetworkInterfaces > 0 && len(networkInterfaces) > int(info.MaximumNetworkInterfaces) {
+		allErrs = append(allErrs, field.TooMany(fldPath, len(networkInterfaces), int(info.MaximumNetworkInterfaces)))
+	}
+
+	interfacesPerCard := map[int32]int{}
+	efaInterfaces := 0
+	for i, netIf := range networkInterfaces {
+		idxPath := fldPath.Index(i)
+		cardIndex := ptr.Deref(netIf.NetworkCardIndex, 0)
+
+		if len(info.NetworkCards) > 0 {
+			card := info.NetworkCard(cardIndex)
+			if card == nil {
+				allErrs = append(allErrs, field.Invalid(idxPath.Child("networkCardIndex"), cardIndex,
+					fmt.Sprintf("instance type %s has %d network cards", info.InstanceType, len(info.NetworkCards))))
+			} else {
+				interfacesPerCard[cardIndex]++
+				if interfacesPerCard[cardIndex] == int(card.MaximumNetworkInterfaces)+1 {
+					allErrs = append(allErrs, field.Invalid(idxPath.Child("networkCardIndex"), cardIndex,
+						fmt.Sprintf("instance type %s supports at most %d network interfaces on network card %d", info.InstanceType, card.MaximumNetworkInterfaces, cardIndex)))
+				}
+			}
+		}
+
+		interfaceType := ptr.Deref(netIf.InterfaceType, "")
+		if interfaceType == string(ec2types.NetworkInterfaceTypeEfa) || interfaceType == string(ec2types.NetworkInterfaceTypeEfaOnly) {
+			efaInterfaces++
+			if !info.EfaSupported {
+				allErrs = append(allErrs, field.Invalid(idxPath.Child("interfaceType"), interfaceType,
+					fmt.Sprintf("instance type %s does not support Elastic Fabric Adapters", info.InstanceType)))
+			} else if info.MaximumEfaInterfaces > 0 && efaInterfaces == int(info.MaximumEfaInterfaces)+1 {
+				allErrs = append(allErrs, field.Invalid(idxPath.Child("interfaceType"), interfaceType,
+					fmt.Sprintf("instance type %s supports at most %d EFA interfaces", info.InstanceType, info.MaximumEfaInterfaces)))
+			}
+		}
+	}
+	return allErrs
+}
@@ -0,0 +1,114 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package instancetypes
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/ptr"
+
+	awsapi "github.com/gardener/machine-controller-manager-provider-aws/pkg/aws/apis"
+)
+
+func errorFields(allErrs field.ErrorList) []string {
+	var fields []string
+	for _, err := range allErrs {
+		fields = append(fields, err.Field)
+	}
+	return fields
+}
+
+func networkInterfaces(count int, cardIndex int32, interfaceType string) []awsapi.AWSNetworkInterfaceSpec {
+	var netIfs []awsapi.AWSNetworkInterfaceSpec
+	for range count {
+		netIf := awsapi.AWSNetworkInterfaceSpec{SubnetID: "subnet-1", NetworkCardIndex: ptr.To(cardIndex)}
+		if interfaceType != "" {
+			netIf.InterfaceType = ptr.To(interfaceType)
+		}
+		netIfs = append(netIfs, netIf)
+	}
+	return netIfs
+}
+
+func TestValidateProviderSpecCPUOptions(t *testing.T) {
+	g := NewWithT(t)
+	fldPath := field.NewPath("providerSpec")
+
+	spec := &awsapi.AWSProviderSpec{CPUOptions: &awsapi.CPUOptions{CoreCount: ptr.To[int32](1), ThreadsPerCore: ptr.To[int32](2)}}
+	g.Expect(ValidateProviderSpec(Snapshot("m5.large"), spec, fldPath)).To(BeEmpty())
+
+	spec.CPUOptions.CoreCount = ptr.To[int32](2)
+	spec.CPUOptions.AmdSevSnp = ptr.To("enabled")
+	g.Expect(errorFields(ValidateProviderSpec(Snapshot("m5.large"), spec, fldPath))).To(ConsistOf(
+		"providerSpec.cpuOptions.coreCount",
+		"providerSpec.cpuOptions.amdSevSnp",
+	))
+	g.Expect(ValidateProviderSpec(Snapshot("m6a.xlarge"), spec, fldPath)).To(BeEmpty())
+
+	spec.CPUOptions.ThreadsPerCore = ptr.To[int32](2)
+	g.Expect(errorFields(ValidateProviderSpec(Snapshot("m6g.large"), spec, fldPath))).To(ConsistOf(
+		"providerSpec.cpuOptions.threadsPerCore",
+		"providerSpec.cpuOptions.amdSevSnp",
+	))
+}
+
+func TestValidateProviderSpecNetworkInterfaces(t *testing.T) {
+	g := NewWithT(t)
+	fldPath := field.NewPath("providerSpec")
+
+	spec := &awsapi.AWSProviderSpec{NetworkInterfaces: networkInterfaces(3, 0, "")}
+	g.Expect(ValidateProviderSpec(Snapshot("m5.large"), spec, fldPath)).To(BeEmpty())
+
+	spec.NetworkInterfaces = networkInterfaces(4, 0, "")
+	g.Expect(errorFields(ValidateProviderSpec(Snapshot("m5.large"), spec, fldPath))).To(ConsistOf(
+		"providerSpec.networkInterfaces",
+		"providerSpec.networkInterfaces[3].networkCardIndex",
+	))
+
+	spec.NetworkInterfaces = networkInterfaces(1, 1, "")
+	g.Expect(errorFields(ValidateProviderSpec(Snapshot("m5.large"), spec, fldPath))).To(ConsistOf("providerSpec.networkInterfaces[0].networkCardIndex"))
+
+	spec.NetworkInterfaces = append(networkInterfaces(1, 0, "efa"), networkInterfaces(2, 1, "efa-only")...)
+	g.Expect(errorFields(ValidateProviderSpec(Snapshot("m5.large"), spec, fldPath))).To(ConsistOf(
+		"providerSpec.networkInterfaces[0].interfaceType",
+		"providerSpec.networkInterfaces[1].networkCardIndex",
+		"providerSpec.networkInterfaces[1].interfaceType",
+		"providerSpec.networkInterfaces[2].networkCardIndex",
+		"providerSpec.networkInterfaces[2].interfaceType",
+	))
+	g.Expect(ValidateProviderSpec(Snapshot("p5.48xlarge"), spec, fldPath)).To(BeEmpty())
+
+	spec.NetworkInterfaces = networkInterfaces(2, 0, "efa")
+	g.Expect(errorFields(ValidateProviderSpec(Snapshot("c5n.18xlarge"), spec, fldPath))).To(ConsistOf("providerSpec.networkInterfaces[1].interfaceType"))
+}
+
+func TestValidateProviderSpecCreditSpecification(t *testing.T) {
+	g := NewWithT(t)
+	fldPath := field.NewPath("providerSpec")
+
+	spec := &awsapi.AWSProviderSpec{CreditSpecification: &awsapi.AWSCreditSpecification{CPUCredits: "unlimited"}}
+	g.Expect(ValidateProviderSpec(Snapshot("t3.medium"), spec, fldPath)).To(BeEmpty())
+	g.Expect(ValidateProviderSpec(Snapshot("t4g.medium"), spec, fldPath)).To(BeEmpty())
+	g.Expect(errorFields(ValidateProviderSpec(Snapshot("m5.large"), spec, fldPath))).To(ConsistOf("providerSpec.creditSpecification"))
+}
+
+func TestValidateProviderSpecHibernationOptions(t *testing.T) {
+	g := NewWithT(t)
+	fldPath := field.NewPath("providerSpec")
+
+	spec := &awsapi.AWSProviderSpec{
+		BlockDevices: []awsapi.AWSBlockDeviceMappingSpec{
+			{Ebs: awsapi.AWSEbsBlockDeviceSpec{Encrypted: true, VolumeSize: 50}},
+		},
+		HibernationOptions: &awsapi.AWSHibernationOptions{Configured: true},
+	}
+	g.Expect(ValidateProviderSpec(Snapshot("m5.large"), spec, fldPath)).To(BeEmpty())
+	g.Expect(errorFields(ValidateProviderSpec(Snapshot("p5.48xlarge"), spec, fldPath))).To(ConsistOf("providerSpec.hibernationOptions.configured"))
+
+	spec.BlockDevices[0].Ebs.VolumeSize = 8
+	g.Expect(errorFields(ValidateProviderSpec(Snapshot("m5.large"), spec, fldPath))).To(ConsistOf("providerSpec.blockDevices[0].ebs.volumeSize"))
+}
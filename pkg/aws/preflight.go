@@ -0,0 +1,307 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package aws
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/smithy-go"
+	v1alpha1 "github.com/gardener/machine-controller-manager/pkg/apis/machine/v1alpha1"
+	"github.com/gardener/machine-controller-manager/pkg/util/provider/machinecodes/codes"
+	"github.com/gardener/machine-controller-manager/pkg/util/provider/machinecodes/status"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
+
+	api "github.com/gardener/machine-controller-manager-provider-aws/pkg/aws/apis"
+	awserror "github.com/gardener/machine-controller-manager-provider-aws/pkg/aws/errors"
+	"github.com/gardener/machine-controller-manager-provider-aws/pkg/aws/interfaces"
+	"github.com/gardener/machine-controller-manager-provider-aws/pkg/instrument"
+)
+
+const preflightServiceLabel = "preflight_validation"
+
+// preflightResultTTL is the duration for which a successful pre-flight validation of a MachineClass is reused. Cloud
+// resources referenced by an unchanged MachineClass can still be deleted, so the result must not be kept forever.
+var preflightResultTTL = time.Hour
+
+// preflightCache remembers which MachineClasses passed the pre-flight validation, keyed by preflightHash.
+type preflightCache struct {
+	mu         sync.Mutex
+	validUntil map[string]time.Time
+}
+
+func newPreflightCache() *preflightCache {
+	return &preflightCache{validUntil: make(map[string]time.Time)}
+}
+
+// valid returns true if the MachineClass with the given hash passed the pre-flight validation recently.
+func (c *preflightCache) valid(hash string) bool {
+	if c == nil {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return time.Now().Before(c.validUntil[hash])
+}
+
+// add records a successful pre-flight validation of the MachineClass with the given hash and drops expired results.
+func (c *preflightCache) add(hash string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	for key, validUntil := range c.validUntil {
+		if !now.Before(validUntil) {
+			delete(c.validUntil, key)
+		}
+	}
+	c.validUntil[hash] = now.Add(preflightResultTTL)
+}
+
+// preflightHash returns a hash over everything the pre-flight validation of a MachineClass depends on, i.e. the
+// providerSpec and the credentials. The userData is excluded, since it changes frequently (e.g. with every bootstrap
+// token rotation) without affecting the referenced cloud resources.
+func preflightHash(machineClass *v1alpha1.MachineClass, secret *corev1.Secret) string {
+	h := sha256.New()
+	h.Write(machineClass.ProviderSpec.Raw)
+	keys := make([]string, 0, len(secret.Data))
+	for key := range secret.Data {
+		if key != "userData" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(h, "\x00%s\x00", key)
+		h.Write(secret.Data[key])
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// validateCloudReferences verifies the cloud resources referenced by the providerSpec, which cannot be checked by the
+// static validation:
+//   - the AMI exists, is shared with the account, is available and not deprecated
+//   - the architecture of the AMI is supported by the machine type
+//   - all subnets exist and are in the VPC of the security groups of the same network interface
+//   - network interfaces in IPv6-only subnets request IPv6 but no IPv4 addresses and use resource-name hostnames
+//
+// Invalid references are returned as InvalidArgument, failing describe calls as Internal.
+func validateCloudReferences(ctx context.Context, client interfaces.Ec2Client, providerSpec *api.AWSProviderSpec) (err error) {
+	defer instrument.AwsAPIMetricRecorderFn(preflightServiceLabel, &err)()
+
+	fldPath := field.NewPath("providerSpec")
+	allErrs := field.ErrorList{}
+
+	imageOutput, err := client.DescribeImages(ctx, &ec2.DescribeImagesInput{ImageIds: []string{providerSpec.AMI}})
+	if err != nil && !awserror.HasErrorCode(err, awserror.InvalidAMIIDNotFound) {
+		return status.Error(codes.Internal, err.Error())
+	}
+	var image *ec2types.Image
+	if err == nil && len(imageOutput.Images) > 0 {
+		image = &imageOutput.Images[0]
+	}
+	if image == nil {
+		allErrs = append(allErrs, field.NotFound(fldPath.Child("ami"), providerSpec.AMI+" (the image does not exist or is not shared with the account)"))
+	} else {
+		if image.State != "" && image.State != ec2types.ImageStateAvailable {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("ami"), providerSpec.AMI, fmt.Sprintf("image is in state %q", image.State)))
+		}
+		if deprecationTime, err := time.Parse(time.RFC3339, aws.ToString(image.DeprecationTime)); err == nil && !time.Now().Before(deprecationTime) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("ami"), providerSpec.AMI, fmt.Sprintf("image is deprecated since %s", deprecationTime.Format(time.RFC3339))))
+		}
+	}
+
+	instanceTypeOutput, err := client.DescribeInstanceTypes(ctx, &ec2.DescribeInstanceTypesInput{InstanceTypes: []ec2types.InstanceType{ec2types.InstanceType(providerSpec.MachineType)}})
+	switch {
+	case awserror.HasErrorCode(err, awserror.InvalidInstanceType) || (err == nil && len(instanceTypeOutput.InstanceTypes) == 0):
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("machineType"), providerSpec.MachineType, "instance type is not available in the region"))
+	case err != nil:
+		return status.Error(codes.Internal, err.Error())
+	case image != nil && image.Architecture != "" && instanceTypeOutput.InstanceTypes[0].ProcessorInfo != nil:
+		supported := instanceTypeOutput.InstanceTypes[0].ProcessorInfo.SupportedArchitectures
+		if !slices.Contains(supported, ec2types.ArchitectureType(image.Architecture)) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("machineType"), providerSpec.MachineType,
+				fmt.Sprintf("instance type supports the architectures %v, but image %s has architecture %q", supported, providerSpec.AMI, image.Architecture)))
+		}
+	}
+
+	subnets := map[string]*ec2types.Subnet{}
+	groupVPCs := map[string]string{}
+	for i, netIf := range providerSpec.NetworkInterfaces {
+		idxPath := fldPath.Child("networkInterfaces").Index(i)
+
+		// The subnet and security groups of existing network interfaces are given, interfaces selected by tags are
+		// looked up when the machine is created
+		if netIf.IsExisting() {
+			if netIf.NetworkInterfaceID != nil {
+				exists, err := networkInterfaceExists(ctx, client, *netIf.NetworkInterfaceID)
+				if err != nil {
+					return err
+				}
+				if !exists {
+					allErrs = append(allErrs, field.NotFound(idxPath.Child("networkInterfaceID"), *netIf.NetworkInterfaceID))
+				}
+			}
+			continue
+		}
+
+		subnet, ok := subnets[netIf.SubnetID]
+		if !ok {
+			subnet, err = describeSubnet(ctx, client, netIf.SubnetID)
+			if err != nil {
+				return err
+			}
+			subnets[netIf.SubnetID] = subnet
+		}
+		if subnet == nil {
+			allErrs = append(allErrs, field.NotFound(idxPath.Child("subnetID"), netIf.SubnetID))
+			continue
+		}
+		if aws.ToBool(subnet.Ipv6Native) {
+			allErrs = append(allErrs, validateIPv6OnlyNetworkInterface(netIf, providerSpec.PrivateDNSNameOptions, idxPath, fldPath)...)
+		}
+		subnetVPC := aws.ToString(subnet.VpcId)
+
+		for j, groupID := range netIf.SecurityGroupIDs {
+			groupVPC, ok := groupVPCs[groupID]
+			if !ok {
+				groupVPC, err = describeSecurityGroupVPC(ctx, client, groupID)
+				if err != nil {
+					return err
+				}
+				groupVPCs[groupID] = groupVPC
+			}
+			if groupVPC == "" {
+				allErrs = append(allErrs, field.NotFound(idxPath.Child("securityGroupIDs").Index(j), groupID))
+			} else if groupVPC != subnetVPC {
+				allErrs = append(allErrs, field.Invalid(idxPath.Child("securityGroupIDs").Index(j), groupID,
+					fmt.Sprintf("security group is in VPC %s, but subnet %s is in VPC %s", groupVPC, netIf.SubnetID, subnetVPC)))
+			}
+		}
+	}
+
+	if len(allErrs) > 0 {
+		return status.Error(codes.InvalidArgument, fmt.Sprintf("pre-flight validation of ProviderSpec failed %v", allErrs.ToAggregate().Error()))
+	}
+	return nil
+}
+
+// validateIPv6OnlyNetworkInterface verifies that a network interface in an IPv6-only subnet requests an IPv6 address
+// but no IPv4 addresses, and that the hostname of the instance is not derived from its IPv4 address.
+func validateIPv6OnlyNetworkInterface(netIf api.AWSNetworkInterfaceSpec, dnsOptions *api.AWSPrivateDNSNameOptions, idxPath, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	detail := fmt.Sprintf("subnet %s is IPv6-only", netIf.SubnetID)
+
+	if ptr.Deref(netIf.Ipv6AddressCount, 0) == 0 && netIf.IPv6CIDR == nil {
+		allErrs = append(allErrs, field.Required(idxPath.Child("ipv6AddressCount"), detail+", set ipv6AddressCount or ipv6CIDR"))
+	}
+	if ptr.Deref(netIf.AssociatePublicIPAddress, false) {
+		allErrs = append(allErrs, field.Forbidden(idxPath.Child("associatePublicIPAddress"), detail))
+	}
+	if netIf.PrivateIPv4CIDR != nil {
+		allErrs = append(allErrs, field.Forbidden(idxPath.Child("privateIPv4CIDR"), detail))
+	}
+	if ptr.Deref(netIf.SecondaryPrivateIPAddressCount, 0) > 0 {
+		allErrs = append(allErrs, field.Forbidden(idxPath.Child("secondaryPrivateIPAddressCount"), detail))
+	}
+	if ptr.Deref(netIf.Ipv4PrefixCount, 0) > 0 {
+		allErrs = append(allErrs, field.Forbidden(idxPath.Child("ipv4PrefixCount"), detail))
+	}
+
+	if dnsOptions != nil {
+		dnsPath := fldPath.Child("privateDNSNameOptions")
+		if hostnameType := ptr.Deref(dnsOptions.HostnameType, ""); hostnameType != "" && hostnameType != string(ec2types.HostnameTypeResourceName) {
+			allErrs = append(allErrs, field.Invalid(dnsPath.Child("hostnameType"), hostnameType, detail+", which requires hostname type \"resource-name\""))
+		}
+		if ptr.Deref(dnsOptions.EnableResourceNameDNSARecord, false) {
+			allErrs = append(allErrs, field.Forbidden(dnsPath.Child("enableResourceNameDNSARecord"), detail+", which has no IPv4 addresses"))
+		}
+	}
+	return allErrs
+}
+
+// describeSubnet returns the subnet, or nil if the subnet does not exist.
+func describeSubnet(ctx context.Context, client interfaces.Ec2Client, subnetID string) (*ec2types.Subnet, error) {
+	output, err := client.DescribeSubnets(ctx, &ec2.DescribeSubnetsInput{SubnetIds: []string{subnetID}})
+	if awserror.HasErrorCode(err, awserror.InvalidSubnetIDNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	if len(output.Subnets) == 0 {
+		return nil, nil
+	}
+	return &output.Subnets[0], nil
+}
+
+// networkInterfaceExists returns true if the network interface exists.
+func networkInterfaceExists(ctx context.Context, client interfaces.Ec2Client, networkInterfaceID string) (bool, error) {
+	output, err := client.DescribeNetworkInterfaces(ctx, &ec2.DescribeNetworkInterfacesInput{NetworkInterfaceIds: []string{networkInterfaceID}})
+	if awserror.HasErrorCode(err, awserror.InvalidNetworkInterfaceIDNotFound) {
+		return false, nil
+	} else if err != nil {
+		return false, status.Error(codes.Internal, err.Error())
+	}
+	return len(output.NetworkInterfaces) > 0, nil
+}
+
+// describeSecurityGroupVPC returns the VPC of the security group, or an empty string if the group does not exist.
+func describeSecurityGroupVPC(ctx context.Context, client interfaces.Ec2Client, groupID string) (string, error) {
+	output, err := client.DescribeSecurityGroups(ctx, &ec2.DescribeSecurityGroupsInput{GroupIds: []string{groupID}})
+	if awserror.HasErrorCode(err, awserror.InvalidGroupNotFound) {
+		return "", nil
+	} else if err != nil {
+		return "", status.Error(codes.Internal, err.Error())
+	}
+	if len(output.SecurityGroups) == 0 {
+		return "", nil
+	}
+	return aws.ToString(output.SecurityGroups[0].VpcId), nil
+}
+
+// dryRunInstance sends the RunInstances request with DryRun set, so that EC2 checks the permissions and all
+// references of the request which have no describe call of their own, e.g. the IAM instance profile.
+func dryRunInstance(ctx context.Context, client interfaces.Ec2Client, input *ec2.RunInstancesInput) (err error) {
+	defer instrument.AwsAPIMetricRecorderFn(preflightServiceLabel, &err)()
+
+	dryRunInput := *input
+	dryRunInput.DryRun = aws.Bool(true)
+	_, err = client.RunInstances(ctx, &dryRunInput)
+
+	code := awserror.GetMCMErrorCodeForDryRun(err)
+	if code == codes.OK {
+		return nil
+	}
+	msg := err.Error()
+	var awsErr smithy.APIError
+	if errors.As(err, &awsErr) && awsErr.ErrorCode() == awserror.InvalidParameterValue && strings.Contains(awsErr.ErrorMessage(), "iamInstanceProfile") {
+		iamPath := field.NewPath("providerSpec", "iam")
+		iamValue := aws.ToString(input.IamInstanceProfile.Name)
+		if iamValue == "" {
+			iamPath, iamValue = iamPath.Child("arn"), aws.ToString(input.IamInstanceProfile.Arn)
+		} else {
+			iamPath = iamPath.Child("name")
+		}
+		msg = field.NotFound(iamPath, iamValue).Error()
+	}
+	klog.V(2).Infof("Dry run of RunInstances failed: %s", msg)
+	return status.Error(code, fmt.Sprintf("pre-flight validation of ProviderSpec failed, dry run of RunInstances was rejected: %s", msg))
+}
@@ -0,0 +1,184 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package aws
+
+import (
+	"context"
+	"strings"
+
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/gardener/machine-controller-manager/pkg/util/provider/driver"
+	"github.com/gardener/machine-controller-manager/pkg/util/provider/machinecodes/codes"
+	"github.com/gardener/machine-controller-manager/pkg/util/provider/machinecodes/status"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+
+	"github.com/gardener/machine-controller-manager-provider-aws/pkg/mockclient"
+)
+
+var _ = Describe("Preflight", func() {
+	const providerSpecTemplate = `{"ami":"ami-123456789","blockDevices":[{"ebs":{"volumeSize":50,"volumeType":"gp2"}}],"iam":{"name":"IAM"},"machineType":"m5.large","networkInterfaces":[{"securityGroupIDs":["sg-1"],"subnetID":"subnet-1"}],"region":"eu-west-1","tags":{"kubernetes.io/cluster/shoot--test":"1","kubernetes.io/role/test":"1"}}`
+	providerSecret := &corev1.Secret{
+		Data: map[string][]byte{
+			"providerAccessKeyId":     []byte("dummy-id"),
+			"providerSecretAccessKey": []byte("dummy-secret"),
+			"userData":                []byte("dummy-user-data"),
+		},
+	}
+
+	var mockClientProvider *mockclient.MockClientProvider
+
+	BeforeEach(func() {
+		mockClientProvider = &mockclient.MockClientProvider{
+			FakeImages: []ec2types.Image{{
+				ImageId:        ptr.To("ami-123456789"),
+				RootDeviceName: ptr.To("/dev/xvda"),
+				Architecture:   ec2types.ArchitectureValuesX8664,
+				State:          ec2types.ImageStateAvailable,
+			}},
+			FakeInstanceTypes: []ec2types.InstanceTypeInfo{
+				{InstanceType: "m5.large", ProcessorInfo: &ec2types.ProcessorInfo{SupportedArchitectures: []ec2types.ArchitectureType{ec2types.ArchitectureTypeX8664}}},
+				{InstanceType: "m6g.large", ProcessorInfo: &ec2types.ProcessorInfo{SupportedArchitectures: []ec2types.ArchitectureType{ec2types.ArchitectureTypeArm64}}},
+			},
+			FakeSubnets: []ec2types.Subnet{
+				{SubnetId: ptr.To("subnet-1"), VpcId: ptr.To("vpc-1")},
+				{SubnetId: ptr.To("subnet-2"), VpcId: ptr.To("vpc-2")},
+			},
+			FakeSecurityGroups: []ec2types.SecurityGroup{
+				{GroupId: ptr.To("sg-1"), VpcId: ptr.To("vpc-1")},
+			},
+		}
+	})
+
+	createMachine := func(d driver.Driver, providerSpec string) error {
+		_, err := d.CreateMachine(context.Background(), &driver.CreateMachineRequest{
+			Machine:      newMachine(-1, nil),
+			MachineClass: newMachineClass([]byte(providerSpec)),
+			Secret:       providerSecret,
+		})
+		return err
+	}
+	expectInvalidArgument := func(err error, substrings ...string) {
+		Expect(err).To(HaveOccurred())
+		statusErr, ok := status.FromError(err)
+		Expect(ok).To(BeTrue())
+		Expect(statusErr.Code()).To(Equal(codes.InvalidArgument))
+		for _, substring := range substrings {
+			Expect(err.Error()).To(ContainSubstring(substring))
+		}
+	}
+
+	Describe("#CreateMachine", func() {
+		It("should create the machine if all references are valid", func() {
+			d := NewAWSDriverWithOptions(mockClientProvider, Options{PreflightValidation: true})
+
+			Expect(createMachine(d, providerSpecTemplate)).To(Succeed())
+		})
+
+		It("should not validate references if pre-flight validation is disabled", func() {
+			d := NewAWSDriver(mockClientProvider)
+
+			Expect(createMachine(d, strings.ReplaceAll(providerSpecTemplate, "subnet-1", "subnet-missing"))).To(Succeed())
+		})
+
+		It("should reject a subnet in another VPC than the security groups", func() {
+			d := NewAWSDriverWithOptions(mockClientProvider, Options{PreflightValidation: true})
+
+			err := createMachine(d, strings.ReplaceAll(providerSpecTemplate, "subnet-1", "subnet-2"))
+			expectInvalidArgument(err, "providerSpec.networkInterfaces[0].securityGroupIDs[0]", "security group is in VPC vpc-1, but subnet subnet-2 is in VPC vpc-2")
+			Expect(mockClientProvider.FakeInstances).To(BeEmpty())
+		})
+
+		It("should reject missing subnets and security groups", func() {
+			d := NewAWSDriverWithOptions(mockClientProvider, Options{PreflightValidation: true})
+
+			err := createMachine(d, strings.ReplaceAll(providerSpecTemplate, "subnet-1", "subnet-missing"))
+			expectInvalidArgument(err, "providerSpec.networkInterfaces[0].subnetID: Not found: \"subnet-missing\"")
+
+			err = createMachine(d, strings.ReplaceAll(providerSpecTemplate, "sg-1", "sg-missing"))
+			expectInvalidArgument(err, "providerSpec.networkInterfaces[0].securityGroupIDs[0]: Not found: \"sg-missing\"")
+		})
+
+		It("should reject a missing existing network interface", func() {
+			d := NewAWSDriverWithOptions(mockClientProvider, Options{PreflightValidation: true})
+
+			err := createMachine(d, strings.ReplaceAll(providerSpecTemplate, `{"securityGroupIDs":["sg-1"],"subnetID":"subnet-1"}`, `{"networkInterfaceID":"eni-missing"}`))
+			expectInvalidArgument(err, "providerSpec.networkInterfaces[0].networkInterfaceID: Not found: \"eni-missing\"")
+		})
+
+		It("should reject network interfaces in IPv6-only subnets requesting IPv4 addresses or ip-name hostnames", func() {
+			mockClientProvider.FakeSubnets[0].Ipv6Native = ptr.To(true)
+			d := NewAWSDriverWithOptions(mockClientProvider, Options{PreflightValidation: true})
+
+			err := createMachine(d, strings.ReplaceAll(providerSpecTemplate, `"subnetID":"subnet-1"}]`, `"subnetID":"subnet-1","secondaryPrivateIPAddressCount":1}],"privateDNSNameOptions":{"hostnameType":"ip-name"}`))
+			expectInvalidArgument(err,
+				"providerSpec.networkInterfaces[0].ipv6AddressCount: Required value: subnet subnet-1 is IPv6-only, set ipv6AddressCount or ipv6CIDR",
+				"providerSpec.networkInterfaces[0].secondaryPrivateIPAddressCount: Forbidden: subnet subnet-1 is IPv6-only",
+				`providerSpec.privateDNSNameOptions.hostnameType: Invalid value: "ip-name": subnet subnet-1 is IPv6-only, which requires hostname type "resource-name"`)
+
+			Expect(createMachine(d, strings.ReplaceAll(providerSpecTemplate, `"subnetID":"subnet-1"}]`, `"subnetID":"subnet-1","ipv6AddressCount":1}]`))).To(Succeed())
+		})
+
+		It("should reject an AMI whose architecture is not supported by the machine type", func() {
+			d := NewAWSDriverWithOptions(mockClientProvider, Options{PreflightValidation: true})
+
+			err := createMachine(d, strings.ReplaceAll(providerSpecTemplate, "m5.large", "m6g.large"))
+			expectInvalidArgument(err, "providerSpec.machineType", `image ami-123456789 has architecture "x86_64"`)
+		})
+
+		It("should reject a deprecated AMI", func() {
+			mockClientProvider.FakeImages[0].DeprecationTime = ptr.To("2020-01-01T00:00:00Z")
+			d := NewAWSDriverWithOptions(mockClientProvider, Options{PreflightValidation: true})
+
+			err := createMachine(d, providerSpecTemplate)
+			expectInvalidArgument(err, "providerSpec.ami", "image is deprecated since 2020-01-01T00:00:00Z")
+		})
+
+		It("should reject an AMI which is not shared with the account", func() {
+			d := NewAWSDriverWithOptions(mockClientProvider, Options{PreflightValidation: true})
+
+			err := createMachine(d, strings.ReplaceAll(providerSpecTemplate, "ami-123456789", "ami-private"))
+			expectInvalidArgument(err, "providerSpec.ami: Not found", "not shared with the account")
+		})
+
+		It("should reject a missing IAM instance profile found by the dry run", func() {
+			d := NewAWSDriverWithOptions(mockClientProvider, Options{PreflightValidation: true})
+
+			err := createMachine(d, strings.ReplaceAll(providerSpecTemplate, `"IAM"`, `"`+mockclient.InvalidIAMInstanceProfile+`"`))
+			expectInvalidArgument(err, "dry run of RunInstances was rejected", "providerSpec.iam.name: Not found")
+			Expect(mockClientProvider.FakeInstances).To(BeEmpty())
+		})
+
+		It("should cache successful validations per MachineClass hash", func() {
+			d := NewAWSDriverWithOptions(mockClientProvider, Options{PreflightValidation: true})
+			Expect(createMachine(d, providerSpecTemplate)).To(Succeed())
+
+			// The cached result is reused, although the subnet does not exist anymore
+			mockClientProvider.FakeSubnets = nil
+			Expect(createMachine(d, providerSpecTemplate)).To(Succeed())
+
+			// A changed MachineClass is validated again
+			err := createMachine(d, strings.ReplaceAll(providerSpecTemplate, `"IAM"`, `"other-iam"`))
+			expectInvalidArgument(err, "providerSpec.networkInterfaces[0].subnetID: Not found")
+		})
+	})
+
+	Describe("#preflightHash", func() {
+		It("should ignore the userData but not the credentials", func() {
+			machineClass := newMachineClass([]byte(providerSpecTemplate))
+			hash := preflightHash(machineClass, providerSecret)
+
+			otherUserData := providerSecret.DeepCopy()
+			otherUserData.Data["userData"] = []byte("other-user-data")
+			Expect(preflightHash(machineClass, otherUserData)).To(Equal(hash))
+
+			otherCredentials := providerSecret.DeepCopy()
+			otherCredentials.Data["providerAccessKeyId"] = []byte("other-id")
+			Expect(preflightHash(machineClass, otherCredentials)).ToNot(Equal(hash))
+		})
+	})
+})
@@ -0,0 +1,261 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package aws
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"slices"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/gardener/machine-controller-manager/pkg/util/provider/machinecodes/codes"
+	"github.com/gardener/machine-controller-manager/pkg/util/provider/machinecodes/status"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
+
+	api "github.com/gardener/machine-controller-manager-provider-aws/pkg/aws/apis"
+	awserror "github.com/gardener/machine-controller-manager-provider-aws/pkg/aws/errors"
+	"github.com/gardener/machine-controller-manager-provider-aws/pkg/aws/interfaces"
+	"github.com/gardener/machine-controller-manager-provider-aws/pkg/instrument"
+)
+
+const (
+	staticAddressSelectServiceLabel = "static_address_select"
+
+	// staticAddressMaxAttempts is the number of launches with different static addresses, if the chosen addresses were
+	// taken concurrently.
+	staticAddressMaxAttempts = 5
+
+	// staticPrivateIPv4TagKeyPrefix and staticIPv6TagKeyPrefix are the prefixes of the instance tags exposing the
+	// static addresses chosen for a network interface, they are followed by the index of the network interface.
+	staticPrivateIPv4TagKeyPrefix = "machine.sapcloud.io/static-private-ipv4-"
+	staticIPv6TagKeyPrefix        = "machine.sapcloud.io/static-ipv6-"
+)
+
+// staticAddresses are the addresses chosen for a network interface. Invalid addresses are not requested.
+type staticAddresses struct {
+	privateIPv4 netip.Addr
+	ipv6        netip.Addr
+}
+
+// subnetAddresses describes the address ranges and the addresses in use of a subnet.
+type subnetAddresses struct {
+	cidrs []netip.Prefix
+	used  map[netip.Addr]struct{}
+}
+
+// runInstances launches the instance. If network interfaces request static addresses, free addresses of their ranges
+// are chosen and the launch is retried with other addresses if the chosen ones were taken concurrently. The returned
+// errors are machine code errors.
+func runInstances(ctx context.Context, client interfaces.Ec2Client, networkInterfaces []api.AWSNetworkInterfaceSpec, input *ec2.RunInstancesInput) (*ec2.RunInstancesOutput, error) {
+	if !slices.ContainsFunc(networkInterfaces, func(netIf api.AWSNetworkInterfaceSpec) bool {
+		return netIf.PrivateIPv4CIDR != nil || netIf.IPv6CIDR != nil
+	}) {
+		output, err := client.RunInstances(ctx, input)
+		if err != nil {
+			return nil, status.Error(awserror.GetMCMErrorCodeForRunInstances(err, input), err.Error())
+		}
+		return output, nil
+	}
+
+	tried := map[netip.Addr]struct{}{}
+	for attempt := 1; ; attempt++ {
+		addresses, err := selectStaticAddresses(ctx, client, networkInterfaces, tried)
+		if err != nil {
+			return nil, err
+		}
+
+		output, err := client.RunInstances(ctx, withStaticAddresses(input, addresses))
+		if err == nil {
+			return output, nil
+		}
+		if !awserror.HasErrorCode(err, awserror.InvalidIPAddressInUse) || attempt == staticAddressMaxAttempts {
+			return nil, status.Error(awserror.GetMCMErrorCodeForRunInstances(err, input), err.Error())
+		}
+
+		klog.V(3).Infof("Static addresses %v were taken concurrently, retrying with other addresses: %v", addresses, err)
+		for _, address := range addresses {
+			tried[address.privateIPv4] = struct{}{}
+			tried[address.ipv6] = struct{}{}
+		}
+	}
+}
+
+// selectStaticAddresses chooses the first free address of every requested range, which is neither reserved by AWS nor
+// in use nor excluded. The returned addresses are indexed like the network interfaces.
+func selectStaticAddresses(ctx context.Context, client interfaces.Ec2Client, networkInterfaces []api.AWSNetworkInterfaceSpec, excluded map[netip.Addr]struct{}) ([]staticAddresses, error) {
+	subnets := map[string]*subnetAddresses{}
+	addresses := make([]staticAddresses, len(networkInterfaces))
+
+	for i, netIf := range networkInterfaces {
+		if netIf.PrivateIPv4CIDR == nil && netIf.IPv6CIDR == nil {
+			continue
+		}
+
+		subnet, ok := subnets[netIf.SubnetID]
+		if !ok {
+			var err error
+			subnet, err = describeSubnetAddresses(ctx, client, netIf.SubnetID)
+			if err != nil {
+				return nil, status.Error(codes.Internal, err.Error())
+			}
+			subnets[netIf.SubnetID] = subnet
+		}
+
+		for _, requested := range []struct {
+			cidr    *string
+			name    string
+			address *netip.Addr
+		}{
+			{netIf.PrivateIPv4CIDR, "privateIPv4CIDR", &addresses[i].privateIPv4},
+			{netIf.IPv6CIDR, "ipv6CIDR", &addresses[i].ipv6},
+		} {
+			if requested.cidr == nil {
+				continue
+			}
+			fldPath := fmt.Sprintf("providerSpec.networkInterfaces[%d].%s", i, requested.name)
+
+			addressRange, err := netip.ParsePrefix(*requested.cidr)
+			if err != nil {
+				return nil, status.Error(codes.InvalidArgument, fmt.Sprintf("%s is invalid: %v", fldPath, err))
+			}
+			subnetCIDR, ok := subnet.containing(addressRange)
+			if !ok {
+				return nil, status.Error(codes.InvalidArgument, fmt.Sprintf("%s %s is not within the CIDRs %v of subnet %s", fldPath, addressRange, subnet.cidrs, netIf.SubnetID))
+			}
+			address, ok := firstFreeAddress(addressRange.Masked(), subnetCIDR, subnet.used, excluded)
+			if !ok {
+				return nil, status.Error(codes.ResourceExhausted, fmt.Sprintf("no free address is left in %s %s", fldPath, addressRange))
+			}
+			*requested.address = address
+			// Network interfaces in the same subnet must not get the same address
+			subnet.used[address] = struct{}{}
+		}
+	}
+	return addresses, nil
+}
+
+// describeSubnetAddresses returns the CIDRs of the subnet and the addresses of its network interfaces.
+func describeSubnetAddresses(ctx context.Context, client interfaces.Ec2Client, subnetID string) (subnet *subnetAddresses, err error) {
+	defer instrument.AwsAPIMetricRecorderFn(staticAddressSelectServiceLabel, &err)()
+
+	subnetsOutput, err := client.DescribeSubnets(ctx, &ec2.DescribeSubnetsInput{SubnetIds: []string{subnetID}})
+	if err != nil {
+		return nil, err
+	}
+	if len(subnetsOutput.Subnets) == 0 {
+		return nil, fmt.Errorf("subnet %s not found", subnetID)
+	}
+
+	subnet = &subnetAddresses{used: map[netip.Addr]struct{}{}}
+	cidrs := []string{ptr.Deref(subnetsOutput.Subnets[0].CidrBlock, "")}
+	for _, association := range subnetsOutput.Subnets[0].Ipv6CidrBlockAssociationSet {
+		cidrs = append(cidrs, ptr.Deref(association.Ipv6CidrBlock, ""))
+	}
+	for _, cidr := range cidrs {
+		if prefix, err := netip.ParsePrefix(cidr); err == nil {
+			subnet.cidrs = append(subnet.cidrs, prefix.Masked())
+		}
+	}
+
+	paginator := ec2.NewDescribeNetworkInterfacesPaginator(client, &ec2.DescribeNetworkInterfacesInput{
+		Filters: []ec2types.Filter{{Name: aws.String("subnet-id"), Values: []string{subnetID}}},
+	}, func(opt *ec2.DescribeNetworkInterfacesPaginatorOptions) {
+		opt.StopOnDuplicateToken = true
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, networkInterface := range page.NetworkInterfaces {
+			for _, address := range networkInterface.PrivateIpAddresses {
+				subnet.markUsed(ptr.Deref(address.PrivateIpAddress, ""))
+			}
+			for _, address := range networkInterface.Ipv6Addresses {
+				subnet.markUsed(ptr.Deref(address.Ipv6Address, ""))
+			}
+		}
+	}
+	return subnet, nil
+}
+
+func (s *subnetAddresses) markUsed(address string) {
+	if addr, err := netip.ParseAddr(address); err == nil {
+		s.used[addr] = struct{}{}
+	}
+}
+
+// containing returns the CIDR of the subnet which contains the address range.
+func (s *subnetAddresses) containing(addressRange netip.Prefix) (netip.Prefix, bool) {
+	for _, cidr := range s.cidrs {
+		if cidr.Addr().Is4() == addressRange.Addr().Is4() && cidr.Bits() <= addressRange.Bits() && cidr.Contains(addressRange.Addr()) {
+			return cidr, true
+		}
+	}
+	return netip.Prefix{}, false
+}
+
+// firstFreeAddress returns the first address of the range which is neither used nor excluded. The first four and the
+// last address of every subnet CIDR are reserved by AWS.
+func firstFreeAddress(addressRange, subnetCIDR netip.Prefix, used, excluded map[netip.Addr]struct{}) (netip.Addr, bool) {
+	reserved := map[netip.Addr]struct{}{lastAddress(subnetCIDR): {}}
+	addr := subnetCIDR.Addr()
+	for range 4 {
+		reserved[addr] = struct{}{}
+		addr = addr.Next()
+	}
+
+	for addr := addressRange.Addr(); addr.IsValid() && addressRange.Contains(addr); addr = addr.Next() {
+		_, isReserved := reserved[addr]
+		_, isUsed := used[addr]
+		_, isExcluded := excluded[addr]
+		if !isReserved && !isUsed && !isExcluded {
+			return addr, true
+		}
+	}
+	return netip.Addr{}, false
+}
+
+// lastAddress returns the last address of the prefix.
+func lastAddress(prefix netip.Prefix) netip.Addr {
+	bytes := prefix.Masked().Addr().AsSlice()
+	for bit := prefix.Bits(); bit < len(bytes)*8; bit++ {
+		bytes[bit/8] |= 1 << (7 - bit%8)
+	}
+	addr, _ := netip.AddrFromSlice(bytes)
+	return addr
+}
+
+// withStaticAddresses returns a copy of the input requesting the addresses for the network interfaces, which are
+// exposed as instance tags.
+func withStaticAddresses(input *ec2.RunInstancesInput, addresses []staticAddresses) *ec2.RunInstancesInput {
+	out := *input
+	out.NetworkInterfaces = slices.Clone(input.NetworkInterfaces)
+	out.TagSpecifications = slices.Clone(input.TagSpecifications)
+
+	var tags []ec2types.Tag
+	for i, address := range addresses {
+		if address.privateIPv4.IsValid() {
+			out.NetworkInterfaces[i].PrivateIpAddress = aws.String(address.privateIPv4.String())
+			tags = append(tags, ec2types.Tag{Key: aws.String(staticPrivateIPv4TagKeyPrefix + strconv.Itoa(i)), Value: aws.String(address.privateIPv4.String())})
+		}
+		if address.ipv6.IsValid() {
+			out.NetworkInterfaces[i].Ipv6Addresses = []ec2types.InstanceIpv6Address{{Ipv6Address: aws.String(address.ipv6.String())}}
+			tags = append(tags, ec2types.Tag{Key: aws.String(staticIPv6TagKeyPrefix + strconv.Itoa(i)), Value: aws.String(address.ipv6.String())})
+		}
+	}
+
+	for i, tagSpec := range out.TagSpecifications {
+		if tagSpec.ResourceType == ec2types.ResourceTypeInstance {
+			out.TagSpecifications[i].Tags = append(slices.Clone(tagSpec.Tags), tags...)
+		}
+	}
+	return &out
+}
@@ -0,0 +1,119 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package aws
+
+import (
+	"context"
+	"net/netip"
+	"strings"
+
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/gardener/machine-controller-manager/pkg/util/provider/driver"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+
+	"github.com/gardener/machine-controller-manager-provider-aws/pkg/mockclient"
+)
+
+var _ = Describe("StaticIP", func() {
+	const providerSpecTemplate = `{"ami":"ami-123456789","blockDevices":[{"ebs":{"volumeSize":50,"volumeType":"gp2"}}],"iam":{"name":"test-iam"},"machineType":"m4.large","networkInterfaces":[{"securityGroupIDs":["sg-1"],"subnetID":"subnet-1",RANGES}],"region":"eu-west-1","tags":{"kubernetes.io/cluster/shoot--test":"1","kubernetes.io/role/test":"1"}}`
+	providerSecret := &corev1.Secret{
+		Data: map[string][]byte{
+			"providerAccessKeyId":     []byte("dummy-id"),
+			"providerSecretAccessKey": []byte("dummy-secret"),
+			"userData":                []byte("dummy-user-data"),
+		},
+	}
+
+	var mockClientProvider *mockclient.MockClientProvider
+
+	BeforeEach(func() {
+		mockClientProvider = &mockclient.MockClientProvider{
+			FakeSubnets: []ec2types.Subnet{{
+				SubnetId:  ptr.To("subnet-1"),
+				CidrBlock: ptr.To("10.0.1.0/24"),
+				Ipv6CidrBlockAssociationSet: []ec2types.SubnetIpv6CidrBlockAssociation{
+					{Ipv6CidrBlock: ptr.To("2001:db8:1::/64")},
+				},
+			}},
+			FakeNetworkInterfaces: []ec2types.NetworkInterface{{
+				NetworkInterfaceId: ptr.To("eni-used"),
+				Status:             ec2types.NetworkInterfaceStatusInUse,
+				PrivateIpAddresses: []ec2types.NetworkInterfacePrivateIpAddress{{PrivateIpAddress: ptr.To("10.0.1.4")}},
+			}},
+		}
+	})
+
+	createMachine := func(ranges string) (*ec2types.Instance, error) {
+		d := NewAWSDriver(mockClientProvider)
+		_, err := d.CreateMachine(context.Background(), &driver.CreateMachineRequest{
+			Machine:      newMachine(-1, nil),
+			MachineClass: newMachineClass([]byte(strings.Replace(providerSpecTemplate, "RANGES", ranges, 1))),
+			Secret:       providerSecret,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return &mockClientProvider.FakeInstances[len(mockClientProvider.FakeInstances)-1], nil
+	}
+	tagValue := func(instance *ec2types.Instance, key string) string {
+		for _, tag := range instance.Tags {
+			if ptr.Deref(tag.Key, "") == key {
+				return ptr.Deref(tag.Value, "")
+			}
+		}
+		return ""
+	}
+
+	It("should launch the instance with the first free address of the ranges and expose it as tag", func() {
+		instance, err := createMachine(`"privateIPv4CIDR":"10.0.1.0/29","ipv6CIDR":"2001:db8:1::/120"`)
+		Expect(err).ToNot(HaveOccurred())
+
+		// The first four addresses are reserved by AWS, 10.0.1.4 is in use
+		Expect(instance.NetworkInterfaces[0].PrivateIpAddress).To(Equal(ptr.To("10.0.1.5")))
+		Expect(instance.NetworkInterfaces[0].Ipv6Addresses).To(ConsistOf(ec2types.InstanceIpv6Address{Ipv6Address: ptr.To("2001:db8:1::4")}))
+		Expect(tagValue(instance, "machine.sapcloud.io/static-private-ipv4-0")).To(Equal("10.0.1.5"))
+		Expect(tagValue(instance, "machine.sapcloud.io/static-ipv6-0")).To(Equal("2001:db8:1::4"))
+	})
+
+	It("should retry with the next free address if the chosen address was taken concurrently", func() {
+		// The address of the running instance is not yet visible in DescribeNetworkInterfaces
+		mockClientProvider.FakeInstances = []ec2types.Instance{{
+			InstanceId: ptr.To("i-concurrent"),
+			State:      &ec2types.InstanceState{Name: ec2types.InstanceStateNameRunning},
+			NetworkInterfaces: []ec2types.InstanceNetworkInterface{{
+				PrivateIpAddresses: []ec2types.InstancePrivateIpAddress{{PrivateIpAddress: ptr.To("10.0.1.5"), Primary: ptr.To(true)}},
+			}},
+		}}
+
+		instance, err := createMachine(`"privateIPv4CIDR":"10.0.1.0/29"`)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(instance.NetworkInterfaces[0].PrivateIpAddress).To(Equal(ptr.To("10.0.1.6")))
+		Expect(tagValue(instance, "machine.sapcloud.io/static-private-ipv4-0")).To(Equal("10.0.1.6"))
+	})
+
+	It("should fail with ResourceExhausted if no address of the range is free", func() {
+		_, err := createMachine(`"privateIPv4CIDR":"10.0.1.4/32"`)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("machine codes error: code = [ResourceExhausted] message = [no free address is left in providerSpec.networkInterfaces[0].privateIPv4CIDR 10.0.1.4/32]"))
+		Expect(mockClientProvider.FakeInstances).To(BeEmpty())
+	})
+
+	It("should fail with InvalidArgument if the range is not within the subnet", func() {
+		_, err := createMachine(`"privateIPv4CIDR":"10.0.2.0/28"`)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("machine codes error: code = [InvalidArgument] message = [providerSpec.networkInterfaces[0].privateIPv4CIDR 10.0.2.0/28 is not within the CIDRs [10.0.1.0/24 2001:db8:1::/64] of subnet subnet-1]"))
+	})
+
+	Describe("#lastAddress", func() {
+		It("should return the last address of IPv4 and IPv6 prefixes", func() {
+			Expect(lastAddress(netip.MustParsePrefix("10.0.1.0/24"))).To(Equal(netip.MustParseAddr("10.0.1.255")))
+			Expect(lastAddress(netip.MustParsePrefix("10.0.1.17/28"))).To(Equal(netip.MustParseAddr("10.0.1.31")))
+			Expect(lastAddress(netip.MustParsePrefix("2001:db8:1::/64"))).To(Equal(netip.MustParseAddr("2001:db8:1::ffff:ffff:ffff:ffff")))
+		})
+	})
+})
@@ -52,6 +52,15 @@ func (d *Driver) createClient(ctx context.Context, secret *corev1.Secret, region
 	return client, nil
 }
 
+// Helper function to create STS Client
+func (d *Driver) createSTSClient(ctx context.Context, secret *corev1.Secret, region string) (interfaces.StsClient, error) {
+	config, err := d.CPI.NewConfig(ctx, secret, region)
+	if err != nil {
+		return nil, err
+	}
+	return d.CPI.NewSTSClient(config), nil
+}
+
 // Function returns true only if error code equals codes.NotFound
 func isNotFoundError(err error) bool {
 	errorStatus, ok := status.FromError(err)
@@ -0,0 +1,242 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package conformance contains a reusable Ginkgo suite which checks that the AWS Driver obeys the contract
+// machine-controller-manager expects from a provider driver.
+package conformance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	v1alpha1 "github.com/gardener/machine-controller-manager/pkg/apis/machine/v1alpha1"
+	"github.com/gardener/machine-controller-manager/pkg/util/provider/driver"
+	"github.com/gardener/machine-controller-manager/pkg/util/provider/machinecodes/codes"
+	"github.com/gardener/machine-controller-manager/pkg/util/provider/machinecodes/status"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/rand"
+
+	"github.com/gardener/machine-controller-manager-provider-aws/pkg/aws"
+	"github.com/gardener/machine-controller-manager-provider-aws/pkg/cpi"
+)
+
+// Config configures the conformance suite.
+type Config struct {
+	// NewClientProvider returns the client provider the Driver under test is created with. It is called once per spec.
+	NewClientProvider func() cpi.ClientProviderInterface
+	// MachineClass is used for all machines created by the suite.
+	MachineClass *v1alpha1.MachineClass
+	// Secret contains the cloud credentials and userData used for all machines created by the suite.
+	Secret *corev1.Secret
+	// MachineNamePrefix is prepended to the names of machines created by the suite. A random suffix is added per
+	// machine, so that suites running against the same account do not interfere.
+	MachineNamePrefix string
+	// Timeout bounds each driver call. Defaults to 10 minutes.
+	Timeout time.Duration
+}
+
+// DescribeDriverConformance registers the conformance specs for the given configuration in the current Ginkgo
+// container.
+func DescribeDriverConformance(cfg Config) bool {
+	if cfg.Timeout == 0 {
+		cfg.Timeout = 10 * time.Minute
+	}
+
+	return Describe("Driver conformance", func() {
+		var (
+			d   driver.Driver
+			ctx context.Context
+		)
+
+		BeforeEach(func() {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(context.Background(), cfg.Timeout)
+			DeferCleanup(cancel)
+			d = aws.NewAWSDriver(cfg.NewClientProvider())
+		})
+
+		newMachine := func() *v1alpha1.Machine {
+			return &v1alpha1.Machine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      fmt.Sprintf("%s%s", cfg.MachineNamePrefix, rand.String(8)),
+					Namespace: cfg.MachineClass.Namespace,
+				},
+			}
+		}
+
+		// createMachineOfClass creates a VM for the machine and registers its deletion, the providerID is set on the
+		// machine like machine-controller-manager does after a successful creation.
+		createMachineOfClass := func(machine *v1alpha1.Machine, machineClass *v1alpha1.MachineClass) *driver.CreateMachineResponse {
+			resp, err := d.CreateMachine(ctx, &driver.CreateMachineRequest{Machine: machine, MachineClass: machineClass, Secret: cfg.Secret})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.ProviderID).ToNot(BeEmpty())
+			Expect(resp.NodeName).ToNot(BeEmpty())
+			machine.Spec.ProviderID = resp.ProviderID
+
+			DeferCleanup(func(ctx SpecContext) {
+				_, err := d.DeleteMachine(ctx, &driver.DeleteMachineRequest{Machine: machine, MachineClass: machineClass, Secret: cfg.Secret})
+				Expect(err).ToNot(HaveOccurred())
+			})
+			return resp
+		}
+
+		createMachine := func(machine *v1alpha1.Machine) *driver.CreateMachineResponse {
+			return createMachineOfClass(machine, cfg.MachineClass)
+		}
+
+		getMachineStatusOfClass := func(machine *v1alpha1.Machine, machineClass *v1alpha1.MachineClass) (*driver.GetMachineStatusResponse, error) {
+			return d.GetMachineStatus(ctx, &driver.GetMachineStatusRequest{Machine: machine, MachineClass: machineClass, Secret: cfg.Secret})
+		}
+
+		getMachineStatus := func(machine *v1alpha1.Machine) (*driver.GetMachineStatusResponse, error) {
+			return getMachineStatusOfClass(machine, cfg.MachineClass)
+		}
+
+		deleteMachine := func(machine *v1alpha1.Machine) error {
+			_, err := d.DeleteMachine(ctx, &driver.DeleteMachineRequest{Machine: machine, MachineClass: cfg.MachineClass, Secret: cfg.Secret})
+			return err
+		}
+
+		Describe("#CreateMachine", func() {
+			It("should create a VM which is reported by GetMachineStatus", func() {
+				machine := newMachine()
+				resp := createMachine(machine)
+
+				statusResp, err := getMachineStatus(machine)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(statusResp.ProviderID).To(Equal(resp.ProviderID))
+				Expect(statusResp.NodeName).To(Equal(resp.NodeName))
+			})
+		})
+
+		Describe("#InitializeMachine", func() {
+			It("should return Uninitialized if no VM backs the machine", func() {
+				_, err := d.InitializeMachine(ctx, &driver.InitializeMachineRequest{Machine: newMachine(), MachineClass: cfg.MachineClass, Secret: cfg.Secret})
+				expectCode(err, codes.Uninitialized)
+			})
+
+			It("should leave the machine in a state in which GetMachineStatus does not return Uninitialized", func() {
+				machine := newMachine()
+				resp := createMachine(machine)
+
+				initResp, err := d.InitializeMachine(ctx, &driver.InitializeMachineRequest{Machine: machine, MachineClass: cfg.MachineClass, Secret: cfg.Secret})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(initResp.ProviderID).To(Equal(resp.ProviderID))
+
+				_, err = getMachineStatus(machine)
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("should be required by GetMachineStatus with Uninitialized until the machine has been initialized", func() {
+				// Source/destination checks can only be disabled on running instances, i.e. by InitializeMachine
+				machineClass := withProviderSpecField(cfg.MachineClass, "srcAndDstChecksEnabled", false)
+				machine := newMachine()
+				createMachineOfClass(machine, machineClass)
+
+				_, err := getMachineStatusOfClass(machine, machineClass)
+				expectCode(err, codes.Uninitialized)
+
+				_, err = d.InitializeMachine(ctx, &driver.InitializeMachineRequest{Machine: machine, MachineClass: machineClass, Secret: cfg.Secret})
+				Expect(err).ToNot(HaveOccurred())
+
+				_, err = getMachineStatusOfClass(machine, machineClass)
+				Expect(err).ToNot(HaveOccurred())
+			})
+		})
+
+		Describe("#GetMachineStatus", func() {
+			It("should return NotFound if the machine was never created", func() {
+				_, err := getMachineStatus(newMachine())
+				expectCode(err, codes.NotFound)
+			})
+
+			It("should return NotFound after the machine has been deleted", func() {
+				machine := newMachine()
+				createMachine(machine)
+				Expect(deleteMachine(machine)).To(Succeed())
+
+				_, err := getMachineStatus(machine)
+				expectCode(err, codes.NotFound)
+			})
+
+			It("should return OutOfRange if multiple VMs back the machine", func() {
+				machine := newMachine()
+				createMachine(machine)
+				// Creating the machine again simulates an orphan VM of a former creation attempt
+				orphan := machine.DeepCopy()
+				orphan.Spec.ProviderID = ""
+				createMachine(orphan)
+
+				_, err := getMachineStatus(machine)
+				expectCode(err, codes.OutOfRange)
+			})
+		})
+
+		Describe("#DeleteMachine", func() {
+			It("should be idempotent", func() {
+				machine := newMachine()
+				createMachine(machine)
+
+				Expect(deleteMachine(machine)).To(Succeed())
+				Expect(deleteMachine(machine)).To(Succeed())
+			})
+
+			It("should succeed if the machine was never created", func() {
+				Expect(deleteMachine(newMachine())).To(Succeed())
+			})
+		})
+
+		Describe("#ListMachines", func() {
+			It("should list every created VM with its machine name", func() {
+				machines := []*v1alpha1.Machine{newMachine(), newMachine(), newMachine()}
+				for _, machine := range machines {
+					createMachine(machine)
+				}
+
+				resp, err := d.ListMachines(ctx, &driver.ListMachinesRequest{MachineClass: cfg.MachineClass, Secret: cfg.Secret})
+				Expect(err).ToNot(HaveOccurred())
+				for _, machine := range machines {
+					Expect(resp.MachineList).To(HaveKeyWithValue(machine.Spec.ProviderID, machine.Name))
+				}
+			})
+
+			It("should not list deleted VMs", func() {
+				machine := newMachine()
+				createMachine(machine)
+				Expect(deleteMachine(machine)).To(Succeed())
+
+				resp, err := d.ListMachines(ctx, &driver.ListMachinesRequest{MachineClass: cfg.MachineClass, Secret: cfg.Secret})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(resp.MachineList).ToNot(HaveKey(machine.Spec.ProviderID))
+			})
+		})
+	})
+}
+
+// withProviderSpecField returns a copy of the MachineClass whose providerSpec has the field set to the value.
+func withProviderSpecField(machineClass *v1alpha1.MachineClass, name string, value any) *v1alpha1.MachineClass {
+	GinkgoHelper()
+	providerSpec := map[string]any{}
+	Expect(json.Unmarshal(machineClass.ProviderSpec.Raw, &providerSpec)).To(Succeed())
+	providerSpec[name] = value
+	raw, err := json.Marshal(providerSpec)
+	Expect(err).ToNot(HaveOccurred())
+
+	machineClass = machineClass.DeepCopy()
+	machineClass.ProviderSpec.Raw = raw
+	return machineClass
+}
+
+func expectCode(err error, code codes.Code) {
+	GinkgoHelper()
+	Expect(err).To(HaveOccurred())
+	statusErr, ok := status.FromError(err)
+	Expect(ok).To(BeTrue(), "expected a machine codes status error, got %v", err)
+	Expect(statusErr.Code()).To(Equal(code), "unexpected code for error %v", err)
+}
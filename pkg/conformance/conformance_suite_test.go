@@ -0,0 +1,83 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package conformance
+
+import (
+	"os"
+	"testing"
+
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	v1alpha1 "github.com/gardener/machine-controller-manager/pkg/apis/machine/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+
+	"github.com/gardener/machine-controller-manager-provider-aws/pkg/aws"
+	"github.com/gardener/machine-controller-manager-provider-aws/pkg/cpi"
+	"github.com/gardener/machine-controller-manager-provider-aws/pkg/mockclient"
+)
+
+const (
+	// envMachineClass points to a MachineClass manifest used to run the suite against a real AWS account.
+	envMachineClass = "CONFORMANCE_MACHINECLASS"
+	// envSecret points to the Secret manifest belonging to the MachineClass in envMachineClass.
+	envSecret = "CONFORMANCE_SECRET"
+)
+
+func TestConformance(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Conformance Suite")
+}
+
+var _ = Describe("Mock client provider", func() {
+	DescribeDriverConformance(Config{
+		NewClientProvider: func() cpi.ClientProviderInterface {
+			return &mockclient.MockClientProvider{FakeInstances: make([]ec2types.Instance, 0)}
+		},
+		MachineClass: &v1alpha1.MachineClass{
+			ProviderSpec: runtime.RawExtension{
+				Raw: []byte(`{"ami":"ami-123456789","blockDevices":[{"ebs":{"volumeSize":50,"volumeType":"gp2"}}],"iam":{"name":"test-iam"},"keyName":"test-ssh-publickey","machineType":"m4.large","networkInterfaces":[{"securityGroupIDs":["sg-00002132323"],"subnetID":"subnet-123456"}],"region":"eu-west-1","tags":{"kubernetes.io/cluster/shoot--test":"1","kubernetes.io/role/test":"1"}}`),
+			},
+			Provider: aws.ProviderAWS,
+		},
+		Secret: &corev1.Secret{
+			Data: map[string][]byte{
+				"providerAccessKeyId":     []byte("dummy-id"),
+				"providerSecretAccessKey": []byte("dummy-secret"),
+				"userData":                []byte("dummy-user-data"),
+			},
+		},
+		MachineNamePrefix: "conformance-",
+	})
+})
+
+var _ = Describe("AWS client provider", Label("aws"), func() {
+	machineClass, secret := &v1alpha1.MachineClass{}, &corev1.Secret{}
+
+	BeforeEach(func() {
+		if os.Getenv(envMachineClass) == "" || os.Getenv(envSecret) == "" {
+			Skip("set " + envMachineClass + " and " + envSecret + " to run the conformance suite against an AWS account")
+		}
+		Expect(readManifest(os.Getenv(envMachineClass), machineClass)).To(Succeed())
+		Expect(readManifest(os.Getenv(envSecret), secret)).To(Succeed())
+	})
+
+	DescribeDriverConformance(Config{
+		NewClientProvider: func() cpi.ClientProviderInterface { return &cpi.ClientProvider{} },
+		MachineClass:      machineClass,
+		Secret:            secret,
+		MachineNamePrefix: "mcm-conformance-",
+	})
+})
+
+func readManifest(path string, obj any) error {
+	data, err := os.ReadFile(path) // #nosec: G304 -- path is provided by the test environment
+	if err != nil {
+		return err
+	}
+	return yaml.Unmarshal(data, obj)
+}
@@ -72,6 +72,11 @@ func (cp *ClientProvider) NewEC2Client(config *aws.Config) interfaces.Ec2Client
 	return client
 }
 
+// NewSTSClient Returns an StsClient object
+func (cp *ClientProvider) NewSTSClient(config *aws.Config) interfaces.StsClient {
+	return sts.NewFromConfig(*config)
+}
+
 // extractCredentialsFromData extracts and trims a value from the given data map. The first key that exists is being
 // returned, otherwise, the next key is tried, etc. If no key exists then an empty string is returned.
 func extractCredentialsFromData(data map[string][]byte, keys ...string) string {
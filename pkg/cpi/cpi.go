@@ -12,8 +12,9 @@ import (
 	corev1 "k8s.io/api/core/v1"
 )
 
-// ClientProviderInterface provides an interface to set-up and instantiate (EC2) Clients
+// ClientProviderInterface provides an interface to set-up and instantiate (EC2 and STS) Clients
 type ClientProviderInterface interface {
 	NewConfig(context.Context, *corev1.Secret, string) (*aws.Config, error)
 	NewEC2Client(*aws.Config) interfaces.Ec2Client
+	NewSTSClient(*aws.Config) interfaces.StsClient
 }
@@ -116,3 +116,14 @@ func deferredMetricsRecorderInvoker(shouldReturnErr bool, isStatusErr bool, fn r
 	}
 	return
 }
+
+func TestRecordMachineClassUnknownFields(t *testing.T) {
+	g := NewWithT(t)
+	defer MachineClassUnknownFields.Reset()
+
+	RecordMachineClassUnknownFields("test-class", "test-namespace", 2)
+	g.Expect(testutil.ToFloat64(MachineClassUnknownFields.WithLabelValues(prometheusProviderLabelValue, "test-class", "test-namespace"))).To(Equal(float64(2)))
+
+	RecordMachineClassUnknownFields("test-class", "test-namespace", 0)
+	g.Expect(testutil.CollectAndCount(MachineClassUnknownFields)).To(Equal(0))
+}
@@ -0,0 +1,89 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package instrument
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	namespace         = "mcm"
+	cloudAPISubsystem = "cloud_api"
+)
+
+var (
+	// MachineClassUnknownFields is the number of providerSpec fields per MachineClass which are unknown to the driver.
+	MachineClassUnknownFields = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: cloudAPISubsystem,
+		Name:      "machine_class_unknown_fields",
+		Help:      "Number of providerSpec fields of a MachineClass which are unknown to the driver.",
+	}, []string{"provider", "name", "namespace"})
+
+	// DeprecatedFeatureMachineClasses is the number of MachineClasses which use a deprecated feature.
+	DeprecatedFeatureMachineClasses = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: cloudAPISubsystem,
+		Name:      "deprecated_feature_machine_classes",
+		Help:      "Number of MachineClasses which use a deprecated feature.",
+	}, []string{"provider", "feature"})
+
+	// CapacityReservationAvailableInstances is the number of instances which can still be launched into a capacity
+	// reservation or Capacity Block.
+	CapacityReservationAvailableInstances = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: cloudAPISubsystem,
+		Name:      "capacity_reservation_available_instances",
+		Help:      "Number of instances which can still be launched into a capacity reservation or Capacity Block.",
+	}, []string{"provider", "capacity_reservation_id", "instance_type"})
+
+	// CapacityReservationEndTimestamp is the end of a capacity reservation or Capacity Block.
+	CapacityReservationEndTimestamp = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: cloudAPISubsystem,
+		Name:      "capacity_reservation_end_timestamp_seconds",
+		Help:      "End of a capacity reservation or Capacity Block as Unix timestamp, unset for reservations without end.",
+	}, []string{"provider", "capacity_reservation_id", "instance_type"})
+)
+
+func init() {
+	prometheus.MustRegister(MachineClassUnknownFields)
+	prometheus.MustRegister(DeprecatedFeatureMachineClasses)
+	prometheus.MustRegister(CapacityReservationAvailableInstances)
+	prometheus.MustRegister(CapacityReservationEndTimestamp)
+}
+
+// RecordMachineClassUnknownFields records the number of unknown providerSpec fields of a MachineClass. The series of
+// a MachineClass is deleted once it has no unknown fields anymore.
+func RecordMachineClassUnknownFields(name, namespace string, count int) {
+	if count == 0 {
+		MachineClassUnknownFields.DeleteLabelValues(prometheusProviderLabelValue, name, namespace)
+		return
+	}
+	MachineClassUnknownFields.WithLabelValues(prometheusProviderLabelValue, name, namespace).Set(float64(count))
+}
+
+// RecordDeprecatedFeatureMachineClasses records the number of MachineClasses which use a deprecated feature.
+func RecordDeprecatedFeatureMachineClasses(feature string, count int) {
+	DeprecatedFeatureMachineClasses.WithLabelValues(prometheusProviderLabelValue, feature).Set(float64(count))
+}
+
+// RecordCapacityReservation records the available instances and the end of a capacity reservation or Capacity Block.
+func RecordCapacityReservation(reservationID, instanceType string, availableInstances int32, endDate *time.Time) {
+	CapacityReservationAvailableInstances.WithLabelValues(prometheusProviderLabelValue, reservationID, instanceType).Set(float64(availableInstances))
+	if endDate != nil {
+		CapacityReservationEndTimestamp.WithLabelValues(prometheusProviderLabelValue, reservationID, instanceType).Set(float64(endDate.Unix()))
+	}
+}
+
+// DeleteCapacityReservation deletes the recorded metrics of a capacity reservation or Capacity Block which does not
+// exist anymore or has ended.
+func DeleteCapacityReservation(reservationID string) {
+	labels := prometheus.Labels{"provider": prometheusProviderLabelValue, "capacity_reservation_id": reservationID}
+	CapacityReservationAvailableInstances.DeletePartialMatch(labels)
+	CapacityReservationEndTimestamp.DeletePartialMatch(labels)
+}
@@ -0,0 +1,64 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package lint validates MachineClass and Secret manifests offline, i.e. without a cluster or AWS credentials, using
+// the same decoding and validation as the driver.
+package lint
+
+import (
+	"fmt"
+
+	v1alpha1 "github.com/gardener/machine-controller-manager/pkg/apis/machine/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/yaml"
+
+	"github.com/gardener/machine-controller-manager-provider-aws/pkg/aws"
+	"github.com/gardener/machine-controller-manager-provider-aws/pkg/aws/apis/validation"
+)
+
+// Lint decodes the given MachineClass and Secret manifests (YAML or JSON) and returns all field errors of the
+// providerSpec and the Secret, including fields unknown to the driver and field names which differ in case. The
+// secret manifest may be nil, in which case it is reported as missing. The returned error is only set if a manifest
+// cannot be decoded at all.
+func Lint(machineClassManifest, secretManifest []byte) (field.ErrorList, error) {
+	machineClass := &v1alpha1.MachineClass{}
+	if err := yaml.Unmarshal(machineClassManifest, machineClass); err != nil {
+		return nil, fmt.Errorf("could not decode MachineClass: %w", err)
+	}
+
+	var secret *corev1.Secret
+	if secretManifest != nil {
+		secret = &corev1.Secret{}
+		if err := yaml.Unmarshal(secretManifest, secret); err != nil {
+			return nil, fmt.Errorf("could not decode Secret: %w", err)
+		}
+		// stringData is merged into data by the API server, which does not take part in offline linting
+		if len(secret.StringData) > 0 && secret.Data == nil {
+			secret.Data = make(map[string][]byte, len(secret.StringData))
+		}
+		for key, value := range secret.StringData {
+			secret.Data[key] = []byte(value)
+		}
+	}
+
+	allErrs := field.ErrorList{}
+	if machineClass.Provider != aws.ProviderAWS {
+		allErrs = append(allErrs, field.NotSupported(field.NewPath("provider"), machineClass.Provider, []string{aws.ProviderAWS}))
+	}
+	if len(machineClass.ProviderSpec.Raw) == 0 {
+		return append(allErrs, field.Required(field.NewPath("providerSpec"), "providerSpec is required")), nil
+	}
+
+	fldPath := field.NewPath("providerSpec")
+	providerSpec, unknownFieldErrs, caseMismatchErrs, err := validation.DecodeAWSProviderSpec(machineClass.ProviderSpec.Raw, fldPath)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode providerSpec: %w", err)
+	}
+	allErrs = append(allErrs, unknownFieldErrs...)
+	allErrs = append(allErrs, caseMismatchErrs...)
+	allErrs = append(allErrs, validation.ValidateAWSProviderSpec(providerSpec, secret, fldPath)...)
+	allErrs = append(allErrs, validation.ValidateDeprecatedFields(providerSpec, fldPath)...)
+	return allErrs, nil
+}
@@ -0,0 +1,113 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package lint
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+const validMachineClass = `
+apiVersion: machine.sapcloud.io/v1alpha1
+kind: MachineClass
+metadata:
+  name: test-mc
+provider: AWS
+providerSpec:
+  ami: ami-123456
+  region: eu-west-1
+  machineType: m5.large
+  iam:
+    name: test-iam
+  keyName: test-ssh-publickey
+  blockDevices:
+  - ebs:
+      volumeSize: 50
+      volumeType: gp3
+  networkInterfaces:
+  - subnetID: subnet-123456
+    securityGroupIDs:
+    - sg-123456
+  tags:
+    kubernetes.io/cluster/shoot--test: "1"
+    kubernetes.io/role/test: "1"
+`
+
+const validSecret = `
+apiVersion: v1
+kind: Secret
+metadata:
+  name: test-secret
+stringData:
+  providerAccessKeyId: dummy-id
+  providerSecretAccessKey: dummy-secret
+  userData: dummy-user-data
+`
+
+func errorFields(allErrs field.ErrorList) []string {
+	var fields []string
+	for _, err := range allErrs {
+		fields = append(fields, err.Field)
+	}
+	return fields
+}
+
+func TestLintValid(t *testing.T) {
+	g := NewWithT(t)
+
+	allErrs, err := Lint([]byte(validMachineClass), []byte(validSecret))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(allErrs).To(BeEmpty())
+}
+
+func TestLintReportsAllErrors(t *testing.T) {
+	g := NewWithT(t)
+	machineClass := `
+provider: AWS
+providerSpec:
+  region: eu-west-1
+  machineType: m5.large
+  iam:
+    name: test-iam
+  blockDevices:
+  - ebs:
+      volumeSize: 50
+      volumetype: gp3
+  networkInterfaces:
+  - subnetID: subnet-123456
+    securityGroupIDs:
+    - sg-123456
+  tags:
+    kubernetes.io/cluster/shoot--test: "1"
+    kubernetes.io/role/test: "1"
+  spotPrise: "0.1"
+`
+
+	allErrs, err := Lint([]byte(machineClass), nil)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(errorFields(allErrs)).To(ConsistOf(
+		"providerSpec.blockDevices[0].ebs.volumetype",
+		"providerSpec.spotPrise",
+		"providerSpec.ami",
+		"secretRef[]",
+	))
+}
+
+func TestLintRejectsOtherProviders(t *testing.T) {
+	g := NewWithT(t)
+
+	allErrs, err := Lint([]byte("provider: GCP\n"), []byte(validSecret))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(errorFields(allErrs)).To(ConsistOf("provider", "providerSpec"))
+}
+
+func TestLintInvalidManifest(t *testing.T) {
+	g := NewWithT(t)
+
+	_, err := Lint([]byte(validMachineClass), []byte("data: ["))
+	g.Expect(err).To(MatchError(ContainSubstring("could not decode Secret")))
+}
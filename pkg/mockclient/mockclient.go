@@ -7,12 +7,15 @@ package mockclient
 import (
 	"context"
 	"fmt"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
 	"github.com/aws/smithy-go"
 	"github.com/gardener/machine-controller-manager-provider-aws/pkg/aws/errors"
 	"github.com/gardener/machine-controller-manager-provider-aws/pkg/aws/interfaces"
@@ -43,6 +46,16 @@ const (
 	InconsistencyInAPIs string = "apis-are-inconsistent"
 	// InsufficientCapacity string makes RunInstances return an InsufficientCapacity error code
 	InsufficientCapacity = "insufficient-capacity"
+	// InvalidIAMInstanceProfile string makes RunInstances with DryRun reject the IAM instance profile
+	InvalidIAMInstanceProfile = "invalid-iam-instance-profile"
+	// UnsupportedAutoRecoveryInstanceType is an instance type for which RunInstances rejects enabled auto-recovery
+	UnsupportedAutoRecoveryInstanceType = "i3.large"
+	// FullHostResourceGroup is a host resource group for which RunInstances returns an InsufficientHostCapacity error
+	FullHostResourceGroup = "arn:aws:resource-groups:eu-west-1:123456789012:group/full"
+	// FakeHostCapacity is the number of instances of its instance type which fit on a host allocated by AllocateHosts
+	FakeHostCapacity int32 = 2
+	// FakeAccountID is the account of the caller returned by GetCallerIdentity
+	FakeAccountID = "123456789012"
 )
 
 var (
@@ -58,11 +71,36 @@ var (
 	AWSInternalErrorForDescribeInstances = &smithy.GenericAPIError{Code: "cloud provider returned error"}
 	// AWSInstanceNotFoundError returns denotes an error with InvalidInstanceID.NotFound error code
 	AWSInstanceNotFoundError = &smithy.GenericAPIError{Code: string(errors.InstanceIDNotFound)}
+	// AWSDryRunOperationError denotes the error returned by a RunInstances call with DryRun which would have succeeded
+	AWSDryRunOperationError = &smithy.GenericAPIError{Code: errors.DryRunOperation, Message: "Request would have succeeded, but DryRun flag is set."}
 )
 
 // MockClientProvider is the mock implementation of ClientProvider interface that makes dummy calls
 type MockClientProvider struct {
 	FakeInstances         []ec2types.Instance
+	FakeVolumes           []ec2types.Volume
+	FakeNetworkInterfaces []ec2types.NetworkInterface
+	// FakeImages are returned by DescribeImages if set, otherwise DescribeImages returns a single dummy image
+	FakeImages         []ec2types.Image
+	FakeSubnets        []ec2types.Subnet
+	FakeSecurityGroups []ec2types.SecurityGroup
+	FakeInstanceTypes  []ec2types.InstanceTypeInfo
+	FakeAddresses      []ec2types.Address
+	// FakePlacementGroups are returned by DescribePlacementGroups
+	FakePlacementGroups []ec2types.PlacementGroup
+	// FakeCapacityReservations are returned by DescribeCapacityReservations
+	FakeCapacityReservations []ec2types.CapacityReservation
+	// FakeHosts are the Dedicated Hosts, instances launched onto a host are added to its instances and capacity
+	FakeHosts []ec2types.Host
+	// FakeCPUCredits are the CPU credit options of the fake instances by instance ID, instances without an entry
+	// have "standard" credits
+	FakeCPUCredits map[string]string
+	// ConcurrentAddressClaims are the allocation IDs of Elastic IPs which are associated with another instance right
+	// after DescribeAddresses returned them unassociated, as if another machine claimed them concurrently
+	ConcurrentAddressClaims []string
+	// ShuttingDownInstances keeps terminated instances shutting down on their Dedicated Hosts until ShutDownInstances
+	// is called
+	ShuttingDownInstances bool
 	PageSize              int32
 	TriggerDuplicateToken int
 }
@@ -78,18 +116,68 @@ func (ms *MockClientProvider) NewConfig(_ context.Context, _ *corev1.Secret, reg
 // NewEC2Client Returns a new mock for the EC2 Client
 func (ms *MockClientProvider) NewEC2Client(_ *aws.Config) interfaces.Ec2Client {
 	return &MockEC2Client{
-		FakeInstances:         &ms.FakeInstances,
-		PageSize:              ms.PageSize,
-		TriggerDuplicateToken: ms.TriggerDuplicateToken,
+		FakeInstances:            &ms.FakeInstances,
+		FakeVolumes:              &ms.FakeVolumes,
+		FakeNetworkInterfaces:    &ms.FakeNetworkInterfaces,
+		FakeImages:               ms.FakeImages,
+		FakeSubnets:              ms.FakeSubnets,
+		FakeSecurityGroups:       ms.FakeSecurityGroups,
+		FakeInstanceTypes:        ms.FakeInstanceTypes,
+		FakePlacementGroups:      ms.FakePlacementGroups,
+		FakeCapacityReservations: ms.FakeCapacityReservations,
+		FakeAddresses:            &ms.FakeAddresses,
+		FakeHosts:                &ms.FakeHosts,
+		FakeCPUCredits:           &ms.FakeCPUCredits,
+		ConcurrentAddressClaims:  &ms.ConcurrentAddressClaims,
+		ShuttingDownInstances:    ms.ShuttingDownInstances,
+		PageSize:                 ms.PageSize,
+		TriggerDuplicateToken:    ms.TriggerDuplicateToken,
 	}
 }
 
+// ShutDownInstances terminates the instances which are shutting down and removes them from their Dedicated Hosts.
+func (ms *MockClientProvider) ShutDownInstances() {
+	client := ms.NewEC2Client(nil).(*MockEC2Client)
+	for i, instance := range ms.FakeInstances {
+		if instance.State != nil && instance.State.Name == ec2types.InstanceStateNameShuttingDown {
+			client.removeHostInstance(aws.ToString(instance.InstanceId))
+			ms.FakeInstances[i].State = &ec2types.InstanceState{Code: aws.Int32(48), Name: ec2types.InstanceStateNameTerminated}
+		}
+	}
+}
+
+// NewSTSClient Returns a new mock for the STS Client
+func (ms *MockClientProvider) NewSTSClient(_ *aws.Config) interfaces.StsClient {
+	return &MockSTSClient{}
+}
+
+// MockSTSClient is the mock implementation of an StsClient
+type MockSTSClient struct{}
+
+// GetCallerIdentity implements a mock get caller identity method, which returns the FakeAccountID
+func (ms *MockSTSClient) GetCallerIdentity(_ context.Context, _ *sts.GetCallerIdentityInput, _ ...func(*sts.Options)) (*sts.GetCallerIdentityOutput, error) {
+	return &sts.GetCallerIdentityOutput{Account: aws.String(FakeAccountID)}, nil
+}
+
 // MockEC2Client is the mock implementation of an EC2Client
 type MockEC2Client struct {
 	interfaces.Ec2Client
-	FakeInstances         *[]ec2types.Instance
-	PageSize              int32
-	TriggerDuplicateToken int
+	FakeInstances            *[]ec2types.Instance
+	FakeVolumes              *[]ec2types.Volume
+	FakeNetworkInterfaces    *[]ec2types.NetworkInterface
+	FakeImages               []ec2types.Image
+	FakeSubnets              []ec2types.Subnet
+	FakeSecurityGroups       []ec2types.SecurityGroup
+	FakeInstanceTypes        []ec2types.InstanceTypeInfo
+	FakePlacementGroups      []ec2types.PlacementGroup
+	FakeCapacityReservations []ec2types.CapacityReservation
+	FakeAddresses            *[]ec2types.Address
+	FakeHosts                *[]ec2types.Host
+	FakeCPUCredits           *map[string]string
+	ConcurrentAddressClaims  *[]string
+	ShuttingDownInstances    bool
+	PageSize                 int32
+	TriggerDuplicateToken    int
 }
 
 // DescribeImages implements a mock describe image method
@@ -99,6 +187,19 @@ func (ms *MockEC2Client) DescribeImages(_ context.Context, input *ec2.DescribeIm
 		return nil, AWSImageNotFoundError
 	}
 
+	if len(ms.FakeImages) > 0 {
+		var images []ec2types.Image
+		for _, image := range ms.FakeImages {
+			if slices.Contains(input.ImageIds, aws.ToString(image.ImageId)) {
+				images = append(images, image)
+			}
+		}
+		if len(images) == 0 {
+			return nil, &smithy.GenericAPIError{Code: errors.InvalidAMIIDNotFound}
+		}
+		return &ec2.DescribeImagesOutput{Images: images}, nil
+	}
+
 	rootDeviceName := "test-root-disk"
 
 	return &ec2.DescribeImagesOutput{
@@ -114,6 +215,16 @@ func (ms *MockEC2Client) DescribeImages(_ context.Context, input *ec2.DescribeIm
 // The name of the newly created instances depends on the number of instances in cache starts from 0
 func (ms *MockEC2Client) RunInstances(_ context.Context, input *ec2.RunInstancesInput, _ ...func(*ec2.Options)) (*ec2.RunInstancesOutput, error) {
 
+	if aws.ToBool(input.DryRun) {
+		if input.IamInstanceProfile != nil && aws.ToString(input.IamInstanceProfile.Name) == InvalidIAMInstanceProfile {
+			return nil, &smithy.GenericAPIError{
+				Code:    errors.InvalidParameterValue,
+				Message: fmt.Sprintf("Value (%s) for parameter iamInstanceProfile.name is invalid. Invalid IAM Instance Profile name", InvalidIAMInstanceProfile),
+			}
+		}
+		return nil, AWSDryRunOperationError
+	}
+
 	if *input.ImageId == FailQueryAtRunInstances {
 		if *input.KeyName == InsufficientCapacity {
 			return nil, AWSInsufficientCapacityError
@@ -129,8 +240,8 @@ func (ms *MockEC2Client) RunInstances(_ context.Context, input *ec2.RunInstances
 		instanceID = fmt.Sprintf(
 			"i-0123456789-%d/placement={affinity:%s,availabilityZone:%s,tenancy:%s}",
 			len(*ms.FakeInstances),
-			*placement.Affinity,
-			*placement.AvailabilityZone,
+			aws.ToString(placement.Affinity),
+			aws.ToString(placement.AvailabilityZone),
 			placement.Tenancy,
 		)
 	}
@@ -143,6 +254,42 @@ func (ms *MockEC2Client) RunInstances(_ context.Context, input *ec2.RunInstances
 		}
 	}
 
+	if placement != nil && aws.ToString(placement.HostResourceGroupArn) == FullHostResourceGroup {
+		return nil, &smithy.GenericAPIError{
+			Code:    errors.InsufficientHostCapacity,
+			Message: "There is not enough capacity to fulfill your Dedicated Host request.",
+		}
+	}
+
+	var (
+		host         *ec2types.Host
+		hostCapacity *ec2types.InstanceCapacity
+	)
+	if placement != nil && placement.HostId != nil {
+		if host = ms.findHost(aws.ToString(placement.HostId)); host == nil {
+			return nil, &smithy.GenericAPIError{Code: "InvalidHostID.NotFound", Message: fmt.Sprintf("The host ID '%s' does not exist", aws.ToString(placement.HostId))}
+		}
+		if hostCapacity = findHostCapacity(host, string(input.InstanceType)); hostCapacity == nil || aws.ToInt32(hostCapacity.AvailableCapacity) < 1 {
+			return nil, &smithy.GenericAPIError{
+				Code:    errors.InsufficientHostCapacity,
+				Message: "There is not enough capacity on the host to launch the requested instance.",
+			}
+		}
+	}
+
+	if input.MaintenanceOptions != nil && input.MaintenanceOptions.AutoRecovery == ec2types.InstanceAutoRecoveryStateDefault &&
+		input.InstanceType == UnsupportedAutoRecoveryInstanceType {
+		return nil, &smithy.GenericAPIError{
+			Code:    "UnsupportedOperation",
+			Message: fmt.Sprintf("The instance type %s does not support simplified automatic recovery.", input.InstanceType),
+		}
+	}
+
+	networkInterfaces, err := ms.attachNetworkInterfaces(input.NetworkInterfaces, len(*ms.FakeInstances))
+	if err != nil {
+		return nil, err
+	}
+
 	newInstance := ec2types.Instance{
 		InstanceId:     &instanceID,
 		PrivateDnsName: &privateDNSName,
@@ -150,7 +297,60 @@ func (ms *MockEC2Client) RunInstances(_ context.Context, input *ec2.RunInstances
 			Code: aws.Int32(16),
 			Name: ec2types.InstanceStateName("running"),
 		},
-		Tags: deepCopyTagList(input.TagSpecifications[0].Tags),
+		Tags:              deepCopyTagList(input.TagSpecifications[0].Tags),
+		NetworkInterfaces: networkInterfaces,
+	}
+	if spec := input.CapacityReservationSpecification; spec != nil && spec.CapacityReservationTarget != nil {
+		newInstance.CapacityReservationId = spec.CapacityReservationTarget.CapacityReservationId
+	}
+	if input.InstanceMarketOptions != nil && input.InstanceMarketOptions.MarketType == ec2types.MarketTypeCapacityBlock {
+		newInstance.InstanceLifecycle = ec2types.InstanceLifecycleTypeCapacityBlock
+	}
+	for _, license := range input.LicenseSpecifications {
+		newInstance.Licenses = append(newInstance.Licenses, ec2types.LicenseConfiguration{LicenseConfigurationArn: license.LicenseConfigurationArn})
+	}
+	if placement != nil {
+		newInstance.Placement = &ec2types.Placement{
+			Tenancy:              placement.Tenancy,
+			GroupId:              placement.GroupId,
+			GroupName:            placement.GroupName,
+			PartitionNumber:      placement.PartitionNumber,
+			HostId:               placement.HostId,
+			HostResourceGroupArn: placement.HostResourceGroupArn,
+		}
+	}
+	if input.EnclaveOptions != nil {
+		newInstance.EnclaveOptions = &ec2types.EnclaveOptions{Enabled: input.EnclaveOptions.Enabled}
+	}
+	if input.HibernationOptions != nil {
+		newInstance.HibernationOptions = &ec2types.HibernationOptions{Configured: input.HibernationOptions.Configured}
+	}
+	if input.MaintenanceOptions != nil {
+		newInstance.MaintenanceOptions = &ec2types.InstanceMaintenanceOptions{
+			AutoRecovery:    input.MaintenanceOptions.AutoRecovery,
+			RebootMigration: ec2types.InstanceRebootMigrationStateDefault,
+		}
+	}
+	if input.CreditSpecification != nil {
+		if *ms.FakeCPUCredits == nil {
+			*ms.FakeCPUCredits = map[string]string{}
+		}
+		(*ms.FakeCPUCredits)[instanceID] = aws.ToString(input.CreditSpecification.CpuCredits)
+	}
+	if options := input.PrivateDnsNameOptions; options != nil {
+		newInstance.PrivateDnsNameOptions = &ec2types.PrivateDnsNameOptionsResponse{
+			HostnameType:                    options.HostnameType,
+			EnableResourceNameDnsARecord:    options.EnableResourceNameDnsARecord,
+			EnableResourceNameDnsAAAARecord: options.EnableResourceNameDnsAAAARecord,
+		}
+		// Like instances in IPv6-only subnets, instances with resource-name hostnames expose no private DNS name
+		if options.HostnameType == ec2types.HostnameTypeResourceName {
+			newInstance.PrivateDnsName = nil
+		}
+	}
+	if host != nil {
+		host.Instances = append(host.Instances, ec2types.HostInstance{InstanceId: aws.String(instanceID), InstanceType: aws.String(string(input.InstanceType))})
+		hostCapacity.AvailableCapacity = aws.Int32(aws.ToInt32(hostCapacity.AvailableCapacity) - 1)
 	}
 	*ms.FakeInstances = append(*ms.FakeInstances, newInstance)
 
@@ -161,6 +361,79 @@ func (ms *MockEC2Client) RunInstances(_ context.Context, input *ec2.RunInstances
 	}, nil
 }
 
+// attachNetworkInterfaces returns the network interfaces of a new instance. Existing network interfaces must be
+// available in FakeNetworkInterfaces and are marked as in use, the IDs of created interfaces are derived from the
+// instance index.
+func (ms *MockEC2Client) attachNetworkInterfaces(specs []ec2types.InstanceNetworkInterfaceSpecification, instanceIndex int) ([]ec2types.InstanceNetworkInterface, error) {
+	var networkInterfaces []ec2types.InstanceNetworkInterface
+	for i, spec := range specs {
+		networkInterfaceID := fmt.Sprintf("eni-%d-%d", instanceIndex, i)
+		if spec.NetworkInterfaceId != nil {
+			networkInterfaceID = aws.ToString(spec.NetworkInterfaceId)
+			idx := slices.IndexFunc(*ms.FakeNetworkInterfaces, func(netIf ec2types.NetworkInterface) bool {
+				return aws.ToString(netIf.NetworkInterfaceId) == networkInterfaceID
+			})
+			if idx < 0 {
+				return nil, &smithy.GenericAPIError{Code: errors.InvalidNetworkInterfaceIDNotFound}
+			}
+			if (*ms.FakeNetworkInterfaces)[idx].Status != ec2types.NetworkInterfaceStatusAvailable {
+				return nil, &smithy.GenericAPIError{Code: "InvalidNetworkInterface.InUse"}
+			}
+			(*ms.FakeNetworkInterfaces)[idx].Status = ec2types.NetworkInterfaceStatusInUse
+		}
+		networkInterface := ec2types.InstanceNetworkInterface{
+			NetworkInterfaceId: aws.String(networkInterfaceID),
+			InterfaceType:      spec.InterfaceType,
+			SourceDestCheck:    aws.Bool(true),
+			Attachment: &ec2types.InstanceNetworkInterfaceAttachment{
+				DeviceIndex:         spec.DeviceIndex,
+				NetworkCardIndex:    spec.NetworkCardIndex,
+				DeleteOnTermination: spec.DeleteOnTermination,
+			},
+		}
+		if spec.PrivateIpAddress != nil {
+			if ms.addressInUse(aws.ToString(spec.PrivateIpAddress)) {
+				return nil, &smithy.GenericAPIError{Code: errors.InvalidIPAddressInUse}
+			}
+			networkInterface.PrivateIpAddress = spec.PrivateIpAddress
+			networkInterface.PrivateIpAddresses = []ec2types.InstancePrivateIpAddress{{PrivateIpAddress: spec.PrivateIpAddress, Primary: aws.Bool(true)}}
+		}
+		for _, address := range spec.Ipv6Addresses {
+			if ms.addressInUse(aws.ToString(address.Ipv6Address)) {
+				return nil, &smithy.GenericAPIError{Code: errors.InvalidIPAddressInUse}
+			}
+			networkInterface.Ipv6Addresses = append(networkInterface.Ipv6Addresses, address)
+		}
+		networkInterfaces = append(networkInterfaces, networkInterface)
+	}
+	return networkInterfaces, nil
+}
+
+// addressInUse returns true if a fake network interface or a network interface of a fake instance which is not
+// terminated has the private IPv4 or IPv6 address.
+func (ms *MockEC2Client) addressInUse(address string) bool {
+	for _, networkInterface := range *ms.FakeNetworkInterfaces {
+		if slices.ContainsFunc(networkInterface.PrivateIpAddresses, func(a ec2types.NetworkInterfacePrivateIpAddress) bool {
+			return aws.ToString(a.PrivateIpAddress) == address
+		}) ||
+			slices.ContainsFunc(networkInterface.Ipv6Addresses, func(a ec2types.NetworkInterfaceIpv6Address) bool { return aws.ToString(a.Ipv6Address) == address }) {
+			return true
+		}
+	}
+	for _, instance := range *ms.FakeInstances {
+		if instance.State != nil && instance.State.Name == ec2types.InstanceStateNameTerminated {
+			continue
+		}
+		for _, networkInterface := range instance.NetworkInterfaces {
+			if slices.ContainsFunc(networkInterface.PrivateIpAddresses, func(a ec2types.InstancePrivateIpAddress) bool { return aws.ToString(a.PrivateIpAddress) == address }) ||
+				slices.ContainsFunc(networkInterface.Ipv6Addresses, func(a ec2types.InstanceIpv6Address) bool { return aws.ToString(a.Ipv6Address) == address }) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // DescribeInstances implements a mock run instance method
 func (ms *MockEC2Client) DescribeInstances(_ context.Context, input *ec2.DescribeInstancesInput, _ ...func(*ec2.Options)) (*ec2.DescribeInstancesOutput, error) {
 	found := false
@@ -202,8 +475,11 @@ func (ms *MockEC2Client) DescribeInstances(_ context.Context, input *ec2.Describ
 		}
 	} else {
 
-		// Target all instances
+		// Target all instances matching the filters
 		for _, instance := range *ms.FakeInstances {
+			if !matchesFilters(instance, input.Filters) {
+				continue
+			}
 			instanceToCopy := instance
 			instanceList = append(instanceList, instanceToCopy)
 		}
@@ -264,20 +540,26 @@ func (ms *MockEC2Client) TerminateInstances(_ context.Context, input *ec2.Termin
 
 	var desiredInstance ec2types.Instance
 	found := false
-	newInstanceList := make([]ec2types.Instance, 0)
 
 	for _, instanceID := range input.InstanceIds {
-		for _, instance := range *ms.FakeInstances {
+		for i, instance := range *ms.FakeInstances {
 			if *instance.InstanceId == instanceID {
-				// Do not append InstanceID, there by removing it
+				// Terminated instances stay visible until AWS eventually removes them
 				found = true
 				desiredInstance = instance
-			} else {
-				newInstanceList = append(newInstanceList, instance)
+				ms.detachNetworkInterfaces(instance.NetworkInterfaces)
+				if ms.ShuttingDownInstances {
+					(*ms.FakeInstances)[i].State = &ec2types.InstanceState{Code: aws.Int32(32), Name: ec2types.InstanceStateNameShuttingDown}
+					continue
+				}
+				ms.removeHostInstance(instanceID)
+				(*ms.FakeInstances)[i].State = &ec2types.InstanceState{
+					Code: aws.Int32(48),
+					Name: ec2types.InstanceStateNameTerminated,
+				}
 			}
 		}
 	}
-	ms.FakeInstances = &newInstanceList
 
 	if !found {
 		return nil, AWSInstanceNotFoundError
@@ -338,12 +620,510 @@ func (ms *MockEC2Client) StopInstances(_ context.Context, input *ec2.StopInstanc
 	}, nil
 }
 
+// detachNetworkInterfaces marks the network interfaces of a terminated instance which are not deleted on termination
+// as available again.
+func (ms *MockEC2Client) detachNetworkInterfaces(networkInterfaces []ec2types.InstanceNetworkInterface) {
+	for _, netIf := range networkInterfaces {
+		if netIf.Attachment == nil || aws.ToBool(netIf.Attachment.DeleteOnTermination) {
+			continue
+		}
+		for i := range *ms.FakeNetworkInterfaces {
+			if aws.ToString((*ms.FakeNetworkInterfaces)[i].NetworkInterfaceId) == aws.ToString(netIf.NetworkInterfaceId) {
+				(*ms.FakeNetworkInterfaces)[i].Status = ec2types.NetworkInterfaceStatusAvailable
+			}
+		}
+	}
+}
+
 // ModifyNetworkInterfaceAttribute implements a mock modify network interface attribute method
-func (ms *MockEC2Client) ModifyNetworkInterfaceAttribute(_ context.Context, _ *ec2.ModifyNetworkInterfaceAttributeInput, _ ...func(*ec2.Options)) (*ec2.ModifyNetworkInterfaceAttributeOutput, error) {
-	// Always succeed for mock
+func (ms *MockEC2Client) ModifyNetworkInterfaceAttribute(_ context.Context, input *ec2.ModifyNetworkInterfaceAttributeInput, _ ...func(*ec2.Options)) (*ec2.ModifyNetworkInterfaceAttributeOutput, error) {
+	// Always succeed for mock, the source/destination check is applied to the network interface of the fake instance
+	if input.SourceDestCheck == nil {
+		return &ec2.ModifyNetworkInterfaceAttributeOutput{}, nil
+	}
+	for i := range *ms.FakeInstances {
+		for j := range (*ms.FakeInstances)[i].NetworkInterfaces {
+			networkInterface := &(*ms.FakeInstances)[i].NetworkInterfaces[j]
+			if aws.ToString(networkInterface.NetworkInterfaceId) == aws.ToString(input.NetworkInterfaceId) {
+				networkInterface.SourceDestCheck = input.SourceDestCheck.Value
+			}
+		}
+	}
 	return &ec2.ModifyNetworkInterfaceAttributeOutput{}, nil
 }
 
+// AssignPrivateIpAddresses implements a mock assign private ip addresses method, which adds the requested number of
+// secondary private IPv4 addresses and IPv4 prefixes to the network interface of the fake instance
+func (ms *MockEC2Client) AssignPrivateIpAddresses(_ context.Context, input *ec2.AssignPrivateIpAddressesInput, _ ...func(*ec2.Options)) (*ec2.AssignPrivateIpAddressesOutput, error) {
+	for i := range *ms.FakeInstances {
+		networkInterfaces := (*ms.FakeInstances)[i].NetworkInterfaces
+		for j := range networkInterfaces {
+			netIf := &networkInterfaces[j]
+			if aws.ToString(netIf.NetworkInterfaceId) != aws.ToString(input.NetworkInterfaceId) {
+				continue
+			}
+			for range aws.ToInt32(input.SecondaryPrivateIpAddressCount) {
+				netIf.PrivateIpAddresses = append(netIf.PrivateIpAddresses, ec2types.InstancePrivateIpAddress{
+					PrivateIpAddress: aws.String(fmt.Sprintf("10.0.0.%d", len(netIf.PrivateIpAddresses)+1)),
+					Primary:          aws.Bool(false),
+				})
+			}
+			for range aws.ToInt32(input.Ipv4PrefixCount) {
+				netIf.Ipv4Prefixes = append(netIf.Ipv4Prefixes, ec2types.InstanceIpv4Prefix{
+					Ipv4Prefix: aws.String(fmt.Sprintf("10.1.%d.0/28", len(netIf.Ipv4Prefixes))),
+				})
+			}
+			return &ec2.AssignPrivateIpAddressesOutput{NetworkInterfaceId: input.NetworkInterfaceId}, nil
+		}
+	}
+	return nil, &smithy.GenericAPIError{Code: errors.InvalidNetworkInterfaceIDNotFound}
+}
+
+// DescribeInstanceCreditSpecifications implements a mock describe instance credit specifications method returning the
+// CPU credits of the requested instances
+func (ms *MockEC2Client) DescribeInstanceCreditSpecifications(_ context.Context, input *ec2.DescribeInstanceCreditSpecificationsInput, _ ...func(*ec2.Options)) (*ec2.DescribeInstanceCreditSpecificationsOutput, error) {
+	output := &ec2.DescribeInstanceCreditSpecificationsOutput{}
+	for _, instanceID := range input.InstanceIds {
+		cpuCredits, ok := (*ms.FakeCPUCredits)[instanceID]
+		if !ok {
+			cpuCredits = "standard"
+		}
+		output.InstanceCreditSpecifications = append(output.InstanceCreditSpecifications, ec2types.InstanceCreditSpecification{
+			InstanceId: aws.String(instanceID),
+			CpuCredits: aws.String(cpuCredits),
+		})
+	}
+	return output, nil
+}
+
+// ModifyInstanceCreditSpecification implements a mock modify instance credit specification method
+func (ms *MockEC2Client) ModifyInstanceCreditSpecification(_ context.Context, input *ec2.ModifyInstanceCreditSpecificationInput, _ ...func(*ec2.Options)) (*ec2.ModifyInstanceCreditSpecificationOutput, error) {
+	if *ms.FakeCPUCredits == nil {
+		*ms.FakeCPUCredits = map[string]string{}
+	}
+	output := &ec2.ModifyInstanceCreditSpecificationOutput{}
+	for _, spec := range input.InstanceCreditSpecifications {
+		(*ms.FakeCPUCredits)[aws.ToString(spec.InstanceId)] = aws.ToString(spec.CpuCredits)
+		output.SuccessfulInstanceCreditSpecifications = append(output.SuccessfulInstanceCreditSpecifications, ec2types.SuccessfulInstanceCreditSpecificationItem{InstanceId: spec.InstanceId})
+	}
+	return output, nil
+}
+
+// ModifyInstanceMaintenanceOptions implements a mock modify instance maintenance options method
+func (ms *MockEC2Client) ModifyInstanceMaintenanceOptions(_ context.Context, input *ec2.ModifyInstanceMaintenanceOptionsInput, _ ...func(*ec2.Options)) (*ec2.ModifyInstanceMaintenanceOptionsOutput, error) {
+	for i := range *ms.FakeInstances {
+		instance := &(*ms.FakeInstances)[i]
+		if aws.ToString(instance.InstanceId) != aws.ToString(input.InstanceId) {
+			continue
+		}
+		if instance.MaintenanceOptions == nil {
+			instance.MaintenanceOptions = &ec2types.InstanceMaintenanceOptions{}
+		}
+		if input.AutoRecovery != "" {
+			instance.MaintenanceOptions.AutoRecovery = input.AutoRecovery
+		}
+		if input.RebootMigration != "" {
+			instance.MaintenanceOptions.RebootMigration = input.RebootMigration
+		}
+		return &ec2.ModifyInstanceMaintenanceOptionsOutput{
+			InstanceId:      input.InstanceId,
+			AutoRecovery:    instance.MaintenanceOptions.AutoRecovery,
+			RebootMigration: instance.MaintenanceOptions.RebootMigration,
+		}, nil
+	}
+	return nil, &smithy.GenericAPIError{Code: string(errors.InstanceIDNotFound)}
+}
+
+// DescribeVolumes implements a mock describe volumes method returning all volumes matching the filters.
+// Supported filters are "tag:<key>", "tag-key" and "status".
+func (ms *MockEC2Client) DescribeVolumes(_ context.Context, input *ec2.DescribeVolumesInput, _ ...func(*ec2.Options)) (*ec2.DescribeVolumesOutput, error) {
+	var volumes []ec2types.Volume
+	for _, volume := range *ms.FakeVolumes {
+		if matchesTagFilters(volume.Tags, input.Filters) && matchesValueFilter(string(volume.State), "status", input.Filters) {
+			volumes = append(volumes, volume)
+		}
+	}
+	return &ec2.DescribeVolumesOutput{Volumes: volumes}, nil
+}
+
+// DeleteVolume implements a mock delete volume method
+func (ms *MockEC2Client) DeleteVolume(_ context.Context, input *ec2.DeleteVolumeInput, _ ...func(*ec2.Options)) (*ec2.DeleteVolumeOutput, error) {
+	for i, volume := range *ms.FakeVolumes {
+		if aws.ToString(volume.VolumeId) == aws.ToString(input.VolumeId) {
+			*ms.FakeVolumes = slices.Delete(*ms.FakeVolumes, i, i+1)
+			return &ec2.DeleteVolumeOutput{}, nil
+		}
+	}
+	return nil, &smithy.GenericAPIError{Code: "InvalidVolume.NotFound"}
+}
+
+// DescribeNetworkInterfaces implements a mock describe network interfaces method returning all network interfaces
+// matching the filters. Supported filters are "tag:<key>", "tag-key" and "status".
+func (ms *MockEC2Client) DescribeNetworkInterfaces(_ context.Context, input *ec2.DescribeNetworkInterfacesInput, _ ...func(*ec2.Options)) (*ec2.DescribeNetworkInterfacesOutput, error) {
+	var networkInterfaces []ec2types.NetworkInterface
+	for _, networkInterface := range *ms.FakeNetworkInterfaces {
+		if len(input.NetworkInterfaceIds) > 0 && !slices.Contains(input.NetworkInterfaceIds, aws.ToString(networkInterface.NetworkInterfaceId)) {
+			continue
+		}
+		if matchesTagFilters(networkInterface.TagSet, input.Filters) && matchesValueFilter(string(networkInterface.Status), "status", input.Filters) {
+			networkInterfaces = append(networkInterfaces, networkInterface)
+		}
+	}
+	return &ec2.DescribeNetworkInterfacesOutput{NetworkInterfaces: networkInterfaces}, nil
+}
+
+// DeleteNetworkInterface implements a mock delete network interface method
+func (ms *MockEC2Client) DeleteNetworkInterface(_ context.Context, input *ec2.DeleteNetworkInterfaceInput, _ ...func(*ec2.Options)) (*ec2.DeleteNetworkInterfaceOutput, error) {
+	for i, networkInterface := range *ms.FakeNetworkInterfaces {
+		if aws.ToString(networkInterface.NetworkInterfaceId) == aws.ToString(input.NetworkInterfaceId) {
+			*ms.FakeNetworkInterfaces = slices.Delete(*ms.FakeNetworkInterfaces, i, i+1)
+			return &ec2.DeleteNetworkInterfaceOutput{}, nil
+		}
+	}
+	return nil, &smithy.GenericAPIError{Code: errors.InvalidNetworkInterfaceIDNotFound}
+}
+
+// DescribeSubnets implements a mock describe subnets method returning the requested subnets
+func (ms *MockEC2Client) DescribeSubnets(_ context.Context, input *ec2.DescribeSubnetsInput, _ ...func(*ec2.Options)) (*ec2.DescribeSubnetsOutput, error) {
+	var subnets []ec2types.Subnet
+	for _, subnetID := range input.SubnetIds {
+		idx := slices.IndexFunc(ms.FakeSubnets, func(subnet ec2types.Subnet) bool { return aws.ToString(subnet.SubnetId) == subnetID })
+		if idx < 0 {
+			return nil, &smithy.GenericAPIError{Code: errors.InvalidSubnetIDNotFound, Message: fmt.Sprintf("The subnet ID '%s' does not exist", subnetID)}
+		}
+		subnets = append(subnets, ms.FakeSubnets[idx])
+	}
+	return &ec2.DescribeSubnetsOutput{Subnets: subnets}, nil
+}
+
+// DescribeSecurityGroups implements a mock describe security groups method returning the requested security groups
+func (ms *MockEC2Client) DescribeSecurityGroups(_ context.Context, input *ec2.DescribeSecurityGroupsInput, _ ...func(*ec2.Options)) (*ec2.DescribeSecurityGroupsOutput, error) {
+	var securityGroups []ec2types.SecurityGroup
+	for _, groupID := range input.GroupIds {
+		idx := slices.IndexFunc(ms.FakeSecurityGroups, func(group ec2types.SecurityGroup) bool { return aws.ToString(group.GroupId) == groupID })
+		if idx < 0 {
+			return nil, &smithy.GenericAPIError{Code: errors.InvalidGroupNotFound, Message: fmt.Sprintf("The security group '%s' does not exist", groupID)}
+		}
+		securityGroups = append(securityGroups, ms.FakeSecurityGroups[idx])
+	}
+	return &ec2.DescribeSecurityGroupsOutput{SecurityGroups: securityGroups}, nil
+}
+
+// DescribeCapacityReservations implements a mock describe capacity reservations method returning the requested
+// capacity reservations
+func (ms *MockEC2Client) DescribeCapacityReservations(_ context.Context, input *ec2.DescribeCapacityReservationsInput, _ ...func(*ec2.Options)) (*ec2.DescribeCapacityReservationsOutput, error) {
+	var reservations []ec2types.CapacityReservation
+	for _, reservationID := range input.CapacityReservationIds {
+		idx := slices.IndexFunc(ms.FakeCapacityReservations, func(reservation ec2types.CapacityReservation) bool {
+			return aws.ToString(reservation.CapacityReservationId) == reservationID
+		})
+		if idx < 0 {
+			return nil, &smithy.GenericAPIError{Code: errors.InvalidCapacityReservationIDNotFound, Message: fmt.Sprintf("The capacity reservation ID '%s' does not exist", reservationID)}
+		}
+		reservations = append(reservations, ms.FakeCapacityReservations[idx])
+	}
+	return &ec2.DescribeCapacityReservationsOutput{CapacityReservations: reservations}, nil
+}
+
+// DescribePlacementGroups implements a mock describe placement groups method returning the requested placement groups
+func (ms *MockEC2Client) DescribePlacementGroups(_ context.Context, input *ec2.DescribePlacementGroupsInput, _ ...func(*ec2.Options)) (*ec2.DescribePlacementGroupsOutput, error) {
+	var placementGroups []ec2types.PlacementGroup
+	for _, group := range ms.FakePlacementGroups {
+		if slices.Contains(input.GroupIds, aws.ToString(group.GroupId)) || slices.Contains(input.GroupNames, aws.ToString(group.GroupName)) {
+			placementGroups = append(placementGroups, group)
+		}
+	}
+	if len(placementGroups) < len(input.GroupIds)+len(input.GroupNames) {
+		return nil, &smithy.GenericAPIError{Code: errors.InvalidPlacementGroupUnknown, Message: "The specified placement group does not exist."}
+	}
+	return &ec2.DescribePlacementGroupsOutput{PlacementGroups: placementGroups}, nil
+}
+
+// DescribeHosts implements a mock describe hosts method returning the requested hosts which match the "tag:<key>",
+// "tag-key", "availability-zone" and "state" filters
+func (ms *MockEC2Client) DescribeHosts(_ context.Context, input *ec2.DescribeHostsInput, _ ...func(*ec2.Options)) (*ec2.DescribeHostsOutput, error) {
+	var hosts []ec2types.Host
+	for _, host := range *ms.FakeHosts {
+		if len(input.HostIds) > 0 && !slices.Contains(input.HostIds, aws.ToString(host.HostId)) {
+			continue
+		}
+		if matchesTagFilters(host.Tags, input.Filter) && matchesValueFilter(aws.ToString(host.AvailabilityZone), "availability-zone", input.Filter) &&
+			matchesValueFilter(string(host.State), "state", input.Filter) {
+			hosts = append(hosts, host)
+		}
+	}
+	return &ec2.DescribeHostsOutput{Hosts: hosts}, nil
+}
+
+// AllocateHosts implements a mock allocate hosts method, every host fits FakeHostCapacity instances
+func (ms *MockEC2Client) AllocateHosts(_ context.Context, input *ec2.AllocateHostsInput, _ ...func(*ec2.Options)) (*ec2.AllocateHostsOutput, error) {
+	var hostIDs []string
+	for range aws.ToInt32(input.Quantity) {
+		host := ec2types.Host{
+			HostId:           aws.String(fmt.Sprintf("h-0123456789-%d", len(*ms.FakeHosts))),
+			AvailabilityZone: input.AvailabilityZone,
+			AutoPlacement:    input.AutoPlacement,
+			AllocationTime:   aws.Time(time.Now()),
+			State:            ec2types.AllocationStateAvailable,
+			HostProperties:   &ec2types.HostProperties{InstanceType: input.InstanceType},
+			AvailableCapacity: &ec2types.AvailableCapacity{
+				AvailableInstanceCapacity: []ec2types.InstanceCapacity{{
+					InstanceType:      input.InstanceType,
+					AvailableCapacity: aws.Int32(FakeHostCapacity),
+					TotalCapacity:     aws.Int32(FakeHostCapacity),
+				}},
+			},
+		}
+		for _, tagSpec := range input.TagSpecifications {
+			host.Tags = append(host.Tags, deepCopyTagList(tagSpec.Tags)...)
+		}
+		*ms.FakeHosts = append(*ms.FakeHosts, host)
+		hostIDs = append(hostIDs, aws.ToString(host.HostId))
+	}
+	return &ec2.AllocateHostsOutput{HostIds: hostIDs}, nil
+}
+
+// ReleaseHosts implements a mock release hosts method, which does not release hosts with instances
+func (ms *MockEC2Client) ReleaseHosts(_ context.Context, input *ec2.ReleaseHostsInput, _ ...func(*ec2.Options)) (*ec2.ReleaseHostsOutput, error) {
+	output := &ec2.ReleaseHostsOutput{}
+	for _, hostID := range input.HostIds {
+		host := ms.findHost(hostID)
+		switch {
+		case host == nil:
+			output.Unsuccessful = append(output.Unsuccessful, ec2types.UnsuccessfulItem{
+				ResourceId: aws.String(hostID),
+				Error:      &ec2types.UnsuccessfulItemError{Code: aws.String("Client.InvalidHostID.NotFound"), Message: aws.String("The specified host does not exist.")},
+			})
+		case len(host.Instances) > 0:
+			output.Unsuccessful = append(output.Unsuccessful, ec2types.UnsuccessfulItem{
+				ResourceId: aws.String(hostID),
+				Error:      &ec2types.UnsuccessfulItemError{Code: aws.String("Client.InvalidHost.Occupied"), Message: aws.String("The specified host has running instances.")},
+			})
+		default:
+			host.State = ec2types.AllocationStateReleased
+			output.Successful = append(output.Successful, hostID)
+		}
+	}
+	return output, nil
+}
+
+func (ms *MockEC2Client) findHost(hostID string) *ec2types.Host {
+	for i := range *ms.FakeHosts {
+		if aws.ToString((*ms.FakeHosts)[i].HostId) == hostID && (*ms.FakeHosts)[i].State != ec2types.AllocationStateReleased {
+			return &(*ms.FakeHosts)[i]
+		}
+	}
+	return nil
+}
+
+// removeHostInstance removes the instance from its host and frees its capacity.
+func (ms *MockEC2Client) removeHostInstance(instanceID string) {
+	for i := range *ms.FakeHosts {
+		host := &(*ms.FakeHosts)[i]
+		idx := slices.IndexFunc(host.Instances, func(instance ec2types.HostInstance) bool { return aws.ToString(instance.InstanceId) == instanceID })
+		if idx < 0 {
+			continue
+		}
+		if capacity := findHostCapacity(host, aws.ToString(host.Instances[idx].InstanceType)); capacity != nil {
+			capacity.AvailableCapacity = aws.Int32(aws.ToInt32(capacity.AvailableCapacity) + 1)
+		}
+		host.Instances = slices.Delete(host.Instances, idx, idx+1)
+	}
+}
+
+func findHostCapacity(host *ec2types.Host, instanceType string) *ec2types.InstanceCapacity {
+	if host.AvailableCapacity == nil {
+		return nil
+	}
+	for i, capacity := range host.AvailableCapacity.AvailableInstanceCapacity {
+		if aws.ToString(capacity.InstanceType) == instanceType {
+			return &host.AvailableCapacity.AvailableInstanceCapacity[i]
+		}
+	}
+	return nil
+}
+
+// DescribeInstanceTypes implements a mock describe instance types method returning the requested instance types
+func (ms *MockEC2Client) DescribeInstanceTypes(_ context.Context, input *ec2.DescribeInstanceTypesInput, _ ...func(*ec2.Options)) (*ec2.DescribeInstanceTypesOutput, error) {
+	var instanceTypes []ec2types.InstanceTypeInfo
+	for _, instanceType := range input.InstanceTypes {
+		idx := slices.IndexFunc(ms.FakeInstanceTypes, func(info ec2types.InstanceTypeInfo) bool { return info.InstanceType == instanceType })
+		if idx < 0 {
+			return nil, &smithy.GenericAPIError{Code: errors.InvalidInstanceType, Message: fmt.Sprintf("The following supplied instance types do not exist: [%s]", instanceType)}
+		}
+		instanceTypes = append(instanceTypes, ms.FakeInstanceTypes[idx])
+	}
+	return &ec2.DescribeInstanceTypesOutput{InstanceTypes: instanceTypes}, nil
+}
+
+// DescribeAddresses implements a mock describe addresses method returning all Elastic IPs matching the "tag:<key>"
+// filters
+func (ms *MockEC2Client) DescribeAddresses(_ context.Context, input *ec2.DescribeAddressesInput, _ ...func(*ec2.Options)) (*ec2.DescribeAddressesOutput, error) {
+	var addresses []ec2types.Address
+	for _, address := range *ms.FakeAddresses {
+		if matchesTagFilters(address.Tags, input.Filters) {
+			address.Tags = deepCopyTagList(address.Tags)
+			addresses = append(addresses, address)
+		}
+	}
+	for _, address := range addresses {
+		allocationID := aws.ToString(address.AllocationId)
+		if address.AssociationId == nil && slices.Contains(*ms.ConcurrentAddressClaims, allocationID) {
+			claimed := ms.findAddress(allocationID)
+			claimed.InstanceId = aws.String("i-concurrent")
+			claimed.AssociationId = aws.String("eipassoc-concurrent-" + allocationID)
+			*ms.ConcurrentAddressClaims = slices.DeleteFunc(*ms.ConcurrentAddressClaims, func(id string) bool { return id == allocationID })
+		}
+	}
+	return &ec2.DescribeAddressesOutput{Addresses: addresses}, nil
+}
+
+// AllocateAddress implements a mock allocate address method
+func (ms *MockEC2Client) AllocateAddress(_ context.Context, input *ec2.AllocateAddressInput, _ ...func(*ec2.Options)) (*ec2.AllocateAddressOutput, error) {
+	address := ec2types.Address{
+		AllocationId: aws.String(fmt.Sprintf("eipalloc-%d", len(*ms.FakeAddresses))),
+		PublicIp:     aws.String(fmt.Sprintf("198.51.100.%d", len(*ms.FakeAddresses))),
+		Domain:       input.Domain,
+	}
+	for _, tagSpec := range input.TagSpecifications {
+		address.Tags = append(address.Tags, deepCopyTagList(tagSpec.Tags)...)
+	}
+	*ms.FakeAddresses = append(*ms.FakeAddresses, address)
+	return &ec2.AllocateAddressOutput{AllocationId: address.AllocationId, PublicIp: address.PublicIp, Domain: address.Domain}, nil
+}
+
+// AssociateAddress implements a mock associate address method
+func (ms *MockEC2Client) AssociateAddress(_ context.Context, input *ec2.AssociateAddressInput, _ ...func(*ec2.Options)) (*ec2.AssociateAddressOutput, error) {
+	address := ms.findAddress(aws.ToString(input.AllocationId))
+	if address == nil {
+		return nil, &smithy.GenericAPIError{Code: "InvalidAllocationID.NotFound"}
+	}
+	if address.AssociationId != nil && !aws.ToBool(input.AllowReassociation) {
+		return nil, &smithy.GenericAPIError{Code: "Resource.AlreadyAssociated"}
+	}
+	instanceID := aws.ToString(input.InstanceId)
+	if input.NetworkInterfaceId != nil {
+		for _, instance := range *ms.FakeInstances {
+			if slices.ContainsFunc(instance.NetworkInterfaces, func(netIf ec2types.InstanceNetworkInterface) bool {
+				return aws.ToString(netIf.NetworkInterfaceId) == aws.ToString(input.NetworkInterfaceId)
+			}) {
+				instanceID = aws.ToString(instance.InstanceId)
+			}
+		}
+	}
+	address.InstanceId = aws.String(instanceID)
+	address.NetworkInterfaceId = input.NetworkInterfaceId
+	address.AssociationId = aws.String("eipassoc-" + strings.TrimPrefix(aws.ToString(address.AllocationId), "eipalloc-"))
+	return &ec2.AssociateAddressOutput{AssociationId: address.AssociationId}, nil
+}
+
+// DisassociateAddress implements a mock disassociate address method
+func (ms *MockEC2Client) DisassociateAddress(_ context.Context, input *ec2.DisassociateAddressInput, _ ...func(*ec2.Options)) (*ec2.DisassociateAddressOutput, error) {
+	for i, address := range *ms.FakeAddresses {
+		if aws.ToString(address.AssociationId) == aws.ToString(input.AssociationId) {
+			(*ms.FakeAddresses)[i].AssociationId = nil
+			(*ms.FakeAddresses)[i].InstanceId = nil
+			(*ms.FakeAddresses)[i].NetworkInterfaceId = nil
+			return &ec2.DisassociateAddressOutput{}, nil
+		}
+	}
+	return nil, &smithy.GenericAPIError{Code: errors.InvalidAssociationIDNotFound}
+}
+
+// ReleaseAddress implements a mock release address method
+func (ms *MockEC2Client) ReleaseAddress(_ context.Context, input *ec2.ReleaseAddressInput, _ ...func(*ec2.Options)) (*ec2.ReleaseAddressOutput, error) {
+	for i, address := range *ms.FakeAddresses {
+		if aws.ToString(address.AllocationId) == aws.ToString(input.AllocationId) {
+			if address.AssociationId != nil {
+				return nil, &smithy.GenericAPIError{Code: "InvalidIPAddress.InUse"}
+			}
+			*ms.FakeAddresses = slices.Delete(*ms.FakeAddresses, i, i+1)
+			return &ec2.ReleaseAddressOutput{}, nil
+		}
+	}
+	return nil, &smithy.GenericAPIError{Code: "InvalidAllocationID.NotFound"}
+}
+
+// CreateTags implements a mock create tags method, which supports Elastic IPs only
+func (ms *MockEC2Client) CreateTags(_ context.Context, input *ec2.CreateTagsInput, _ ...func(*ec2.Options)) (*ec2.CreateTagsOutput, error) {
+	for _, resource := range input.Resources {
+		address := ms.findAddress(resource)
+		if address == nil {
+			return nil, &smithy.GenericAPIError{Code: "InvalidID"}
+		}
+		for _, tag := range input.Tags {
+			address.Tags = slices.DeleteFunc(address.Tags, func(t ec2types.Tag) bool { return aws.ToString(t.Key) == aws.ToString(tag.Key) })
+			address.Tags = append(address.Tags, deepCopyTagList([]ec2types.Tag{tag})...)
+		}
+	}
+	return &ec2.CreateTagsOutput{}, nil
+}
+
+// DeleteTags implements a mock delete tags method, which supports Elastic IPs only
+func (ms *MockEC2Client) DeleteTags(_ context.Context, input *ec2.DeleteTagsInput, _ ...func(*ec2.Options)) (*ec2.DeleteTagsOutput, error) {
+	for _, resource := range input.Resources {
+		address := ms.findAddress(resource)
+		if address == nil {
+			return nil, &smithy.GenericAPIError{Code: "InvalidID"}
+		}
+		for _, tag := range input.Tags {
+			address.Tags = slices.DeleteFunc(address.Tags, func(t ec2types.Tag) bool { return aws.ToString(t.Key) == aws.ToString(tag.Key) })
+		}
+	}
+	return &ec2.DeleteTagsOutput{}, nil
+}
+
+func (ms *MockEC2Client) findAddress(allocationID string) *ec2types.Address {
+	for i := range *ms.FakeAddresses {
+		if aws.ToString((*ms.FakeAddresses)[i].AllocationId) == allocationID {
+			return &(*ms.FakeAddresses)[i]
+		}
+	}
+	return nil
+}
+
+// matchesFilters returns true if the instance matches all supported DescribeInstances filters.
+// Supported filters are "tag:<key>", "tag-key" and "instance-state-name", all others are ignored.
+func matchesFilters(instance ec2types.Instance, filters []ec2types.Filter) bool {
+	state := ""
+	if instance.State != nil {
+		state = string(instance.State.Name)
+	}
+	return matchesTagFilters(instance.Tags, filters) && matchesValueFilter(state, "instance-state-name", filters)
+}
+
+// matchesTagFilters returns true if the tags match all "tag:<key>" and "tag-key" filters.
+func matchesTagFilters(tags []ec2types.Tag, filters []ec2types.Filter) bool {
+	for _, filter := range filters {
+		name := aws.ToString(filter.Name)
+		switch {
+		case strings.HasPrefix(name, "tag:"):
+			if !slices.ContainsFunc(tags, func(tag ec2types.Tag) bool {
+				return aws.ToString(tag.Key) == strings.TrimPrefix(name, "tag:") && slices.Contains(filter.Values, aws.ToString(tag.Value))
+			}) {
+				return false
+			}
+		case name == "tag-key":
+			if !slices.ContainsFunc(tags, func(tag ec2types.Tag) bool {
+				return slices.Contains(filter.Values, aws.ToString(tag.Key))
+			}) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// matchesValueFilter returns true if the value matches all filters with the given name. Empty values always match.
+func matchesValueFilter(value, filterName string, filters []ec2types.Filter) bool {
+	for _, filter := range filters {
+		if aws.ToString(filter.Name) == filterName && value != "" && !slices.Contains(filter.Values, value) {
+			return false
+		}
+	}
+	return true
+}
+
 // deepCopyTagList copies inTags list to outTags
 func deepCopyTagList(inTags []ec2types.Tag) []ec2types.Tag {
 	var outTags []ec2types.Tag
@@ -0,0 +1,323 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package orphan finds and deletes AWS resources which carry the cluster tag of a MachineClass but do not belong
+// to any Machine object.
+package orphan
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	v1alpha1 "github.com/gardener/machine-controller-manager/pkg/apis/machine/v1alpha1"
+	"k8s.io/klog/v2"
+
+	"github.com/gardener/machine-controller-manager-provider-aws/pkg/aws/interfaces"
+)
+
+const (
+	// KindInstance is the kind of orphaned EC2 instances.
+	KindInstance = "instance"
+	// KindVolume is the kind of orphaned (available) EBS volumes.
+	KindVolume = "volume"
+	// KindNetworkInterface is the kind of orphaned (available) network interfaces.
+	KindNetworkInterface = "network-interface"
+)
+
+// Options configures a collection run.
+type Options struct {
+	// ClusterTag is the key of the cluster tag, e.g. "kubernetes.io/cluster/shoot--foo--bar".
+	ClusterTag string
+	// RoleTag is the key of the role tag, e.g. "kubernetes.io/role/node". Like ListMachines of the driver, only
+	// resources carrying both the cluster and the role tag are considered.
+	RoleTag string
+	// MinAge excludes resources younger than the given duration. Network interfaces have no creation timestamp and
+	// are therefore not subject to the age threshold.
+	MinAge time.Duration
+	// ExcludeTags excludes resources carrying any of the tags. An empty value matches every value of the tag key.
+	ExcludeTags map[string]string
+	// Delete deletes the orphaned resources. By default they are only reported.
+	Delete bool
+	// Now returns the current time, it defaults to time.Now.
+	Now func() time.Time
+}
+
+// Resource is an orphaned AWS resource.
+type Resource struct {
+	// Kind is one of KindInstance, KindVolume or KindNetworkInterface.
+	Kind string `json:"kind"`
+	// ID is the AWS resource ID.
+	ID string `json:"id"`
+	// Name is the value of the Name tag, if any.
+	Name string `json:"name,omitempty"`
+	// CreationTime is the launch or creation time of the resource, if known.
+	CreationTime *time.Time `json:"creationTime,omitempty"`
+	// Deleted is true if the resource has been deleted (or termination was requested).
+	Deleted bool `json:"deleted"`
+	// Error contains the error of a failed deletion.
+	Error string `json:"error,omitempty"`
+}
+
+// Report is the result of a collection run.
+type Report struct {
+	// ClusterTag is the cluster tag the resources were selected by.
+	ClusterTag string `json:"clusterTag"`
+	// DryRun is true if no resources were deleted.
+	DryRun bool `json:"dryRun"`
+	// Resources contains all orphaned resources.
+	Resources []Resource `json:"resources"`
+}
+
+// Failed returns the number of resources whose deletion failed.
+func (r *Report) Failed() int {
+	failed := 0
+	for _, resource := range r.Resources {
+		if resource.Error != "" {
+			failed++
+		}
+	}
+	return failed
+}
+
+// Collect lists all resources carrying the cluster tag which do not correspond to any of the given machines and
+// deletes them if Options.Delete is set. Instances correspond to a machine if either their Name tag matches the
+// machine name or their ID is part of the machine's providerID. Instances, available volumes and available network
+// interfaces are only considered if they also carry the role tag and a Name tag, like the ones created by the driver
+// do. This keeps volumes of persistent volumes and network interfaces of other components, which may carry the cluster
+// tag and a Name tag as well, out of the collection.
+func Collect(ctx context.Context, client interfaces.Ec2Client, machines []v1alpha1.Machine, opts Options) (*Report, error) {
+	if opts.ClusterTag == "" {
+		return nil, fmt.Errorf("cluster tag is required")
+	}
+	if opts.RoleTag == "" {
+		return nil, fmt.Errorf("role tag is required")
+	}
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+
+	report := &Report{
+		ClusterTag: opts.ClusterTag,
+		DryRun:     !opts.Delete,
+	}
+
+	machineNames := make(map[string]struct{}, len(machines))
+	providerIDs := make(map[string]struct{}, len(machines))
+	for _, machine := range machines {
+		machineNames[machine.Name] = struct{}{}
+		if machine.Spec.ProviderID != "" {
+			providerIDs[instanceIDFromProviderID(machine.Spec.ProviderID)] = struct{}{}
+		}
+	}
+
+	instances, err := orphanedInstances(ctx, client, machineNames, providerIDs, opts)
+	if err != nil {
+		return nil, err
+	}
+	volumes, err := orphanedVolumes(ctx, client, machineNames, opts)
+	if err != nil {
+		return nil, err
+	}
+	networkInterfaces, err := orphanedNetworkInterfaces(ctx, client, machineNames, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// Instances are deleted first, since their volumes and network interfaces only become available afterwards
+	// and are then picked up by the next run.
+	for _, resources := range [][]Resource{instances, volumes, networkInterfaces} {
+		for _, resource := range resources {
+			if opts.Delete {
+				if err := deleteResource(ctx, client, resource); err != nil {
+					klog.Errorf("Failed to delete orphaned %s %q: %v", resource.Kind, resource.ID, err)
+					resource.Error = err.Error()
+				} else {
+					klog.V(2).Infof("Deleted orphaned %s %q", resource.Kind, resource.ID)
+					resource.Deleted = true
+				}
+			}
+			report.Resources = append(report.Resources, resource)
+		}
+	}
+	return report, nil
+}
+
+func orphanedInstances(ctx context.Context, client interfaces.Ec2Client, machineNames, providerIDs map[string]struct{}, opts Options) ([]Resource, error) {
+	input := &ec2.DescribeInstancesInput{
+		Filters: []ec2types.Filter{
+			{
+				Name:   aws.String("tag-key"),
+				Values: []string{opts.ClusterTag},
+			},
+			{
+				Name:   aws.String("tag-key"),
+				Values: []string{opts.RoleTag},
+			},
+			{
+				Name: aws.String("instance-state-name"),
+				Values: []string{
+					string(ec2types.InstanceStateNamePending),
+					string(ec2types.InstanceStateNameRunning),
+					string(ec2types.InstanceStateNameStopping),
+					string(ec2types.InstanceStateNameStopped),
+				},
+			},
+		},
+	}
+
+	var resources []Resource
+	paginator := ec2.NewDescribeInstancesPaginator(client, input, func(opt *ec2.DescribeInstancesPaginatorOptions) {
+		opt.StopOnDuplicateToken = true
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, reservation := range page.Reservations {
+			for _, instance := range reservation.Instances {
+				id := aws.ToString(instance.InstanceId)
+				name := tagValue(instance.Tags, "Name")
+				if _, ok := machineNames[name]; ok || name == "" {
+					continue
+				}
+				if _, ok := providerIDs[id]; ok {
+					continue
+				}
+				if excluded(instance.Tags, instance.LaunchTime, opts) {
+					continue
+				}
+				resources = append(resources, Resource{Kind: KindInstance, ID: id, Name: name, CreationTime: instance.LaunchTime})
+			}
+		}
+	}
+	return resources, nil
+}
+
+func orphanedVolumes(ctx context.Context, client interfaces.Ec2Client, machineNames map[string]struct{}, opts Options) ([]Resource, error) {
+	input := &ec2.DescribeVolumesInput{
+		Filters: []ec2types.Filter{
+			{
+				Name:   aws.String("tag-key"),
+				Values: []string{opts.ClusterTag},
+			},
+			{
+				Name:   aws.String("tag-key"),
+				Values: []string{opts.RoleTag},
+			},
+			{
+				Name:   aws.String("status"),
+				Values: []string{string(ec2types.VolumeStateAvailable)},
+			},
+		},
+	}
+
+	var resources []Resource
+	paginator := ec2.NewDescribeVolumesPaginator(client, input, func(opt *ec2.DescribeVolumesPaginatorOptions) {
+		opt.StopOnDuplicateToken = true
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, volume := range page.Volumes {
+			name := tagValue(volume.Tags, "Name")
+			if _, ok := machineNames[name]; ok || name == "" {
+				continue
+			}
+			if excluded(volume.Tags, volume.CreateTime, opts) {
+				continue
+			}
+			resources = append(resources, Resource{Kind: KindVolume, ID: aws.ToString(volume.VolumeId), Name: name, CreationTime: volume.CreateTime})
+		}
+	}
+	return resources, nil
+}
+
+func orphanedNetworkInterfaces(ctx context.Context, client interfaces.Ec2Client, machineNames map[string]struct{}, opts Options) ([]Resource, error) {
+	input := &ec2.DescribeNetworkInterfacesInput{
+		Filters: []ec2types.Filter{
+			{
+				Name:   aws.String("tag-key"),
+				Values: []string{opts.ClusterTag},
+			},
+			{
+				Name:   aws.String("tag-key"),
+				Values: []string{opts.RoleTag},
+			},
+			{
+				Name:   aws.String("status"),
+				Values: []string{string(ec2types.NetworkInterfaceStatusAvailable)},
+			},
+		},
+	}
+
+	var resources []Resource
+	paginator := ec2.NewDescribeNetworkInterfacesPaginator(client, input, func(opt *ec2.DescribeNetworkInterfacesPaginatorOptions) {
+		opt.StopOnDuplicateToken = true
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, networkInterface := range page.NetworkInterfaces {
+			name := tagValue(networkInterface.TagSet, "Name")
+			if _, ok := machineNames[name]; ok || name == "" {
+				continue
+			}
+			if excluded(networkInterface.TagSet, nil, opts) {
+				continue
+			}
+			resources = append(resources, Resource{Kind: KindNetworkInterface, ID: aws.ToString(networkInterface.NetworkInterfaceId), Name: name})
+		}
+	}
+	return resources, nil
+}
+
+func deleteResource(ctx context.Context, client interfaces.Ec2Client, resource Resource) error {
+	var err error
+	switch resource.Kind {
+	case KindInstance:
+		_, err = client.TerminateInstances(ctx, &ec2.TerminateInstancesInput{InstanceIds: []string{resource.ID}})
+	case KindVolume:
+		_, err = client.DeleteVolume(ctx, &ec2.DeleteVolumeInput{VolumeId: aws.String(resource.ID)})
+	case KindNetworkInterface:
+		_, err = client.DeleteNetworkInterface(ctx, &ec2.DeleteNetworkInterfaceInput{NetworkInterfaceId: aws.String(resource.ID)})
+	default:
+		err = fmt.Errorf("unknown resource kind %q", resource.Kind)
+	}
+	return err
+}
+
+// excluded returns true if the resource carries an exclusion tag or is younger than the minimum age.
+func excluded(tags []ec2types.Tag, creationTime *time.Time, opts Options) bool {
+	for _, tag := range tags {
+		value, ok := opts.ExcludeTags[aws.ToString(tag.Key)]
+		if ok && (value == "" || value == aws.ToString(tag.Value)) {
+			return true
+		}
+	}
+	return creationTime != nil && opts.Now().Sub(*creationTime) < opts.MinAge
+}
+
+func tagValue(tags []ec2types.Tag, key string) string {
+	for _, tag := range tags {
+		if aws.ToString(tag.Key) == key {
+			return aws.ToString(tag.Value)
+		}
+	}
+	return ""
+}
+
+// instanceIDFromProviderID extracts the instance ID from a providerID of the form aws:///<region>/<instanceID>.
+func instanceIDFromProviderID(providerID string) string {
+	return providerID[strings.LastIndex(providerID, "/")+1:]
+}
@@ -0,0 +1,148 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package orphan
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	v1alpha1 "github.com/gardener/machine-controller-manager/pkg/apis/machine/v1alpha1"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/gardener/machine-controller-manager-provider-aws/pkg/mockclient"
+)
+
+const (
+	clusterTag = "kubernetes.io/cluster/shoot--test"
+	roleTag    = "kubernetes.io/role/node"
+)
+
+var (
+	now = time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	old = now.Add(-24 * time.Hour)
+)
+
+func tags(name string, extra ...string) []ec2types.Tag {
+	result := []ec2types.Tag{{Key: aws.String("Name"), Value: aws.String(name)}, {Key: aws.String(clusterTag), Value: aws.String("1")}}
+	for _, key := range extra {
+		result = append(result, ec2types.Tag{Key: aws.String(key), Value: aws.String("true")})
+	}
+	return result
+}
+
+func instance(id, name string, launchTime time.Time, extraTags ...string) ec2types.Instance {
+	return ec2types.Instance{
+		InstanceId: aws.String(id),
+		LaunchTime: aws.Time(launchTime),
+		State:      &ec2types.InstanceState{Name: ec2types.InstanceStateNameRunning},
+		Tags:       tags(name, append(extraTags, roleTag)...),
+	}
+}
+
+func newClientProvider() *mockclient.MockClientProvider {
+	return &mockclient.MockClientProvider{
+		FakeInstances: []ec2types.Instance{
+			instance("i-by-name", "machine-0", old),
+			instance("i-by-provider-id", "renamed", old),
+			instance("i-orphan", "machine-gone", old),
+			instance("i-young", "machine-young", now.Add(-time.Minute)),
+			instance("i-excluded", "machine-excluded", old, "keep"),
+			{InstanceId: aws.String("i-other-cluster"), State: &ec2types.InstanceState{Name: ec2types.InstanceStateNameRunning}, Tags: []ec2types.Tag{{Key: aws.String("Name"), Value: aws.String("other")}}},
+			{InstanceId: aws.String("i-no-role"), LaunchTime: aws.Time(old), State: &ec2types.InstanceState{Name: ec2types.InstanceStateNameRunning}, Tags: tags("machine-no-role")},
+			instance("i-no-name", "", old),
+		},
+		FakeVolumes: []ec2types.Volume{
+			{VolumeId: aws.String("vol-orphan"), State: ec2types.VolumeStateAvailable, CreateTime: aws.Time(old), Tags: tags("machine-gone", roleTag)},
+			{VolumeId: aws.String("vol-in-use"), State: ec2types.VolumeStateInUse, CreateTime: aws.Time(old), Tags: tags("machine-0", roleTag)},
+			{VolumeId: aws.String("vol-pv"), State: ec2types.VolumeStateAvailable, CreateTime: aws.Time(old), Tags: []ec2types.Tag{{Key: aws.String(clusterTag), Value: aws.String("owned")}}},
+			// Volumes of persistent volumes carry the cluster tag and may be named, but lack the role tag
+			{VolumeId: aws.String("vol-named-pv"), State: ec2types.VolumeStateAvailable, CreateTime: aws.Time(old), Tags: tags("shoot--test-pvc-0123")},
+		},
+		FakeNetworkInterfaces: []ec2types.NetworkInterface{
+			{NetworkInterfaceId: aws.String("eni-orphan"), Status: ec2types.NetworkInterfaceStatusAvailable, TagSet: tags("machine-gone", roleTag)},
+			{NetworkInterfaceId: aws.String("eni-machine"), Status: ec2types.NetworkInterfaceStatusAvailable, TagSet: tags("machine-0", roleTag)},
+			{NetworkInterfaceId: aws.String("eni-foreign"), Status: ec2types.NetworkInterfaceStatusAvailable, TagSet: tags("load-balancer")},
+		},
+	}
+}
+
+func machines() []v1alpha1.Machine {
+	return []v1alpha1.Machine{
+		{ObjectMeta: metav1.ObjectMeta{Name: "machine-0"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "machine-1"}, Spec: v1alpha1.MachineSpec{ProviderID: "aws:///eu-west-1/i-by-provider-id"}},
+	}
+}
+
+func TestCollectDryRun(t *testing.T) {
+	g := NewWithT(t)
+	clientProvider := newClientProvider()
+	client := clientProvider.NewEC2Client(&aws.Config{})
+
+	report, err := Collect(context.Background(), client, machines(), Options{
+		ClusterTag:  clusterTag,
+		RoleTag:     roleTag,
+		MinAge:      time.Hour,
+		ExcludeTags: map[string]string{"keep": ""},
+		Now:         func() time.Time { return now },
+	})
+
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(report.DryRun).To(BeTrue())
+	g.Expect(report.Resources).To(ConsistOf(
+		Resource{Kind: KindInstance, ID: "i-orphan", Name: "machine-gone", CreationTime: aws.Time(old)},
+		Resource{Kind: KindVolume, ID: "vol-orphan", Name: "machine-gone", CreationTime: aws.Time(old)},
+		Resource{Kind: KindNetworkInterface, ID: "eni-orphan", Name: "machine-gone"},
+	))
+	g.Expect(clientProvider.FakeVolumes).To(HaveLen(4))
+	g.Expect(clientProvider.FakeNetworkInterfaces).To(HaveLen(3))
+}
+
+func TestCollectDeletes(t *testing.T) {
+	g := NewWithT(t)
+	clientProvider := newClientProvider()
+	client := clientProvider.NewEC2Client(&aws.Config{})
+
+	report, err := Collect(context.Background(), client, machines(), Options{
+		ClusterTag: clusterTag,
+		RoleTag:    roleTag,
+		ExcludeTags: map[string]string{
+			"keep": "false",
+		},
+		Delete: true,
+		Now:    func() time.Time { return now },
+	})
+
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(report.Failed()).To(Equal(0))
+	var deleted []string
+	for _, resource := range report.Resources {
+		g.Expect(resource.Deleted).To(BeTrue())
+		deleted = append(deleted, resource.ID)
+	}
+	// The exclusion tag value does not match and the minimum age is not set
+	g.Expect(deleted).To(ConsistOf("i-orphan", "i-young", "i-excluded", "vol-orphan", "eni-orphan"))
+	g.Expect(clientProvider.FakeVolumes).To(HaveLen(3))
+	g.Expect(clientProvider.FakeNetworkInterfaces).To(HaveLen(2))
+	for _, instance := range clientProvider.FakeInstances {
+		if aws.ToString(instance.InstanceId) == "i-orphan" {
+			g.Expect(instance.State.Name).To(Equal(ec2types.InstanceStateNameTerminated))
+		}
+	}
+}
+
+func TestCollectRequiresClusterAndRoleTag(t *testing.T) {
+	g := NewWithT(t)
+	client := newClientProvider().NewEC2Client(&aws.Config{})
+
+	_, err := Collect(context.Background(), client, nil, Options{})
+	g.Expect(err).To(MatchError("cluster tag is required"))
+
+	_, err = Collect(context.Background(), client, nil, Options{ClusterTag: clusterTag})
+	g.Expect(err).To(MatchError("role tag is required"))
+}
@@ -0,0 +1,113 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package replay records EC2 API interactions of a real session into sanitized fixture files and replays them
+// against the aws-sdk-go-v2 middleware stack, so that Driver unit tests can be driven by real API responses.
+package replay
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+// Interaction is a single recorded EC2 request/response pair. Fixture files contain one JSON encoded Interaction
+// per line in the order in which the requests were sent.
+type Interaction struct {
+	// Operation is the EC2 API operation name, e.g. "DescribeInstances".
+	Operation string `json:"operation"`
+	// Input is the sanitized operation input as sent by the client.
+	Input json.RawMessage `json:"input,omitempty"`
+	// Output is the sanitized operation output. It is empty if the request failed.
+	Output json.RawMessage `json:"output,omitempty"`
+	// Error is set if the request failed.
+	Error *APIError `json:"error,omitempty"`
+}
+
+// APIError is the recorded form of an error returned by the EC2 API.
+type APIError struct {
+	// Code is the API error code, e.g. "InvalidInstanceID.NotFound". It is empty for errors which did not originate
+	// from the API (e.g. transport errors).
+	Code string `json:"code,omitempty"`
+	// Message is the error message.
+	Message string `json:"message,omitempty"`
+}
+
+// outputFactories returns an empty output object per supported operation. Replayed outputs are decoded into these
+// objects, since the ec2.Client expects the concrete output type of the invoked operation as result.
+// Operations added to interfaces.Ec2Client or interfaces.StsClient must be added here as well to be replayable.
+var outputFactories = map[string]func() any{
+	"AllocateAddress":                      func() any { return &ec2.AllocateAddressOutput{} },
+	"AllocateHosts":                        func() any { return &ec2.AllocateHostsOutput{} },
+	"AssignIpv6Addresses":                  func() any { return &ec2.AssignIpv6AddressesOutput{} },
+	"AssignPrivateIpAddresses":             func() any { return &ec2.AssignPrivateIpAddressesOutput{} },
+	"AssociateAddress":                     func() any { return &ec2.AssociateAddressOutput{} },
+	"CreateTags":                           func() any { return &ec2.CreateTagsOutput{} },
+	"DeleteNetworkInterface":               func() any { return &ec2.DeleteNetworkInterfaceOutput{} },
+	"DeleteTags":                           func() any { return &ec2.DeleteTagsOutput{} },
+	"DeleteVolume":                         func() any { return &ec2.DeleteVolumeOutput{} },
+	"DescribeAddresses":                    func() any { return &ec2.DescribeAddressesOutput{} },
+	"DescribeCapacityReservations":         func() any { return &ec2.DescribeCapacityReservationsOutput{} },
+	"DescribeHosts":                        func() any { return &ec2.DescribeHostsOutput{} },
+	"DescribeImages":                       func() any { return &ec2.DescribeImagesOutput{} },
+	"DescribeInstanceCreditSpecifications": func() any { return &ec2.DescribeInstanceCreditSpecificationsOutput{} },
+	"DescribeInstanceTypes":                func() any { return &ec2.DescribeInstanceTypesOutput{} },
+	"DescribeInstances":                    func() any { return &ec2.DescribeInstancesOutput{} },
+	"DescribeNetworkInterfaces":            func() any { return &ec2.DescribeNetworkInterfacesOutput{} },
+	"DescribePlacementGroups":              func() any { return &ec2.DescribePlacementGroupsOutput{} },
+	"DescribeSecurityGroups":               func() any { return &ec2.DescribeSecurityGroupsOutput{} },
+	"DescribeSubnets":                      func() any { return &ec2.DescribeSubnetsOutput{} },
+	"DescribeVolumes":                      func() any { return &ec2.DescribeVolumesOutput{} },
+	"DisassociateAddress":                  func() any { return &ec2.DisassociateAddressOutput{} },
+	"GetCallerIdentity":                    func() any { return &sts.GetCallerIdentityOutput{} },
+	"ModifyInstanceAttribute":              func() any { return &ec2.ModifyInstanceAttributeOutput{} },
+	"ModifyInstanceCreditSpecification":    func() any { return &ec2.ModifyInstanceCreditSpecificationOutput{} },
+	"ModifyInstanceMaintenanceOptions":     func() any { return &ec2.ModifyInstanceMaintenanceOptionsOutput{} },
+	"ModifyNetworkInterfaceAttribute":      func() any { return &ec2.ModifyNetworkInterfaceAttributeOutput{} },
+	"ReleaseAddress":                       func() any { return &ec2.ReleaseAddressOutput{} },
+	"ReleaseHosts":                         func() any { return &ec2.ReleaseHostsOutput{} },
+	"RunInstances":                         func() any { return &ec2.RunInstancesOutput{} },
+	"TerminateInstances":                   func() any { return &ec2.TerminateInstancesOutput{} },
+}
+
+// ReadFixture reads all interactions from a fixture stream.
+func ReadFixture(r io.Reader) ([]Interaction, error) {
+	var interactions []Interaction
+
+	scanner := bufio.NewScanner(r)
+	// EC2 responses (e.g. DescribeInstances pages) easily exceed the default token size of the scanner
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var interaction Interaction
+		if err := json.Unmarshal(scanner.Bytes(), &interaction); err != nil {
+			return nil, fmt.Errorf("invalid interaction in line %d: %w", line, err)
+		}
+		interactions = append(interactions, interaction)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return interactions, nil
+}
+
+// LoadFixture reads all interactions from the fixture file at the given path.
+func LoadFixture(path string) ([]Interaction, error) {
+	// #nosec: G304 -- fixture paths are provided by tests and operators
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadFixture(f)
+}
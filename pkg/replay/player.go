@@ -0,0 +1,183 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/smithy-go"
+	"github.com/aws/smithy-go/middleware"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/gardener/machine-controller-manager-provider-aws/pkg/aws/interfaces"
+)
+
+const playerMiddlewareID = "ReplayEC2Interaction"
+
+// idempotencyTokenFields are the input fields which the SDK fills with random tokens, so they differ from the recording.
+var idempotencyTokenFields = []string{"ClientToken"}
+
+// Player is a middleware for the aws-sdk-go-v2 stack which answers EC2 requests from recorded interactions instead of
+// sending them. Interactions are replayed in the recorded order per operation, and a request fails if its input does
+// not match the recorded input.
+type Player struct {
+	mu           sync.Mutex
+	interactions map[string][]Interaction
+	consumed     map[string]int
+}
+
+// NewPlayer returns a Player replaying the given interactions.
+func NewPlayer(interactions []Interaction) *Player {
+	p := &Player{
+		interactions: make(map[string][]Interaction),
+		consumed:     make(map[string]int),
+	}
+	for _, interaction := range interactions {
+		p.interactions[interaction.Operation] = append(p.interactions[interaction.Operation], interaction)
+	}
+	return p
+}
+
+// NewPlayerFromFixture returns a Player replaying the interactions of the fixture file at the given path.
+func NewPlayerFromFixture(path string) (*Player, error) {
+	interactions, err := LoadFixture(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewPlayer(interactions), nil
+}
+
+// ID implements middleware.InitializeMiddleware
+func (p *Player) ID() string {
+	return playerMiddlewareID
+}
+
+// HandleInitialize implements middleware.InitializeMiddleware. The remaining stack is never invoked, so no request
+// leaves the process.
+func (p *Player) HandleInitialize(ctx context.Context, in middleware.InitializeInput, _ middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+	operation := middleware.GetOperationName(ctx)
+
+	interaction, err := p.next(operation)
+	if err != nil {
+		return middleware.InitializeOutput{}, middleware.Metadata{}, err
+	}
+	if err := matchInput(operation, interaction.Input, in.Parameters); err != nil {
+		return middleware.InitializeOutput{}, middleware.Metadata{}, err
+	}
+	if interaction.Error != nil {
+		if interaction.Error.Code == "" {
+			return middleware.InitializeOutput{}, middleware.Metadata{}, errors.New(interaction.Error.Message)
+		}
+		return middleware.InitializeOutput{}, middleware.Metadata{}, &smithy.GenericAPIError{
+			Code:    interaction.Error.Code,
+			Message: interaction.Error.Message,
+		}
+	}
+
+	newOutput, ok := outputFactories[operation]
+	if !ok {
+		return middleware.InitializeOutput{}, middleware.Metadata{}, fmt.Errorf("replay of operation %q is not supported", operation)
+	}
+	result := newOutput()
+	if len(interaction.Output) > 0 {
+		if err := json.Unmarshal(interaction.Output, result); err != nil {
+			return middleware.InitializeOutput{}, middleware.Metadata{}, fmt.Errorf("invalid recorded output for operation %q: %w", operation, err)
+		}
+	}
+	return middleware.InitializeOutput{Result: result}, middleware.Metadata{}, nil
+}
+
+// AddToStack registers the Player on the given stack. It can be used as entry of aws.Config.APIOptions.
+func (p *Player) AddToStack(stack *middleware.Stack) error {
+	return stack.Initialize.Add(p, middleware.After)
+}
+
+// Remaining returns the number of recorded interactions which have not been replayed yet.
+func (p *Player) Remaining() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	remaining := 0
+	for operation, interactions := range p.interactions {
+		remaining += len(interactions) - p.consumed[operation]
+	}
+	return remaining
+}
+
+func (p *Player) next(operation string) (Interaction, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	idx := p.consumed[operation]
+	if idx >= len(p.interactions[operation]) {
+		return Interaction{}, fmt.Errorf("no recorded interaction left for operation %q (%d replayed)", operation, idx)
+	}
+	p.consumed[operation]++
+	return p.interactions[operation][idx], nil
+}
+
+// matchInput returns an error if the input of a request differs from the recorded input. The input is sanitized like
+// the recorded one before both are compared, except for their idempotency tokens. Interactions recorded without input
+// match any input.
+func matchInput(operation string, recorded json.RawMessage, input any) error {
+	if len(recorded) == 0 {
+		return nil
+	}
+	actual, err := sanitize(input)
+	if err != nil {
+		return fmt.Errorf("invalid input for operation %q: %w", operation, err)
+	}
+
+	var recordedValue, actualValue any
+	if err := json.Unmarshal(recorded, &recordedValue); err != nil {
+		return fmt.Errorf("invalid recorded input for operation %q: %w", operation, err)
+	}
+	if err := json.Unmarshal(actual, &actualValue); err != nil {
+		return fmt.Errorf("invalid input for operation %q: %w", operation, err)
+	}
+	for _, value := range []any{recordedValue, actualValue} {
+		if fields, ok := value.(map[string]any); ok {
+			for _, field := range idempotencyTokenFields {
+				delete(fields, field)
+			}
+		}
+	}
+	if !reflect.DeepEqual(recordedValue, actualValue) {
+		return fmt.Errorf("recorded input of operation %q does not match: recorded %s, got %s", operation, recorded, actual)
+	}
+	return nil
+}
+
+// ClientProvider is an implementation of cpi.ClientProviderInterface creating real EC2 and STS clients whose requests
+// are answered by a Player.
+type ClientProvider struct {
+	Player *Player
+}
+
+// NewConfig returns a config replaying all requests with the Player.
+func (cp *ClientProvider) NewConfig(_ context.Context, _ *corev1.Secret, region string) (*aws.Config, error) {
+	return &aws.Config{
+		Region:     region,
+		APIOptions: []func(*middleware.Stack) error{cp.Player.AddToStack},
+	}, nil
+}
+
+// NewEC2Client returns an EC2 client for the given config.
+func (cp *ClientProvider) NewEC2Client(config *aws.Config) interfaces.Ec2Client {
+	return ec2.NewFromConfig(*config)
+}
+
+// NewSTSClient returns an STS client for the given config.
+func (cp *ClientProvider) NewSTSClient(config *aws.Config) interfaces.StsClient {
+	return sts.NewFromConfig(*config)
+}
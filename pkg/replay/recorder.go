@@ -0,0 +1,182 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/smithy-go"
+	"github.com/aws/smithy-go/middleware"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/gardener/machine-controller-manager-provider-aws/pkg/aws/interfaces"
+	"github.com/gardener/machine-controller-manager-provider-aws/pkg/cpi"
+)
+
+const (
+	recorderMiddlewareID = "RecordEC2Interaction"
+
+	// redactedValue replaces the values of sensitive fields.
+	redactedValue = "REDACTED"
+	// redactedAccountID replaces AWS account IDs, e.g. in OwnerId fields and ARNs.
+	redactedAccountID = "000000000000"
+)
+
+var (
+	// accountIDRegexp matches 12 digit AWS account IDs.
+	accountIDRegexp = regexp.MustCompile(`\b\d{12}\b`)
+
+	// sensitiveFields contains the lower-cased names of fields whose values are always redacted.
+	// UserData is included since it carries the bootstrap token of the node.
+	sensitiveFields = map[string]struct{}{
+		"accesskeyid":     {},
+		"secretaccesskey": {},
+		"sessiontoken":    {},
+		"keymaterial":     {},
+		"password":        {},
+		"userdata":        {},
+	}
+)
+
+// Recorder is a middleware for the aws-sdk-go-v2 stack which writes sanitized EC2 interactions to a fixture stream.
+type Recorder struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewRecorder returns a Recorder writing to the given stream.
+func NewRecorder(w io.Writer) *Recorder {
+	return &Recorder{w: w}
+}
+
+// ID implements middleware.InitializeMiddleware
+func (r *Recorder) ID() string {
+	return recorderMiddlewareID
+}
+
+// HandleInitialize implements middleware.InitializeMiddleware. The interaction is recorded after the remaining stack
+// has been invoked, so retries of the SDK are not part of the fixture.
+func (r *Recorder) HandleInitialize(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+	out, metadata, err := next.HandleInitialize(ctx, in)
+
+	if recordErr := r.record(middleware.GetOperationName(ctx), in.Parameters, out.Result, err); recordErr != nil {
+		klog.Errorf("Failed to record EC2 interaction for operation %q: %v", middleware.GetOperationName(ctx), recordErr)
+	}
+	return out, metadata, err
+}
+
+// AddToStack registers the Recorder on the given stack. It can be used as entry of aws.Config.APIOptions.
+func (r *Recorder) AddToStack(stack *middleware.Stack) error {
+	return stack.Initialize.Add(r, middleware.After)
+}
+
+func (r *Recorder) record(operation string, input, output any, err error) error {
+	interaction := Interaction{
+		Operation: operation,
+	}
+
+	var sanitizeErr error
+	if interaction.Input, sanitizeErr = sanitize(input); sanitizeErr != nil {
+		return sanitizeErr
+	}
+	if err != nil {
+		interaction.Error = &APIError{Message: redactString(err.Error())}
+		var apiErr smithy.APIError
+		if errors.As(err, &apiErr) {
+			interaction.Error.Code = apiErr.ErrorCode()
+			interaction.Error.Message = redactString(apiErr.ErrorMessage())
+		}
+	} else if interaction.Output, sanitizeErr = sanitize(output); sanitizeErr != nil {
+		return sanitizeErr
+	}
+
+	line, marshalErr := json.Marshal(interaction)
+	if marshalErr != nil {
+		return marshalErr
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, writeErr := r.w.Write(append(line, '\n'))
+	return writeErr
+}
+
+// sanitize converts the given value into JSON with credentials, user data and account IDs redacted.
+func sanitize(v any) (json.RawMessage, error) {
+	if v == nil {
+		return nil, nil
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var generic any
+	if err := json.Unmarshal(raw, &generic); err != nil {
+		return nil, err
+	}
+	return json.Marshal(redact(generic))
+}
+
+func redact(v any) any {
+	switch value := v.(type) {
+	case map[string]any:
+		for key, field := range value {
+			if _, ok := sensitiveFields[strings.ToLower(key)]; ok && field != nil {
+				value[key] = redactedValue
+				continue
+			}
+			value[key] = redact(field)
+		}
+		return value
+	case []any:
+		for i := range value {
+			value[i] = redact(value[i])
+		}
+		return value
+	case string:
+		return redactString(value)
+	default:
+		return v
+	}
+}
+
+func redactString(s string) string {
+	return accountIDRegexp.ReplaceAllString(s, redactedAccountID)
+}
+
+// RecordingClientProvider wraps a cpi.ClientProviderInterface and records all EC2 and STS interactions of the clients it
+// creates.
+type RecordingClientProvider struct {
+	cpi.ClientProviderInterface
+	Recorder *Recorder
+}
+
+// NewConfig returns the config of the wrapped provider with the recorder added to its API options.
+func (rp *RecordingClientProvider) NewConfig(ctx context.Context, secret *corev1.Secret, region string) (*aws.Config, error) {
+	cfg, err := rp.ClientProviderInterface.NewConfig(ctx, secret, region)
+	if err != nil {
+		return nil, err
+	}
+	cfg.APIOptions = append(cfg.APIOptions, rp.Recorder.AddToStack)
+	return cfg, nil
+}
+
+// NewEC2Client returns the EC2 client of the wrapped provider.
+func (rp *RecordingClientProvider) NewEC2Client(config *aws.Config) interfaces.Ec2Client {
+	return rp.ClientProviderInterface.NewEC2Client(config)
+}
+
+// NewSTSClient returns the STS client of the wrapped provider.
+func (rp *RecordingClientProvider) NewSTSClient(config *aws.Config) interfaces.StsClient {
+	return rp.ClientProviderInterface.NewSTSClient(config)
+}
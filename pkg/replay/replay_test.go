@@ -0,0 +1,169 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package replay
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/smithy-go"
+	"github.com/aws/smithy-go/middleware"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// stubMiddleware answers every request at the end of the initialize step, like a real API would.
+type stubMiddleware struct {
+	result any
+	err    error
+}
+
+func (s *stubMiddleware) ID() string { return "stub" }
+
+func (s *stubMiddleware) HandleInitialize(_ context.Context, _ middleware.InitializeInput, _ middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+	return middleware.InitializeOutput{Result: s.result}, middleware.Metadata{}, s.err
+}
+
+func newStubbedClient(recorder *Recorder, stub *stubMiddleware) *ec2.Client {
+	return ec2.NewFromConfig(aws.Config{
+		Region: "eu-west-1",
+		APIOptions: []func(*middleware.Stack) error{
+			recorder.AddToStack,
+			func(stack *middleware.Stack) error { return stack.Initialize.Add(stub, middleware.After) },
+		},
+	})
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+	buf := &bytes.Buffer{}
+	recorder := NewRecorder(buf)
+
+	client := newStubbedClient(recorder, &stubMiddleware{result: &ec2.DescribeInstancesOutput{
+		Reservations: []ec2types.Reservation{{
+			OwnerId: aws.String("123456789012"),
+			Instances: []ec2types.Instance{{
+				InstanceId:         aws.String("i-1"),
+				IamInstanceProfile: &ec2types.IamInstanceProfile{Arn: aws.String("arn:aws:iam::123456789012:instance-profile/nodes")},
+			}},
+		}},
+	}})
+	_, err := client.DescribeInstances(ctx, &ec2.DescribeInstancesInput{InstanceIds: []string{"i-1"}})
+	g.Expect(err).ToNot(HaveOccurred())
+
+	client = newStubbedClient(recorder, &stubMiddleware{err: &smithy.GenericAPIError{Code: "InvalidInstanceID.NotFound", Message: "not found"}})
+	_, err = client.TerminateInstances(ctx, &ec2.TerminateInstancesInput{InstanceIds: []string{"i-2"}})
+	g.Expect(err).To(HaveOccurred())
+
+	g.Expect(buf.String()).ToNot(ContainSubstring("123456789012"))
+
+	interactions, err := ReadFixture(buf)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(interactions).To(HaveLen(2))
+	g.Expect(interactions[0].Operation).To(Equal("DescribeInstances"))
+	g.Expect(interactions[1].Operation).To(Equal("TerminateInstances"))
+	g.Expect(interactions[1].Error).To(Equal(&APIError{Code: "InvalidInstanceID.NotFound", Message: "not found"}))
+
+	provider := &ClientProvider{Player: NewPlayer(interactions)}
+	cfg, err := provider.NewConfig(ctx, &corev1.Secret{}, "eu-west-1")
+	g.Expect(err).ToNot(HaveOccurred())
+	replayClient := provider.NewEC2Client(cfg)
+
+	output, err := replayClient.DescribeInstances(ctx, &ec2.DescribeInstancesInput{InstanceIds: []string{"i-1"}})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(output.Reservations).To(HaveLen(1))
+	g.Expect(*output.Reservations[0].OwnerId).To(Equal(redactedAccountID))
+	g.Expect(*output.Reservations[0].Instances[0].InstanceId).To(Equal("i-1"))
+	g.Expect(*output.Reservations[0].Instances[0].IamInstanceProfile.Arn).To(Equal("arn:aws:iam::000000000000:instance-profile/nodes"))
+
+	_, err = replayClient.TerminateInstances(ctx, &ec2.TerminateInstancesInput{InstanceIds: []string{"i-2"}})
+	var apiErr smithy.APIError
+	g.Expect(errors.As(err, &apiErr)).To(BeTrue())
+	g.Expect(apiErr.ErrorCode()).To(Equal("InvalidInstanceID.NotFound"))
+
+	g.Expect(provider.Player.Remaining()).To(Equal(0))
+	_, err = replayClient.DescribeInstances(ctx, &ec2.DescribeInstancesInput{})
+	g.Expect(err).To(MatchError(ContainSubstring(`no recorded interaction left for operation "DescribeInstances"`)))
+}
+
+func TestSanitize(t *testing.T) {
+	g := NewWithT(t)
+
+	sanitized, err := sanitize(&ec2.RunInstancesInput{
+		ImageId:  aws.String("ami-123"),
+		UserData: aws.String("c2VjcmV0LWJvb3RzdHJhcC10b2tlbg=="),
+		IamInstanceProfile: &ec2types.IamInstanceProfileSpecification{
+			Arn: aws.String("arn:aws:iam::210987654321:instance-profile/nodes"),
+		},
+	})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(string(sanitized)).To(ContainSubstring(`"UserData":"REDACTED"`))
+	g.Expect(string(sanitized)).To(ContainSubstring(`"ImageId":"ami-123"`))
+	g.Expect(string(sanitized)).To(ContainSubstring("arn:aws:iam::000000000000:instance-profile/nodes"))
+	g.Expect(string(sanitized)).ToNot(ContainSubstring("c2VjcmV0"))
+
+	credentials, err := sanitize(map[string]any{"Credentials": map[string]any{"AccessKeyId": "AKIAXXXXXXXX", "SecretAccessKey": "secret", "SessionToken": nil}})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(string(credentials)).To(Equal(`{"Credentials":{"AccessKeyId":"REDACTED","SecretAccessKey":"REDACTED","SessionToken":null}}`))
+}
+
+func TestReplayCallerIdentity(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	provider := &ClientProvider{Player: NewPlayer([]Interaction{{Operation: "GetCallerIdentity", Output: []byte(`{"Account":"000000000000"}`)}})}
+	cfg, err := provider.NewConfig(ctx, &corev1.Secret{}, "eu-west-1")
+	g.Expect(err).ToNot(HaveOccurred())
+
+	output, err := provider.NewSTSClient(cfg).GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(output.Account).To(Equal(aws.String("000000000000")))
+}
+
+func TestReplayRejectsDifferentInput(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+	buf := &bytes.Buffer{}
+	recorder := NewRecorder(buf)
+
+	input := func(imageID string) *ec2.RunInstancesInput {
+		return &ec2.RunInstancesInput{
+			ImageId:  aws.String(imageID),
+			MinCount: aws.Int32(1),
+			MaxCount: aws.Int32(1),
+			UserData: aws.String("c2VjcmV0LWJvb3RzdHJhcC10b2tlbg=="),
+		}
+	}
+	client := newStubbedClient(recorder, &stubMiddleware{result: &ec2.RunInstancesOutput{Instances: []ec2types.Instance{{InstanceId: aws.String("i-1")}}}})
+	for range 2 {
+		_, err := client.RunInstances(ctx, input("ami-123"))
+		g.Expect(err).ToNot(HaveOccurred())
+	}
+
+	interactions, err := ReadFixture(buf)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(interactions).To(HaveLen(2))
+	g.Expect(string(interactions[0].Input)).To(ContainSubstring(`"ClientToken":"`))
+	provider := &ClientProvider{Player: NewPlayer(interactions)}
+	cfg, err := provider.NewConfig(ctx, &corev1.Secret{}, "eu-west-1")
+	g.Expect(err).ToNot(HaveOccurred())
+	replayClient := provider.NewEC2Client(cfg)
+
+	// The redacted user data and the idempotency token generated by the SDK do not count as difference
+	output, err := replayClient.RunInstances(ctx, input("ami-123"))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(*output.Instances[0].InstanceId).To(Equal("i-1"))
+
+	_, err = replayClient.RunInstances(ctx, input("ami-456"))
+	g.Expect(err).To(MatchError(ContainSubstring(`recorded input of operation "RunInstances" does not match`)))
+	g.Expect(err).To(MatchError(ContainSubstring(`"ImageId":"ami-456"`)))
+}